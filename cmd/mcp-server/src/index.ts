@@ -31,6 +31,29 @@ interface ValidationArgs {
   language?: string;
 }
 
+interface FileInput {
+  path: string;
+  content: string;
+  language?: string;
+}
+
+interface ValidateFilesArgs {
+  project_id: string;
+  files: FileInput[];
+}
+
+interface ValidateDiffArgs {
+  project_id: string;
+  diff: string;
+}
+
+interface FixArgs {
+  project_id: string;
+  code: string;
+  filename?: string;
+  rule_ids?: string[];
+}
+
 interface RuleArgs {
   project_id: string;
   language?: string;
@@ -61,6 +84,35 @@ const isValidValidationArgs = (args: any): args is ValidationArgs =>
   typeof args.code === 'string' &&
   (args.language === undefined || typeof args.language === 'string');
 
+const isValidValidateFilesArgs = (args: any): args is ValidateFilesArgs =>
+  typeof args === 'object' &&
+  args !== null &&
+  typeof args.project_id === 'string' &&
+  Array.isArray(args.files) &&
+  args.files.every(
+    (f: any) =>
+      typeof f === 'object' &&
+      f !== null &&
+      typeof f.path === 'string' &&
+      typeof f.content === 'string' &&
+      (f.language === undefined || typeof f.language === 'string')
+  );
+
+const isValidValidateDiffArgs = (args: any): args is ValidateDiffArgs =>
+  typeof args === 'object' &&
+  args !== null &&
+  typeof args.project_id === 'string' &&
+  typeof args.diff === 'string';
+
+const isValidFixArgs = (args: any): args is FixArgs =>
+  typeof args === 'object' &&
+  args !== null &&
+  typeof args.project_id === 'string' &&
+  typeof args.code === 'string' &&
+  (args.filename === undefined || typeof args.filename === 'string') &&
+  (args.rule_ids === undefined ||
+    (Array.isArray(args.rule_ids) && args.rule_ids.every((id: unknown) => typeof id === 'string')));
+
 const isValidProjectInfoArgs = (args: any): args is ProjectInfoArgs =>
   typeof args === 'object' &&
   args !== null &&
@@ -311,6 +363,78 @@ class RuleMCPServer {
             required: ['project_id', 'code'],
           },
         },
+        {
+          name: 'validateFiles',
+          description: 'Validate several files at once; rules are applied per file by language and file_glob',
+          inputSchema: {
+            type: 'object',
+            properties: {
+              project_id: {
+                type: 'string',
+                description: 'The project ID to validate against',
+              },
+              files: {
+                type: 'array',
+                description: 'Files to validate',
+                items: {
+                  type: 'object',
+                  properties: {
+                    path: { type: 'string', description: 'File path relative to the project root' },
+                    content: { type: 'string', description: 'File content' },
+                    language: { type: 'string', description: 'Programming language (optional, inferred from extension)' },
+                  },
+                  required: ['path', 'content'],
+                },
+              },
+            },
+            required: ['project_id', 'files'],
+          },
+        },
+        {
+          name: 'validateDiff',
+          description: 'Validate a unified diff (git diff output); only issues on added lines are reported, with new-file line numbers',
+          inputSchema: {
+            type: 'object',
+            properties: {
+              project_id: {
+                type: 'string',
+                description: 'The project ID to validate against',
+              },
+              diff: {
+                type: 'string',
+                description: 'Unified diff as produced by git diff',
+              },
+            },
+            required: ['project_id', 'diff'],
+          },
+        },
+        {
+          name: 'fixCode',
+          description: 'Apply automatic fixes from project rules and return the patched code with a unified diff',
+          inputSchema: {
+            type: 'object',
+            properties: {
+              project_id: {
+                type: 'string',
+                description: 'The project ID whose rules are applied',
+              },
+              code: {
+                type: 'string',
+                description: 'The code to fix',
+              },
+              filename: {
+                type: 'string',
+                description: 'File path used for file_glob scoped rules (optional)',
+              },
+              rule_ids: {
+                type: 'array',
+                items: { type: 'string' },
+                description: 'Only apply fixes from these rules (optional)',
+              },
+            },
+            required: ['project_id', 'code'],
+          },
+        },
         {
           name: 'getProjectInfo',
           description: 'Get information about a specific project',
@@ -390,6 +514,33 @@ class RuleMCPServer {
             }
             return await this.handleValidateCode(request.params.arguments);
 
+          case 'validateFiles':
+            if (!isValidValidateFilesArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                'Invalid validateFiles arguments'
+              );
+            }
+            return await this.handleValidateFiles(request.params.arguments);
+
+          case 'validateDiff':
+            if (!isValidValidateDiffArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                'Invalid validateDiff arguments'
+              );
+            }
+            return await this.handleValidateDiff(request.params.arguments);
+
+          case 'fixCode':
+            if (!isValidFixArgs(request.params.arguments)) {
+              throw new McpError(
+                ErrorCode.InvalidParams,
+                'Invalid fixCode arguments'
+              );
+            }
+            return await this.handleFixCode(request.params.arguments);
+
           case 'getProjectInfo':
             if (!isValidProjectInfoArgs(request.params.arguments)) {
               throw new McpError(
@@ -457,6 +608,7 @@ class RuleMCPServer {
 
   private async callRuleServer(method: string, params: any): Promise<any> {
     const response = await this.axiosInstance.post<RuleServerResponse>('/mcp/request', {
+      jsonrpc: '2.0',
       id: `mcp-${Date.now()}`,
       method,
       params,
@@ -517,6 +669,78 @@ class RuleMCPServer {
     }
   }
 
+  private async handleValidateFiles(args: ValidateFilesArgs) {
+    try {
+      const result = await this.callRuleServer('validateFiles', args);
+      return {
+        content: [
+          {
+            type: 'text',
+            text: JSON.stringify(result, null, 2),
+          },
+        ],
+      };
+    } catch (error) {
+      return {
+        content: [
+          {
+            type: 'text',
+            text: `Failed to validate files: ${error instanceof Error ? error.message : String(error)}`,
+          },
+        ],
+        isError: true,
+      };
+    }
+  }
+
+  private async handleValidateDiff(args: ValidateDiffArgs) {
+    try {
+      const result = await this.callRuleServer('validateDiff', args);
+      return {
+        content: [
+          {
+            type: 'text',
+            text: JSON.stringify(result, null, 2),
+          },
+        ],
+      };
+    } catch (error) {
+      return {
+        content: [
+          {
+            type: 'text',
+            text: `Failed to validate diff: ${error instanceof Error ? error.message : String(error)}`,
+          },
+        ],
+        isError: true,
+      };
+    }
+  }
+
+  private async handleFixCode(args: FixArgs) {
+    try {
+      const result = await this.callRuleServer('fixCode', args);
+      return {
+        content: [
+          {
+            type: 'text',
+            text: JSON.stringify(result, null, 2),
+          },
+        ],
+      };
+    } catch (error) {
+      return {
+        content: [
+          {
+            type: 'text',
+            text: `Failed to fix code: ${error instanceof Error ? error.message : String(error)}`,
+          },
+        ],
+        isError: true,
+      };
+    }
+  }
+
   private async handleGetProjectInfo(args: ProjectInfoArgs) {
     try {
       const result = await this.callRuleServer('getProjectInfo', args);
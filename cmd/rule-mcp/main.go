@@ -0,0 +1,107 @@
+// rule-mcp はRule MCP Serverのツールをstdio（改行区切りのJSON-RPC）で提供する
+// デスクトップのMCPクライアントからNode.jsなしで起動できる
+//
+//	rule-mcp --rules ./rules.json   # ローカルのルールファイルを使用
+//	DB_HOST=localhost rule-mcp      # PostgreSQL（DB_* 環境変数）を使用
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/infrastructure/database"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/infrastructure/rulesfile"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/interface/handler"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+)
+
+const defaultRulesFile = "rules.json"
+
+func main() {
+	// stdoutはプロトコル専用のため、ログはすべてstderrへ出す
+	log.SetOutput(os.Stderr)
+	log.SetPrefix("rule-mcp: ")
+
+	rulesPath := flag.String("rules", os.Getenv("RULES_FILE"), "path to a local rules file (default: PostgreSQL when DB_HOST is set, otherwise ./rules.json)")
+	flag.Parse()
+
+	repos, err := openRepositories(*rulesPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+	defer repos.close()
+
+	ruleUseCase := usecase.NewRuleUseCase(repos.rules, repos.globalRules, repos.projects)
+	globalRuleUseCase := usecase.NewGlobalRuleUseCase(repos.globalRules)
+	ruleEngine := usecase.NewRuleEngine()
+	ruleUseCase.SetRuleEngine(ruleEngine)
+	globalRuleUseCase.SetRuleEngine(ruleEngine)
+	if repos.languages != nil {
+		ruleUseCase.SetLanguageRepository(repos.languages)
+	}
+	ruleUseCase.SetViolationRepository(repos.violations)
+	defer ruleUseCase.Close()
+	ruleUseCase.SetGlobalRuleOverrideRepository(repos.overrides)
+	ruleUseCase.SetRulePackRepository(repos.packs)
+	projectDetector := usecase.NewProjectDetector(repos.projects, repos.rules)
+
+	mcpHandler := handler.NewMCPHandler(ruleUseCase, globalRuleUseCase, projectDetector)
+	if err := mcpHandler.ServeStdio(os.Stdin, os.Stdout); err != nil {
+		log.Fatal(err)
+	}
+}
+
+// repositories stdioサーバーが使うリポジトリ
+type repositories struct {
+	projects    domain.ProjectRepository
+	rules       domain.RuleRepository
+	globalRules domain.GlobalRuleRepository
+	languages   domain.LanguageRepository // ルールファイルでは言語マスタを持たないためnil
+	violations  domain.ViolationRepository
+	overrides   domain.GlobalRuleOverrideRepository
+	packs       domain.RulePackRepository
+	close       func()
+}
+
+// openRepositories ルールファイルまたはPostgreSQLのリポジトリを開く
+func openRepositories(rulesPath string) (*repositories, error) {
+	if rulesPath == "" && os.Getenv("DB_HOST") != "" {
+		db, err := database.NewPostgresDatabase(
+			os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"),
+		)
+		if err != nil {
+			return nil, err
+		}
+		return &repositories{
+			projects:    db,
+			rules:       database.NewPostgresRuleRepository(db.DB),
+			globalRules: database.NewPostgresGlobalRuleRepository(db.DB),
+			languages:   database.NewPostgresLanguageRepository(db.DB),
+			violations:  database.NewPostgresViolationRepository(db.DB),
+			overrides:   database.NewPostgresGlobalRuleOverrideRepository(db.DB),
+			packs:       database.NewPostgresRulePackRepository(db.DB),
+			close:       func() { db.Close() },
+		}, nil
+	}
+
+	if rulesPath == "" {
+		rulesPath = defaultRulesFile
+	}
+	store, err := rulesfile.Load(rulesPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load rules file: %w", err)
+	}
+	log.Printf("using rules file %s", rulesPath)
+	return &repositories{
+		projects:    store.Projects(),
+		rules:       store.Rules(),
+		globalRules: store.GlobalRules(),
+		violations:  store.Violations(),
+		overrides:   store.GlobalRuleOverrides(),
+		packs:       store.RulePacks(),
+		close:       func() {},
+	}, nil
+}
@@ -0,0 +1,246 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/infrastructure/database"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/interface/handler"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/httpx"
+	"github.com/gin-gonic/gin"
+)
+
+// adminSystem 管理画面向けのシステム情報（統計・設定・MCP集計・ログ）
+// いずれも未接続のリポジトリは nil のまま渡してよい
+type adminSystem struct {
+	db          *database.PostgresDatabase
+	userRepo    domain.UserRepository
+	projectRepo domain.ProjectRepository
+	ruleRepo    domain.RuleRepository
+	metricsRepo domain.MetricsRepository
+	tracker     *ActiveTracker
+	admin       *handler.AdminHandler
+}
+
+// registerAdminRoutes /api/v1/admin 配下のルートを登録する
+// 権限はルートごとに RequirePermission で宣言する（権限の無いルートが無いことを admin_routes_test.go で確認する）
+func registerAdminRoutes(admin *gin.RouterGroup, h *handler.AdminHandler, sys *adminSystem) {
+	requireAdmin := handler.RequirePermission(handler.PermissionAdmin)
+	manageUsers := handler.RequirePermission(handler.PermissionManageUsers)
+	manageRules := handler.RequirePermission(handler.PermissionManageRules)
+	manageRoles := handler.RequirePermission(handler.PermissionManageRoles)
+
+	admin.GET("/stats", requireAdmin, sys.stats)
+	admin.GET("/users", manageUsers, h.GetUsers)
+	admin.POST("/users", manageUsers, h.CreateUser)
+	admin.PUT("/users/:id", manageUsers, h.UpdateUser)
+	admin.DELETE("/users/:id", manageUsers, h.DeleteUser)
+	// APIキー（保存するのはハッシュのみ。キー全体は発行時のレスポンスでのみ返す）
+	admin.GET("/api-keys", requireAdmin, h.GetApiKeys)
+	admin.POST("/api-keys", requireAdmin, h.GenerateApiKey)
+	admin.PUT("/api-keys/:id", requireAdmin, h.UpdateApiKey)
+	admin.POST("/api-keys/:id/revoke", requireAdmin, h.RevokeApiKey)
+	admin.DELETE("/api-keys/:id", requireAdmin, h.DeleteApiKey)
+	// 設定: シンプルなキー・バリューストア
+	admin.GET("/settings", requireAdmin, sys.getSettings)
+	admin.PUT("/settings", requireAdmin, sys.updateSettings)
+	admin.GET("/mcp-stats", requireAdmin, sys.mcpStats)
+	admin.GET("/mcp-performance", requireAdmin, sys.mcpPerformance)
+	admin.GET("/system-logs", requireAdmin, sys.systemLogs)
+	// ルールオプションはルール編集画面の選択肢にも使う
+	admin.GET("/rule-options", manageRules, h.GetRuleOptions)
+	admin.POST("/rule-options", manageRules, h.AddRuleOption)
+	admin.DELETE("/rule-options", manageRules, h.DeleteRuleOption)
+	admin.GET("/roles", manageRoles, h.GetRoles)
+	admin.POST("/roles", manageRoles, h.CreateRole)
+	admin.PUT("/roles/:name", manageRoles, h.UpdateRole)
+	admin.DELETE("/roles/:name", manageRoles, h.DeleteRole)
+	// 一括エクスポート・インポート（全プロジェクトが対象のため管理者のみ）
+	admin.POST("/bulk-export", requireAdmin, h.BulkExport)
+	admin.POST("/bulk-import", requireAdmin, h.BulkImport)
+}
+
+// registerAdminAuthRoutes /api/v1/auth 配下で管理者のみが使うルート（新規ユーザーの承認）
+// ハンドラーは権限を確認しないため、admin_routes_test.go でルートの保護を確認する
+func registerAdminAuthRoutes(auth *gin.RouterGroup, h *handler.AuthHandler) {
+	requireAdmin := handler.RequirePermission(handler.PermissionAdmin)
+
+	auth.GET("/pending-users", requireAdmin, h.GetPendingUsers)
+	auth.POST("/approve-user", requireAdmin, h.ApproveUser)
+}
+
+// registerAdminAPIRoutes /api/v1 配下で管理者のみが使うルート（言語管理・グローバルルールとルールパックの編集・入出力）
+// 閲覧用のルートは main.go で登録する
+func registerAdminAPIRoutes(api *gin.RouterGroup, languages *handler.LanguageHandler, globalRules *handler.GlobalRuleHandler, rulePacks *handler.RulePackHandler) {
+	requireAdmin := handler.RequirePermission(handler.PermissionAdmin)
+
+	api.POST("/languages", requireAdmin, languages.CreateLanguage)
+	api.PUT("/languages/:code", requireAdmin, languages.UpdateLanguage)
+	api.DELETE("/languages/:code", requireAdmin, languages.DeleteLanguage)
+	// グローバルルールは継承する全プロジェクト（閲覧できないものを含む）に及ぶため管理者に限る
+	api.POST("/global-rules", requireAdmin, globalRules.CreateGlobalRule)
+	api.PUT("/global-rules/:language/:rule_id", requireAdmin, globalRules.UpdateGlobalRule)
+	api.DELETE("/global-rules/:language/:rule_id", requireAdmin, globalRules.DeleteGlobalRule)
+	api.POST("/global-rules/export", requireAdmin, globalRules.ExportGlobalRules)
+	api.POST("/global-rules/import", requireAdmin, globalRules.ImportGlobalRules)
+	// パックは適用先のプロジェクト（閲覧できないものを含む）のルールを変えるため、プロジェクト単位のロールではなく管理者に限る
+	api.POST("/rule-packs", requireAdmin, rulePacks.CreateRulePack)
+	api.PUT("/rule-packs/:name", requireAdmin, rulePacks.UpdateRulePack)
+	api.DELETE("/rule-packs/:name", requireAdmin, rulePacks.DeleteRulePack)
+}
+
+// stats 実データ化: totalUsers/totalProjects/totalRules + mcpRequests + activeSessions + systemLoad
+func (s *adminSystem) stats(c *gin.Context) {
+	if s.userRepo == nil || s.projectRepo == nil || s.ruleRepo == nil {
+		s.admin.GetStats(c)
+		return
+	}
+	users, _ := s.userRepo.GetAll()
+	projects, _ := s.projectRepo.GetAll()
+	totalRules := 0
+	for _, p := range projects {
+		rs, _ := s.ruleRepo.GetByProjectID(p.ProjectID)
+		totalRules += len(rs)
+	}
+	mcpCount := 0
+	if s.metricsRepo != nil {
+		mcpCount, _ = s.metricsRepo.GetMCPRequestsCountLast24h()
+	}
+	active := 0
+	if s.tracker != nil {
+		active = s.tracker.CountSince(10 * time.Minute)
+	}
+	// システム負荷（概算パーセント）: loadavg1 / NumCPU * 100
+	sysLoad := ""
+	if b, err := os.ReadFile("/proc/loadavg"); err == nil {
+		parts := strings.Fields(string(b))
+		if len(parts) > 0 {
+			if load1, err := strconv.ParseFloat(parts[0], 64); err == nil {
+				cores := float64(runtime.NumCPU())
+				sysLoad = fmt.Sprintf("%d%%", int((load1/cores)*100+0.5))
+			}
+		}
+	}
+	c.JSON(http.StatusOK, handler.AdminStats{TotalUsers: len(users), TotalProjects: len(projects), TotalRules: totalRules, ActiveApiKeys: 0, McpRequests: mcpCount, ActiveSessions: active, SystemLoad: sysLoad})
+}
+
+func (s *adminSystem) getSettings(c *gin.Context) {
+	if s.db == nil || s.db.DB == nil {
+		c.JSON(http.StatusOK, gin.H{"defaultAccessLevel": "public", "requestsPerMinute": 100})
+		return
+	}
+	rows, err := s.db.DB.Query(`SELECT key, value FROM settings`)
+	if err != nil {
+		c.JSON(http.StatusOK, gin.H{"defaultAccessLevel": "public", "requestsPerMinute": 100})
+		return
+	}
+	defer rows.Close()
+	conf := map[string]interface{}{}
+	for rows.Next() {
+		var k string
+		var v string
+		if err := rows.Scan(&k, &v); err == nil {
+			conf[k] = v
+		}
+	}
+	c.JSON(http.StatusOK, conf)
+}
+
+func (s *adminSystem) updateSettings(c *gin.Context) {
+	if s.db == nil || s.db.DB == nil {
+		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
+		return
+	}
+	var payload map[string]interface{}
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+	_, _ = s.db.DB.Exec(`CREATE TABLE IF NOT EXISTS settings (key TEXT PRIMARY KEY, value TEXT, updated_at TIMESTAMP DEFAULT NOW())`)
+	for k, v := range payload {
+		_, _ = s.db.DB.Exec(`INSERT INTO settings(key, value, updated_at) VALUES ($1, $2, NOW()) ON CONFLICT (key) DO UPDATE SET value = $2, updated_at = NOW()`, k, fmt.Sprint(v))
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Settings updated"})
+}
+
+func (s *adminSystem) mcpStats(c *gin.Context) {
+	if s.metricsRepo == nil {
+		c.JSON(http.StatusOK, []domain.MCPMethodStat{})
+		return
+	}
+	stats, err := s.metricsRepo.GetMCPStatsLast24h()
+	if err != nil {
+		c.JSON(http.StatusOK, []domain.MCPMethodStat{})
+		return
+	}
+	c.JSON(http.StatusOK, stats)
+}
+
+// mcpPerformance MCPパフォーマンス集計（平均、成功率、エラー率、p95）過去24時間
+func (s *adminSystem) mcpPerformance(c *gin.Context) {
+	if s.db == nil || s.db.DB == nil {
+		c.JSON(http.StatusOK, gin.H{"avgMs": 0, "successRate": 0, "errorRate": 0, "p95Ms": 0})
+		return
+	}
+	row := s.db.DB.QueryRow(`
+		SELECT
+			COALESCE(AVG(duration_ms)::INT,0) AS avg_ms,
+			COALESCE(SUM(CASE WHEN status='ok' THEN 1 ELSE 0 END),0) AS ok_cnt,
+			COALESCE(SUM(CASE WHEN status<>'ok' THEN 1 ELSE 0 END),0) AS err_cnt,
+			COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms),0) AS p95
+		FROM mcp_requests
+		WHERE created_at > NOW() - INTERVAL '24 hours'
+	`)
+	var avgMs int
+	var okCnt, errCnt int
+	var p95 float64
+	if err := row.Scan(&avgMs, &okCnt, &errCnt, &p95); err != nil {
+		c.JSON(http.StatusOK, gin.H{"avgMs": 0, "successRate": 0, "errorRate": 0, "p95Ms": 0})
+		return
+	}
+	total := okCnt + errCnt
+	var succRate, errRate float64
+	if total > 0 {
+		succRate = float64(okCnt) * 100.0 / float64(total)
+		errRate = float64(errCnt) * 100.0 / float64(total)
+	}
+	c.JSON(http.StatusOK, gin.H{"avgMs": avgMs, "successRate": succRate, "errorRate": errRate, "p95Ms": int(p95 + 0.5)})
+}
+
+// systemLogs 最近のMCPリクエストログをシステムログとして返す
+func (s *adminSystem) systemLogs(c *gin.Context) {
+	if s.db == nil || s.db.DB == nil {
+		c.JSON(http.StatusOK, []gin.H{})
+		return
+	}
+	rows, err := s.db.DB.Query(`SELECT created_at, method, status, duration_ms FROM mcp_requests ORDER BY created_at DESC LIMIT 100`)
+	if err != nil {
+		c.JSON(http.StatusOK, []gin.H{})
+		return
+	}
+	defer rows.Close()
+	var logs []gin.H
+	for rows.Next() {
+		var ts time.Time
+		var method, status string
+		var dur int
+		if err := rows.Scan(&ts, &method, &status, &dur); err != nil {
+			continue
+		}
+		level := "INFO"
+		if status != "ok" {
+			level = "ERROR"
+		} else if dur > 500 {
+			level = "WARN"
+		}
+		msg := fmt.Sprintf("MCP %s %s in %dms", method, status, dur)
+		logs = append(logs, gin.H{"timestamp": ts.Format(time.RFC3339), "level": level, "message": msg})
+	}
+	c.JSON(http.StatusOK, logs)
+}
@@ -0,0 +1,89 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/interface/handler"
+	"github.com/gin-gonic/gin"
+)
+
+// newAdminRouter 認証ミドルウェアの代わりに、指定した認証状態と権限をコンテキストに設定する
+func newAdminRouter(authenticated bool, permissions map[string]bool) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(handler.ContextKeyAuthenticated, authenticated)
+		c.Set(handler.ContextKeyPermissions, permissions)
+		c.Next()
+	})
+	adminHandler := handler.NewAdminHandler(nil, nil, nil, nil, nil, nil)
+	registerAdminRoutes(r.Group("/api/v1/admin"), adminHandler, &adminSystem{admin: adminHandler})
+	registerAdminAuthRoutes(r.Group("/api/v1/auth"), handler.NewAuthHandler("test-secret", nil, nil))
+	registerAdminAPIRoutes(r.Group("/api/v1"), handler.NewLanguageHandler(nil), handler.NewGlobalRuleHandler(nil, nil), handler.NewRulePackHandler(nil))
+	return r
+}
+
+func serveRoute(r *gin.Engine, route gin.RouteInfo) int {
+	path := strings.NewReplacer(":id", "1", ":name", "user", ":code", "go", ":language", "go", ":rule_id", "no-todo").Replace(route.Path)
+	req := httptest.NewRequest(route.Method, path, strings.NewReader(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w.Code
+}
+
+func TestEveryAdminRouteRequiresPermission(t *testing.T) {
+	anonymous := newAdminRouter(false, map[string]bool{})
+	// public ロールと同じく権限を1つも持たない認証済みの利用者
+	unprivileged := newAdminRouter(true, map[string]bool{})
+
+	// /api/v1/admin 配下に加え、main.go の外で登録する管理者専用ルート（ユーザー承認・言語管理・グローバルルールとルールパックの編集・入出力）も対象
+	routes := anonymous.Routes()
+	if len(routes) == 0 {
+		t.Fatal("no admin routes registered")
+	}
+	for _, want := range []string{"/api/v1/auth/approve-user", "/api/v1/languages/:code", "/api/v1/global-rules/import", "/api/v1/rule-packs/:name"} {
+		found := false
+		for _, route := range routes {
+			found = found || route.Path == want
+		}
+		if !found {
+			t.Errorf("admin-only route %s not registered by the admin registrars", want)
+		}
+	}
+	for _, route := range routes {
+		if code := serveRoute(anonymous, route); code != http.StatusUnauthorized {
+			t.Errorf("anonymous %s %s = %d, want 401", route.Method, route.Path, code)
+		}
+		if code := serveRoute(unprivileged, route); code != http.StatusForbidden {
+			t.Errorf("unprivileged %s %s = %d, want 403", route.Method, route.Path, code)
+		}
+	}
+}
+
+func TestUserRoleCannotRewriteSettings(t *testing.T) {
+	// user ロール（manage_rules のみ）は設定・APIキー・一括入出力・グローバルルールとルールパックの編集を扱えない
+	r := newAdminRouter(true, map[string]bool{handler.PermissionManageRules: true})
+	for _, route := range []gin.RouteInfo{
+		{Method: http.MethodPut, Path: "/api/v1/admin/settings"},
+		{Method: http.MethodPost, Path: "/api/v1/admin/api-keys"},
+		{Method: http.MethodPost, Path: "/api/v1/admin/bulk-import"},
+		{Method: http.MethodDelete, Path: "/api/v1/admin/users/:id"},
+		// グローバルルール・パックの編集は適用先の全プロジェクトに及ぶため manage_rules では行えない
+		{Method: http.MethodPost, Path: "/api/v1/global-rules"},
+		{Method: http.MethodPut, Path: "/api/v1/global-rules/:language/:rule_id"},
+		{Method: http.MethodDelete, Path: "/api/v1/global-rules/:language/:rule_id"},
+		{Method: http.MethodPut, Path: "/api/v1/rule-packs/:name"},
+		{Method: http.MethodDelete, Path: "/api/v1/rule-packs/:name"},
+	} {
+		if code := serveRoute(r, route); code != http.StatusForbidden {
+			t.Errorf("%s %s = %d, want 403", route.Method, route.Path, code)
+		}
+	}
+	if code := serveRoute(r, gin.RouteInfo{Method: http.MethodGet, Path: "/api/v1/admin/rule-options"}); code == http.StatusForbidden || code == http.StatusUnauthorized {
+		t.Errorf("GET /api/v1/admin/rule-options with manage_rules = %d", code)
+	}
+}
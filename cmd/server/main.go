@@ -1,15 +1,9 @@
 package main
 
 import (
-	"crypto/rand"
-	"fmt"
 	"log"
-	"math/big"
 	"net/http"
 	"os"
-	"runtime"
-	"strconv"
-	"strings"
 	"sync"
 	"time"
 
@@ -20,7 +14,6 @@ import (
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/config"
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/httpx"
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt/v5"
 )
 
 type ActiveTracker struct {
@@ -43,22 +36,6 @@ func (t *ActiveTracker) CountSince(d time.Duration) int {
 	return c
 }
 
-// generateRandomString セキュアな乱数で英数字文字列を生成
-func generateRandomString(length int) string {
-	const letters = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, length)
-	for i := range b {
-		nBig, err := rand.Int(rand.Reader, big.NewInt(int64(len(letters))))
-		if err != nil {
-			// フォールバック（低品質）
-			b[i] = letters[time.Now().UnixNano()%int64(len(letters))]
-			continue
-		}
-		b[i] = letters[nBig.Int64()]
-	}
-	return string(b)
-}
-
 func main() {
 	cfg := config.LoadConfig()
 
@@ -69,7 +46,12 @@ func main() {
 	var ruleOptionRepo domain.RuleOptionRepository
 	var roleRepo domain.RoleRepository
 	var metricsRepo domain.MetricsRepository
+	var apiKeyRepo domain.APIKeyRepository
+	var refreshTokenRepo domain.RefreshTokenRepository
+	var tokenDenylistRepo domain.TokenDenylistRepository
 	activeTracker := NewActiveTracker()
+	// ルール変更時のキャッシュ破棄を共有するため、ルールエンジンは1つだけ生成する
+	ruleEngine := usecase.NewRuleEngine()
 
 	db, err := database.NewPostgresDatabase(
 		os.Getenv("DB_HOST"), os.Getenv("DB_PORT"), os.Getenv("DB_USER"), os.Getenv("DB_PASSWORD"), os.Getenv("DB_NAME"),
@@ -88,6 +70,9 @@ func main() {
 		userRepo = database.NewPostgresUserRepository(db.DB)
 		roleRepo = database.NewPostgresRoleRepository(db.DB)
 		metricsRepo = database.NewPostgresMetricsRepository(db.DB)
+		apiKeyRepo = database.NewPostgresAPIKeyRepository(db.DB)
+		refreshTokenRepo = database.NewPostgresRefreshTokenRepository(db.DB)
+		tokenDenylistRepo = database.NewPostgresTokenDenylistRepository(db.DB)
 	}
 
 	if cfg.IsProduction() {
@@ -98,31 +83,24 @@ func main() {
 	r.Use(httpx.RecoveryJSON())
 	r.Use(httpx.RequestID())
 
-	allowedOrigins := os.Getenv("ALLOWED_ORIGINS")
-	if cfg.IsProduction() && allowedOrigins == "" {
+	allowedOrigins := httpx.ParseOrigins(os.Getenv("ALLOWED_ORIGINS"))
+	if cfg.IsProduction() && len(allowedOrigins) == 0 {
 		log.Fatal("ALLOWED_ORIGINS must be set in production")
 	}
 	r.Use(func(c *gin.Context) {
 		origin := c.Request.Header.Get("Origin")
-		if allowedOrigins != "" {
-			parts := strings.Split(allowedOrigins, ",")
-			allowed := false
-			for _, part := range parts {
-				if strings.TrimSpace(part) == origin {
-					allowed = true
-					break
-				}
-			}
-			if allowed {
+		if len(allowedOrigins) > 0 {
+			if httpx.OriginAllowed(allowedOrigins, origin) {
 				c.Header("Access-Control-Allow-Origin", origin)
 			} else {
-				c.Header("Access-Control-Allow-Origin", strings.TrimSpace(parts[0]))
+				c.Header("Access-Control-Allow-Origin", allowedOrigins[0])
 			}
 		} else {
 			c.Header("Access-Control-Allow-Origin", "*")
 		}
 		c.Header("Access-Control-Allow-Methods", "GET, POST, PUT, DELETE, OPTIONS")
-		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization")
+		c.Header("Access-Control-Allow-Headers", "Origin, Content-Type, Content-Length, Accept-Encoding, X-CSRF-Token, Authorization, X-API-Key, Mcp-Session-Id, Mcp-Protocol-Version")
+		c.Header("Access-Control-Expose-Headers", "Mcp-Session-Id")
 		c.Header("Access-Control-Allow-Credentials", "true")
 		if c.Request.Method == "OPTIONS" {
 			c.AbortWithStatus(http.StatusOK)
@@ -138,44 +116,26 @@ func main() {
 	if jwtSecret == "" {
 		jwtSecret = "default-secret-key-change-in-production"
 	}
-	r.Use(func(c *gin.Context) {
-		c.Set("userRole", "public")
-		c.Set("permissions", map[string]bool{"manage_users": false, "manage_rules": false, "manage_roles": false})
-		auth := c.GetHeader("Authorization")
-		if len(auth) > 7 && (auth[:7] == "Bearer " || auth[:7] == "bearer ") {
-			tokenStr := auth[7:]
-			claims := &handler.Claims{}
-			token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) { return []byte(jwtSecret), nil })
-			if err == nil && token.Valid {
-				if claims.Role != "" {
-					c.Set("userRole", claims.Role)
-				}
-				// 権限の検索（フォールバック付き）
-				perm := map[string]bool{"manage_users": false, "manage_rules": false, "manage_roles": false}
-				loaded := false
-				if roleRepo != nil {
-					if role, err := roleRepo.GetByName(claims.Role); err == nil && role.Permissions != nil {
-						perm = role.Permissions
-						loaded = true
-					}
-				}
-				if !loaded {
-					switch claims.Role {
-					case "admin":
-						perm["manage_users"] = true
-						perm["manage_rules"] = true
-						perm["manage_roles"] = true
-					case "user":
-						perm["manage_rules"] = true
-					}
-				}
-				c.Set("permissions", perm)
-				// アクティブセッションを追跡
-				activeTracker.Touch(claims.Username)
-			}
-		}
-		c.Next()
-	})
+	// アクティブセッションを追跡しながらJWT・APIキーを検証
+	var apiKeyUseCase *usecase.APIKeyUseCase
+	if apiKeyRepo != nil {
+		apiKeyUseCase = usecase.NewAPIKeyUseCase(apiKeyRepo)
+	}
+	// アクセストークンは短命にし、リフレッシュトークンのローテーションと jti の拒否リストで失効させる
+	authTokenUseCase := usecase.NewAuthTokenUseCase(jwtSecret)
+	authTokenUseCase.SetTTL(cfg.AccessTokenTTL, cfg.RefreshTokenTTL)
+	if refreshTokenRepo != nil {
+		authTokenUseCase.SetRefreshTokenRepository(refreshTokenRepo)
+		authTokenUseCase.SetDenylistRepository(tokenDenylistRepo)
+	}
+	if userRepo != nil {
+		authTokenUseCase.SetUserRepository(userRepo)
+	}
+	authenticator := handler.NewAuthenticator(jwtSecret, roleRepo)
+	authenticator.SetTokenUseCase(authTokenUseCase)
+	authenticator.SetAPIKeyUseCase(apiKeyUseCase)
+	authenticator.SetOnAuthenticated(activeTracker.Touch)
+	r.Use(authenticator.Middleware())
 
 	healthHandler := handler.NewHealthHandler()
 	r.GET("/api/v1/health", healthHandler.HealthCheck)
@@ -185,332 +145,116 @@ func main() {
 	r.GET("/metrics", metricsHandler.Metrics)
 
 	authHandler := handler.NewAuthHandler(jwtSecret, userRepo, roleRepo)
+	authHandler.SetTokenUseCase(authTokenUseCase)
 	auth := r.Group("/api/v1/auth")
 	{
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/refresh", authHandler.Refresh)
 		auth.POST("/logout", authHandler.Logout)
 		auth.POST("/register", authHandler.Register)
 		auth.GET("/validate", authHandler.ValidateToken)
 		auth.GET("/me", authHandler.Me)
 		auth.POST("/change-password", authHandler.ChangePassword)
-		auth.GET("/pending-users", authHandler.GetPendingUsers)
-		auth.POST("/approve-user", authHandler.ApproveUser)
 	}
+	registerAdminAuthRoutes(auth, authHandler)
 
 	var adminHandler *handler.AdminHandler
 	if projectRepo != nil {
 		adminHandler = handler.NewAdminHandler(userRepo, projectRepo, ruleRepo, globalRuleRepo, ruleOptionRepo, roleRepo)
+		adminHandler.SetAPIKeyUseCase(apiKeyUseCase)
+		adminHandler.SetAuthTokenUseCase(authTokenUseCase)
 	} else {
 		if cfg.IsProduction() {
 			log.Fatal("Database repositories are not initialized in production")
 		}
 		adminHandler = handler.NewAdminHandler(nil, nil, nil, nil, nil, nil)
 	}
-	admin := r.Group("/api/v1/admin")
-	{
-		admin.GET("/stats", func(c *gin.Context) {
-			// 実データ化: totalUsers/totalProjects/totalRules + mcpRequests + activeSessions + systemLoad
-			if projectRepo == nil || ruleRepo == nil {
-				adminHandler.GetStats(c)
-				return
-			}
-			// 既存のものを再利用するが、一部のフィールドを上書き
-			// カウントを計算するために元のハンドラーを呼び出し
-			// シンプルにするため、ハンドラーを呼び出してからレスポンスを変更するのは複雑なので、代わりに小さなインライン実装を行う
-			users, _ := userRepo.GetAll()
-			projects, _ := projectRepo.GetAll()
-			totalRules := 0
-			for _, p := range projects {
-				rs, _ := ruleRepo.GetByProjectID(p.ProjectID)
-				totalRules += len(rs)
-			}
-			mcpCount := 0
-			if metricsRepo != nil {
-				mcpCount, _ = metricsRepo.GetMCPRequestsCountLast24h()
-			}
-			active := activeTracker.CountSince(10 * time.Minute)
-			// システム負荷（概算パーセント）: loadavg1 / NumCPU * 100
-			sysLoad := ""
-			if b, err := os.ReadFile("/proc/loadavg"); err == nil {
-				parts := strings.Fields(string(b))
-				if len(parts) > 0 {
-					if load1, err := strconv.ParseFloat(parts[0], 64); err == nil {
-						cores := float64(runtime.NumCPU())
-						pct := int((load1/cores)*100 + 0.5)
-						sysLoad = strings.TrimSpace((func(v int) string { return fmt.Sprintf("%d%%", v) })(pct))
-					}
-				}
-			}
-			c.JSON(http.StatusOK, handler.AdminStats{TotalUsers: len(users), TotalProjects: len(projects), TotalRules: totalRules, ActiveApiKeys: 0, McpRequests: mcpCount, ActiveSessions: active, SystemLoad: sysLoad})
-		})
-		admin.GET("/users", adminHandler.GetUsers)
-		admin.POST("/users", adminHandler.CreateUser)
-		admin.PUT("/users/:id", adminHandler.UpdateUser)
-		admin.DELETE("/users/:id", adminHandler.DeleteUser)
-		// データベースからの実際のAPIキー
-		admin.GET("/api-keys", func(c *gin.Context) {
-			if db == nil || db.DB == nil {
-				c.JSON(http.StatusOK, []gin.H{})
-				return
-			}
-			rows, err := db.DB.Query(`SELECT id, name, key_hash, access_level, is_active, created_at, updated_at FROM api_keys ORDER BY created_at DESC LIMIT 100`)
-			if err != nil {
-				httpx.JSONFromError(c, err)
-				return
-			}
-			defer rows.Close()
-			var keys []gin.H
-			for rows.Next() {
-				var id int
-				var name, keyHash, accessLevel string
-				var isActive bool
-				var createdAt, updatedAt time.Time
-				if err := rows.Scan(&id, &name, &keyHash, &accessLevel, &isActive, &createdAt, &updatedAt); err != nil {
-					continue
-				}
-				status := "active"
-				if !isActive {
-					status = "inactive"
-				}
-				keys = append(keys, gin.H{"id": id, "name": name, "key": keyHash, "accessLevel": accessLevel, "status": status, "createdAt": createdAt.Format(time.RFC3339), "lastUsed": updatedAt.Format(time.RFC3339)})
-			}
-			c.JSON(http.StatusOK, keys)
-		})
-		admin.POST("/api-keys", func(c *gin.Context) {
-			var req struct {
-				Name        string `json:"name"`
-				AccessLevel string `json:"accessLevel"`
-			}
-			if err := c.ShouldBindJSON(&req); err != nil {
-				httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
-				return
-			}
-			if db == nil || db.DB == nil {
-				httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
-				return
-			}
-			apiKey := fmt.Sprintf("%s_%d_%s", req.AccessLevel, time.Now().Unix(), generateRandomString(16))
-			_, err := db.DB.Exec(`INSERT INTO api_keys (key_hash, name, access_level, is_active, created_by, created_at, updated_at) VALUES ($1, $2, $3, true, $4, NOW(), NOW())`, apiKey, req.Name, req.AccessLevel, "admin")
-			if err != nil {
-				httpx.JSONFromError(c, err)
-				return
-			}
-			c.JSON(http.StatusCreated, gin.H{"id": time.Now().Unix(), "name": req.Name, "key": apiKey, "accessLevel": req.AccessLevel, "status": "active", "createdAt": time.Now().Format(time.RFC3339), "lastUsed": ""})
-		})
-		admin.DELETE("/api-keys/:id", func(c *gin.Context) {
-			if db == nil || db.DB == nil {
-				httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
-				return
-			}
-			id := c.Param("id")
-			_, err := db.DB.Exec(`DELETE FROM api_keys WHERE id = $1`, id)
-			if err != nil {
-				httpx.JSONFromError(c, err)
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"message": "API Key deleted successfully"})
-		})
-		// APIキーの更新（name/description/is_active）
-		admin.PUT("/api-keys/:id", func(c *gin.Context) {
-			if db == nil || db.DB == nil {
-				httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
-				return
-			}
-			id := c.Param("id")
-			var req struct {
-				Name        *string `json:"name"`
-				Description *string `json:"description"`
-				IsActive    *bool   `json:"isActive"`
-			}
-			if err := c.ShouldBindJSON(&req); err != nil {
-				httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
-				return
-			}
-			q := "UPDATE api_keys SET updated_at = NOW()"
-			args := []interface{}{}
-			idx := 1
-			if req.Name != nil {
-				q += ", name = $" + fmt.Sprint(idx)
-				args = append(args, *req.Name)
-				idx++
-			}
-			if req.Description != nil {
-				q += ", description = $" + fmt.Sprint(idx)
-				args = append(args, *req.Description)
-				idx++
-			}
-			if req.IsActive != nil {
-				q += ", is_active = $" + fmt.Sprint(idx)
-				args = append(args, *req.IsActive)
-				idx++
-			}
-			q += " WHERE id = $" + fmt.Sprint(idx)
-			args = append(args, id)
-			if _, err := db.DB.Exec(q, args...); err != nil {
-				httpx.JSONFromError(c, err)
-				return
-			}
-			c.JSON(http.StatusOK, gin.H{"message": "API Key updated"})
-		})
-		// 設定: シンプルなキー・バリューストア
-		admin.GET("/settings", func(c *gin.Context) {
-			if db == nil || db.DB == nil {
-				c.JSON(http.StatusOK, gin.H{"defaultAccessLevel": "public", "requestsPerMinute": 100})
-				return
-			}
-			rows, err := db.DB.Query(`SELECT key, value FROM settings`)
-			if err != nil {
-				c.JSON(http.StatusOK, gin.H{"defaultAccessLevel": "public", "requestsPerMinute": 100})
-				return
-			}
-			defer rows.Close()
-			conf := map[string]interface{}{}
-			for rows.Next() {
-				var k string
-				var v string
-				if err := rows.Scan(&k, &v); err == nil {
-					conf[k] = v
-				}
-			}
-			c.JSON(http.StatusOK, conf)
-		})
-		admin.PUT("/settings", func(c *gin.Context) {
-			if db == nil || db.DB == nil {
-				httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
-				return
-			}
-			var payload map[string]interface{}
-			if err := c.ShouldBindJSON(&payload); err != nil {
-				httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
-				return
-			}
-			_, _ = db.DB.Exec(`CREATE TABLE IF NOT EXISTS settings (key TEXT PRIMARY KEY, value TEXT, updated_at TIMESTAMP DEFAULT NOW())`)
-			for k, v := range payload {
-				_, _ = db.DB.Exec(`INSERT INTO settings(key, value, updated_at) VALUES ($1, $2, NOW()) ON CONFLICT (key) DO UPDATE SET value = $2, updated_at = NOW()`, k, fmt.Sprint(v))
-			}
-			c.JSON(http.StatusOK, gin.H{"message": "Settings updated"})
-		})
-		admin.GET("/mcp-stats", func(c *gin.Context) {
-			if metricsRepo == nil {
-				c.JSON(http.StatusOK, []domain.MCPMethodStat{})
-				return
-			}
-			s, err := metricsRepo.GetMCPStatsLast24h()
-			if err != nil {
-				c.JSON(http.StatusOK, []domain.MCPMethodStat{})
-				return
-			}
-			c.JSON(http.StatusOK, s)
-		})
-		// MCPパフォーマンス集計（平均、成功率、エラー率、p95）過去24時間
-		admin.GET("/mcp-performance", func(c *gin.Context) {
-			if db == nil || db.DB == nil {
-				c.JSON(http.StatusOK, gin.H{"avgMs": 0, "successRate": 0, "errorRate": 0, "p95Ms": 0})
-				return
-			}
-			row := db.DB.QueryRow(`
-				SELECT 
-					COALESCE(AVG(duration_ms)::INT,0) AS avg_ms,
-					COALESCE(SUM(CASE WHEN status='ok' THEN 1 ELSE 0 END),0) AS ok_cnt,
-					COALESCE(SUM(CASE WHEN status<>'ok' THEN 1 ELSE 0 END),0) AS err_cnt,
-					COALESCE(PERCENTILE_CONT(0.95) WITHIN GROUP (ORDER BY duration_ms),0) AS p95
-				FROM mcp_requests 
-				WHERE created_at > NOW() - INTERVAL '24 hours'
-			`)
-			var avgMs int
-			var okCnt, errCnt int
-			var p95 float64
-			if err := row.Scan(&avgMs, &okCnt, &errCnt, &p95); err != nil {
-				c.JSON(http.StatusOK, gin.H{"avgMs": 0, "successRate": 0, "errorRate": 0, "p95Ms": 0})
-				return
-			}
-			total := okCnt + errCnt
-			var succRate, errRate float64
-			if total > 0 {
-				succRate = float64(okCnt) * 100.0 / float64(total)
-				errRate = float64(errCnt) * 100.0 / float64(total)
-			}
-			c.JSON(http.StatusOK, gin.H{"avgMs": avgMs, "successRate": succRate, "errorRate": errRate, "p95Ms": int(p95 + 0.5)})
-		})
-		admin.GET("/system-logs", func(c *gin.Context) {
-			// 最近のMCPリクエストログをシステムログとして返す
-			if db == nil || db.DB == nil {
-				c.JSON(http.StatusOK, []gin.H{})
-				return
-			}
-			rows, err := db.DB.Query(`SELECT created_at, method, status, duration_ms FROM mcp_requests ORDER BY created_at DESC LIMIT 100`)
-			if err != nil {
-				c.JSON(http.StatusOK, []gin.H{})
-				return
-			}
-			defer rows.Close()
-			var logs []gin.H
-			for rows.Next() {
-				var ts time.Time
-				var method, status string
-				var dur int
-				if err := rows.Scan(&ts, &method, &status, &dur); err != nil {
-					continue
-				}
-				level := "INFO"
-				if status != "ok" {
-					level = "ERROR"
-				} else if dur > 500 {
-					level = "WARN"
-				}
-				msg := fmt.Sprintf("MCP %s %s in %dms", method, status, dur)
-				logs = append(logs, gin.H{"timestamp": ts.Format(time.RFC3339), "level": level, "message": msg})
-			}
-			c.JSON(http.StatusOK, logs)
-		})
-		admin.GET("/rule-options", adminHandler.GetRuleOptions)
-		admin.POST("/rule-options", adminHandler.AddRuleOption)
-		admin.DELETE("/rule-options", adminHandler.DeleteRuleOption)
-		admin.GET("/roles", adminHandler.GetRoles)
-		admin.POST("/roles", adminHandler.CreateRole)
-		admin.PUT("/roles/:name", adminHandler.UpdateRole)
-		admin.DELETE("/roles/:name", adminHandler.DeleteRole)
-		// 一括エクスポート・インポート
-		admin.POST("/bulk-export", adminHandler.BulkExport)
-		admin.POST("/bulk-import", adminHandler.BulkImport)
-	}
+	registerAdminRoutes(r.Group("/api/v1/admin"), adminHandler, &adminSystem{
+		db:          db,
+		userRepo:    userRepo,
+		projectRepo: projectRepo,
+		ruleRepo:    ruleRepo,
+		metricsRepo: metricsRepo,
+		tracker:     activeTracker,
+		admin:       adminHandler,
+	})
 
 	if projectRepo != nil {
 		projectUseCase := usecase.NewProjectUseCase(projectRepo)
 		ruleUseCase := usecase.NewRuleUseCase(ruleRepo, globalRuleRepo, projectRepo)
 		globalRuleUseCase := usecase.NewGlobalRuleUseCase(globalRuleRepo)
+		projectUseCase.SetRuleEngine(ruleEngine)
+		ruleUseCase.SetRuleEngine(ruleEngine)
+		globalRuleUseCase.SetRuleEngine(ruleEngine)
+		// ルール変更をMCPセッションへ通知するため、変更イベントを共有する
+		ruleEvents := usecase.NewRuleEvents()
+		projectUseCase.SetRuleEvents(ruleEvents)
+		ruleUseCase.SetRuleEvents(ruleEvents)
+		globalRuleUseCase.SetRuleEvents(ruleEvents)
+		// 公開範囲とメンバーのロールによる認可をREST・MCPで共有する
+		projectAccess := usecase.NewProjectAccessUseCase(projectRepo)
+		projectAccess.SetMemberRepository(database.NewPostgresProjectMemberRepository(db.DB))
+		projectAccess.SetUserRepository(userRepo)
 		projectHandler := handler.NewProjectHandler(projectUseCase)
+		projectHandler.SetProjectAccess(projectAccess)
 		ruleHandler := handler.NewRuleHandler(ruleUseCase)
+		ruleHandler.SetProjectAccess(projectAccess)
 		languageRepo := database.NewPostgresLanguageRepository(db.DB)
+		// getProjectInfo の言語メタデータと違反件数の集計に使う
+		ruleUseCase.SetLanguageRepository(languageRepo)
+		ruleUseCase.SetViolationRepository(database.NewPostgresViolationRepository(db.DB))
+		ruleUseCase.SetGlobalRuleOverrideRepository(database.NewPostgresGlobalRuleOverrideRepository(db.DB))
+		rulePackRepo := database.NewPostgresRulePackRepository(db.DB)
+		ruleUseCase.SetRulePackRepository(rulePackRepo)
+		rulePackUseCase := usecase.NewRulePackUseCase(rulePackRepo, projectRepo)
+		rulePackUseCase.SetRuleEngine(ruleEngine)
+		rulePackUseCase.SetRuleEvents(ruleEvents)
+		rulePackHandler := handler.NewRulePackHandler(rulePackUseCase)
+		// 一括インポートでもキャッシュの破棄とMCPセッションへの通知を行うため、同じユースケースを使う
+		adminHandler.SetRuleUseCases(projectUseCase, ruleUseCase, globalRuleUseCase)
+		rulePackHandler.SetProjectAccess(projectAccess)
 		languageUseCase := usecase.NewLanguageUseCase(languageRepo)
 		languageHandler := handler.NewLanguageHandler(languageUseCase)
 		globalRuleHandler := handler.NewGlobalRuleHandler(globalRuleUseCase, languageRepo)
 		api := r.Group("/api/v1")
+		// プロジェクトスコープ付きのAPIキーはスコープ外のプロジェクトを操作できない
+		api.Use(handler.RestrictProjectScope())
+		// 必要な権限はルートごとに宣言する（プロジェクト単位の認可はハンドラーで行う）
+		manageRules := handler.RequirePermission(handler.PermissionManageRules)
 		{
 			api.GET("/projects", projectHandler.GetProjects)
 			api.GET("/projects/:project_id", projectHandler.GetProject)
 			api.POST("/projects", projectHandler.CreateProject)
 			api.PUT("/projects/:project_id", projectHandler.UpdateProject)
 			api.DELETE("/projects/:project_id", projectHandler.DeleteProject)
+			api.GET("/projects/:project_id/members", projectHandler.GetMembers)
+			api.POST("/projects/:project_id/members", projectHandler.AddMember)
+			api.PUT("/projects/:project_id/members/:user_id", projectHandler.UpdateMember)
+			api.DELETE("/projects/:project_id/members/:user_id", projectHandler.RemoveMember)
+			api.GET("/projects/:project_id/global-rule-overrides", ruleHandler.GetGlobalRuleOverrides)
+			api.PUT("/projects/:project_id/global-rule-overrides/:language/:rule_id", ruleHandler.SetGlobalRuleOverride)
+			api.DELETE("/projects/:project_id/global-rule-overrides/:language/:rule_id", ruleHandler.DeleteGlobalRuleOverride)
+			api.GET("/projects/:project_id/rule-packs", rulePackHandler.GetProjectRulePacks)
+			api.PUT("/projects/:project_id/rule-packs/:name", rulePackHandler.AttachRulePack)
+			api.DELETE("/projects/:project_id/rule-packs/:name", rulePackHandler.DetachRulePack)
 			api.GET("/rules", ruleHandler.GetRules)
 			api.GET("/rules/:project_id/:rule_id", ruleHandler.GetRule)
 			api.POST("/rules", ruleHandler.CreateRule)
 			api.PUT("/rules/:project_id/:rule_id", ruleHandler.UpdateRule)
 			api.DELETE("/rules/:project_id/:rule_id", ruleHandler.DeleteRule)
 			api.POST("/rules/validate", ruleHandler.ValidateCode)
-			api.POST("/rules/export", ruleHandler.ExportRules)
-			api.POST("/rules/import", ruleHandler.ImportRules)
+			api.POST("/rules/validate-files", ruleHandler.ValidateFiles)
+			api.POST("/rules/test-pattern", ruleHandler.TestPattern)
+			api.POST("/rules/export", manageRules, ruleHandler.ExportRules)
+			api.POST("/rules/import", manageRules, ruleHandler.ImportRules)
 			api.GET("/global-rules/:language", globalRuleHandler.GetGlobalRules)
-			api.POST("/global-rules", globalRuleHandler.CreateGlobalRule)
-			api.DELETE("/global-rules/:language/:rule_id", globalRuleHandler.DeleteGlobalRule)
+			api.GET("/global-rules/:language/:rule_id", globalRuleHandler.GetGlobalRule)
+			api.GET("/rule-packs", rulePackHandler.GetRulePacks)
+			api.GET("/rule-packs/:name", rulePackHandler.GetRulePack)
 			api.GET("/languages", languageHandler.GetLanguages)
 			api.GET("/languages/:code", languageHandler.GetLanguage)
-			api.POST("/languages", languageHandler.CreateLanguage)
-			api.PUT("/languages/:code", languageHandler.UpdateLanguage)
-			api.DELETE("/languages/:code", languageHandler.DeleteLanguage)
-			api.POST("/global-rules/export", globalRuleHandler.ExportGlobalRules)
-			api.POST("/global-rules/import", globalRuleHandler.ImportGlobalRules)
 		}
+		registerAdminAPIRoutes(api, languageHandler, globalRuleHandler, rulePackHandler)
 
 		// MCPエンドポイント
 		projectDetector := usecase.NewProjectDetector(projectRepo, ruleRepo)
@@ -519,14 +263,30 @@ func main() {
 		mcpHandler.SetMetricsRepo(metricsRepo)
 		// メトリクスハンドラーを注入
 		mcpHandler.SetMetricsHandler(metricsHandler)
+		mcpHandler.SetRuleEvents(ruleEvents)
+		mcpHandler.SetProjectAccess(projectAccess)
+		// WebSocketのOrigin検証はCORSと同じ許可リストを使う
+		mcpHandler.SetAllowedOrigins(allowedOrigins)
 		mcp := r.Group("/mcp")
+		if os.Getenv("MCP_REQUIRE_AUTH") == "true" {
+			// HTTP・Streamable HTTP・WebSocketで同じ認証を要求
+			mcp.Use(handler.RequireAuthentication())
+		}
 		{
+			// Streamable HTTP（セッション・SSEによるサーバー通知）
+			mcp.POST("", mcpHandler.HandleStreamablePost)
+			mcp.GET("", mcpHandler.HandleStreamableGet)
+			mcp.DELETE("", mcpHandler.HandleStreamableDelete)
 			mcp.POST("/request", mcpHandler.HandleMCPRequest)
 			mcp.GET("/ws", mcpHandler.HandleWebSocket)
 		}
 	} else {
 		simpleMCPHandler := handler.NewSimpleMCPHandler()
+		simpleMCPHandler.SetAllowedOrigins(allowedOrigins)
 		mcp := r.Group("/mcp")
+		if os.Getenv("MCP_REQUIRE_AUTH") == "true" {
+			mcp.Use(handler.RequireAuthentication())
+		}
 		{
 			mcp.POST("/request", simpleMCPHandler.HandleMCPRequest)
 			mcp.GET("/ws", simpleMCPHandler.HandleWebSocket)
@@ -540,7 +300,7 @@ func main() {
 	} else {
 		log.Printf("Database: JSON file mode")
 	}
-	log.Printf("MCP Endpoints: /mcp/request, /mcp/ws")
+	log.Printf("MCP Endpoints: /mcp (Streamable HTTP), /mcp/request, /mcp/ws")
 
 	if err := r.Run(cfg.GetAddress()); err != nil {
 		log.Fatal("Failed to start server:", err)
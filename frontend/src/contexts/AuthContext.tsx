@@ -87,10 +87,13 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
   const login = async (username: string, password: string) => {
     try {
       const response = await api.post('/auth/login', { username, password });
-      const { token, user: userData } = response.data;
+      const { token, refresh_token, user: userData } = response.data;
       
       // トークンとユーザー情報を保存
       localStorage.setItem('auth_token', token);
+      if (refresh_token) {
+        localStorage.setItem('refresh_token', refresh_token);
+      }
       localStorage.setItem('user', JSON.stringify(userData));
       
       // ユーザー情報を設定
@@ -107,13 +110,14 @@ export const AuthProvider: React.FC<AuthProviderProps> = ({ children }) => {
 
   const logout = async () => {
     try {
-      // ログアウトAPIを呼び出し
-      await api.post('/auth/logout');
+      // ログアウトAPIを呼び出し（サーバー側でアクセストークンとリフレッシュトークンを失効させる）
+      await api.post('/auth/logout', { refresh_token: localStorage.getItem('refresh_token') ?? '' });
     } catch (error) {
       console.error('Logout error:', error);
     } finally {
       // ローカル状態をクリア
       localStorage.removeItem('auth_token');
+      localStorage.removeItem('refresh_token');
       localStorage.removeItem('user');
       setUser(null);
       delete api.defaults.headers.common['Authorization'];
@@ -20,12 +20,46 @@ api.interceptors.request.use(
   }
 );
 
+// アクセストークンは短命のため、401の場合はリフレッシュトークンで1度だけ再発行してリトライする
+let refreshing: Promise<string> | null = null;
+
+const refreshAccessToken = async (): Promise<string> => {
+  const refreshToken = localStorage.getItem('refresh_token');
+  if (!refreshToken) {
+    throw new Error('no refresh token');
+  }
+  const response = await axios.post(`${API_BASE_URL}/auth/refresh`, { refresh_token: refreshToken });
+  const { token, refresh_token } = response.data;
+  localStorage.setItem('auth_token', token);
+  if (refresh_token) {
+    localStorage.setItem('refresh_token', refresh_token);
+  }
+  api.defaults.headers.common['Authorization'] = `Bearer ${token}`;
+  return token;
+};
+
 // エラーハンドリング用のレスポンスインターセプター
 api.interceptors.response.use(
   (response) => {
     return response;
   },
-  (error) => {
+  async (error) => {
+    const original = error.config;
+    const isAuthRequest = original?.url?.startsWith('/auth/');
+    if (error.response?.status === 401 && original && !original._retried && !isAuthRequest && localStorage.getItem('refresh_token')) {
+      original._retried = true;
+      try {
+        refreshing = refreshing ?? refreshAccessToken();
+        const token = await refreshing;
+        original.headers = { ...original.headers, Authorization: `Bearer ${token}` };
+        return api(original);
+      } catch {
+        // リフレッシュに失敗した場合は下のログイン画面へのリダイレクトに進む
+      } finally {
+        refreshing = null;
+      }
+    }
+
     const data = error.response?.data;
     // バックエンド統一エラー形式: { code, message, details?, requestId?, timestamp }
     const unified = {
@@ -51,6 +85,7 @@ api.interceptors.response.use(
       console.warn('Authentication error detected, redirecting to login...');
       // localStorageをクリア
       localStorage.removeItem('auth_token');
+      localStorage.removeItem('refresh_token');
       localStorage.removeItem('user');
       // ログイン画面にリダイレクト（現在のページがログイン画面でない場合のみ）
       if (window.location.pathname !== '/login') {
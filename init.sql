@@ -5,6 +5,7 @@ CREATE TABLE IF NOT EXISTS projects (
     name VARCHAR(255) NOT NULL,
     description TEXT,
     language VARCHAR(50),
+    languages JSONB NOT NULL DEFAULT '[]', -- 複数言語のプロジェクト: [{"language": "go", "paths": ["backend/**"]}]
     apply_global_rules BOOLEAN DEFAULT true,
     access_level VARCHAR(20) DEFAULT 'public',
     created_by VARCHAR(100),
@@ -34,6 +35,11 @@ CREATE TABLE IF NOT EXISTS global_rules (
     pattern TEXT NOT NULL,
     message TEXT NOT NULL,
     is_active BOOLEAN DEFAULT true,
+    matcher_kind VARCHAR(30) NOT NULL DEFAULT 'regex',
+    flags VARCHAR(10) NOT NULL DEFAULT '',
+    file_glob VARCHAR(255) NOT NULL DEFAULT '',
+    match_limit INTEGER NOT NULL DEFAULT 0,
+    replacement TEXT NOT NULL DEFAULT '',
     access_level VARCHAR(20) DEFAULT 'public',
     created_by VARCHAR(100),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -52,6 +58,11 @@ CREATE TABLE IF NOT EXISTS rules (
     pattern TEXT NOT NULL,
     message TEXT NOT NULL,
     is_active BOOLEAN DEFAULT true,
+    matcher_kind VARCHAR(30) NOT NULL DEFAULT 'regex',
+    flags VARCHAR(10) NOT NULL DEFAULT '',
+    file_glob VARCHAR(255) NOT NULL DEFAULT '',
+    match_limit INTEGER NOT NULL DEFAULT 0,
+    replacement TEXT NOT NULL DEFAULT '',
     access_level VARCHAR(20) DEFAULT 'public',
     created_by VARCHAR(100),
     created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
@@ -60,6 +71,23 @@ CREATE TABLE IF NOT EXISTS rules (
     UNIQUE(project_id, rule_id)
 );
 
+-- 既存DB向け: マッチャー設定カラムの追加
+ALTER TABLE global_rules ADD COLUMN IF NOT EXISTS matcher_kind VARCHAR(30) NOT NULL DEFAULT 'regex';
+ALTER TABLE global_rules ADD COLUMN IF NOT EXISTS flags VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE global_rules ADD COLUMN IF NOT EXISTS file_glob VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE global_rules ADD COLUMN IF NOT EXISTS match_limit INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS matcher_kind VARCHAR(30) NOT NULL DEFAULT 'regex';
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS flags VARCHAR(10) NOT NULL DEFAULT '';
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS file_glob VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS match_limit INTEGER NOT NULL DEFAULT 0;
+
+-- 既存DB向け: 自動修正テンプレートカラムの追加
+ALTER TABLE global_rules ADD COLUMN IF NOT EXISTS replacement TEXT NOT NULL DEFAULT '';
+ALTER TABLE rules ADD COLUMN IF NOT EXISTS replacement TEXT NOT NULL DEFAULT '';
+
+-- 既存DB向け: 複数言語のプロジェクトの言語とパスglob
+ALTER TABLE projects ADD COLUMN IF NOT EXISTS languages JSONB NOT NULL DEFAULT '[]';
+
 -- Dynamic rule options (types / severities)
 CREATE TABLE IF NOT EXISTS rule_options (
     id SERIAL PRIMARY KEY,
@@ -96,6 +124,11 @@ CREATE TABLE IF NOT EXISTS rule_violations (
     FOREIGN KEY (rule_id) REFERENCES rules(id) ON DELETE CASCADE
 );
 
+-- 既存DB向け: ルールIDを文字列で記録（グローバルルールの違反も記録できるようにする）
+ALTER TABLE rule_violations ALTER COLUMN rule_id DROP NOT NULL;
+ALTER TABLE rule_violations ADD COLUMN IF NOT EXISTS rule_key VARCHAR(100) NOT NULL DEFAULT '';
+CREATE INDEX IF NOT EXISTS idx_rule_violations_project_created ON rule_violations(project_id, created_at);
+
 -- Create API keys table for authentication
 CREATE TABLE IF NOT EXISTS api_keys (
     id SERIAL PRIMARY KEY,
@@ -110,6 +143,20 @@ CREATE TABLE IF NOT EXISTS api_keys (
     updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 );
 
+-- 既存DB向け: APIキーの最終使用日時
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS last_used_at TIMESTAMP;
+
+-- 既存DB向け: キーの公開部分（rmcp_<公開ID>）と利用できるプロジェクト（空なら制限なし）
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS key_prefix VARCHAR(32);
+ALTER TABLE api_keys ADD COLUMN IF NOT EXISTS project_ids TEXT[] NOT NULL DEFAULT '{}';
+-- 既存DB向け: 平文で保存されていたキーはハッシュ化したうえで無効化する（再発行が必要）
+UPDATE api_keys
+SET key_hash = encode(sha256(convert_to(key_hash, 'UTF8')), 'hex'),
+    key_prefix = 'legacy_' || id,
+    is_active = false
+WHERE key_prefix IS NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_api_keys_key_prefix ON api_keys(key_prefix);
+
 -- Create users table for team management
 CREATE TABLE IF NOT EXISTS users (
     id SERIAL PRIMARY KEY,
@@ -155,12 +202,105 @@ CREATE TABLE IF NOT EXISTS project_members (
     UNIQUE(project_id, user_id)
 );
 
+-- 既存DB向け: メンバーのロールは viewer / editor / owner（従来の既定値 member は editor とする）
+ALTER TABLE project_members ALTER COLUMN role SET DEFAULT 'viewer';
+UPDATE project_members SET role = 'editor' WHERE role = 'member';
+-- 既存DB向け: プロジェクトの公開範囲は public / internal / private（従来のロール名は近い範囲に、不明な値は private に寄せる）
+UPDATE projects
+SET access_level = CASE
+    WHEN access_level IS NULL THEN 'public'
+    WHEN access_level = 'user' THEN 'internal'
+    ELSE 'private'
+END
+WHERE access_level IS NULL OR access_level NOT IN ('public', 'internal', 'private');
+
+-- リフレッシュトークン（保存するのは SHA-256 のハッシュのみ。family_id はローテーションで引き継ぐセッションの識別子）
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    token_hash CHAR(64) UNIQUE NOT NULL,
+    family_id VARCHAR(64) NOT NULL,
+    access_jti VARCHAR(64) NOT NULL,
+    access_expires_at TIMESTAMP NOT NULL,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_access_jti ON refresh_tokens(access_jti);
+
+-- 失効させたアクセストークンの拒否リスト（jti 単位。期限を過ぎた行は削除してよい）
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP NOT NULL,
+    revoked_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- プロジェクト単位のグローバルルールの上書き（disabled で継承を止め、空でない severity / message で置き換える）
+CREATE TABLE IF NOT EXISTS global_rule_overrides (
+    id SERIAL PRIMARY KEY,
+    project_id VARCHAR(100) NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    language VARCHAR(50) NOT NULL,
+    rule_id VARCHAR(100) NOT NULL,
+    disabled BOOLEAN NOT NULL DEFAULT false,
+    severity VARCHAR(20) NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (language, rule_id) REFERENCES global_rules(language, rule_id) ON DELETE CASCADE,
+    UNIQUE(project_id, language, rule_id)
+);
+
+-- ルールパック（複数のプロジェクトに適用できる名前付きのルール集）
+CREATE TABLE IF NOT EXISTS rule_packs (
+    id SERIAL PRIMARY KEY,
+    name VARCHAR(100) UNIQUE NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+);
+
+-- パックのルール（language が空なら全言語、position はパック内の並び順）
+CREATE TABLE IF NOT EXISTS rule_pack_rules (
+    id SERIAL PRIMARY KEY,
+    pack_name VARCHAR(100) NOT NULL REFERENCES rule_packs(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    position INTEGER NOT NULL DEFAULT 0,
+    rule_id VARCHAR(100) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    type VARCHAR(50) NOT NULL DEFAULT '',
+    severity VARCHAR(20) NOT NULL DEFAULT '',
+    pattern TEXT NOT NULL DEFAULT '',
+    message TEXT NOT NULL DEFAULT '',
+    is_active BOOLEAN NOT NULL DEFAULT true,
+    language VARCHAR(50) NOT NULL DEFAULT '',
+    matcher_kind VARCHAR(30) NOT NULL DEFAULT 'regex',
+    flags VARCHAR(10) NOT NULL DEFAULT '',
+    file_glob VARCHAR(255) NOT NULL DEFAULT '',
+    match_limit INTEGER NOT NULL DEFAULT 0,
+    replacement TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(pack_name, rule_id)
+);
+
+-- プロジェクトへのパックの適用（priority が大きいパックほど優先）
+CREATE TABLE IF NOT EXISTS project_rule_packs (
+    id SERIAL PRIMARY KEY,
+    project_id VARCHAR(100) NOT NULL REFERENCES projects(project_id) ON DELETE CASCADE,
+    pack_name VARCHAR(100) NOT NULL REFERENCES rule_packs(name) ON DELETE CASCADE ON UPDATE CASCADE,
+    priority INTEGER NOT NULL DEFAULT 0,
+    created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE(project_id, pack_name)
+);
+CREATE INDEX IF NOT EXISTS idx_project_rule_packs_pack_name ON project_rule_packs(pack_name);
+
 -- Insert sample data
 INSERT INTO projects (project_id, name, description, language, apply_global_rules, access_level, created_by) VALUES
     ('default', 'Default Project', 'Default project with common rules', 'general', true, 'public', 'system'),
     ('web-app', 'Web Application', 'Web application specific rules', 'javascript', true, 'public', 'system'),
     ('api-service', 'API Service', 'API service specific rules', 'go', true, 'public', 'system'),
-    ('team-project', 'Team Project', 'Team collaboration project', 'typescript', true, 'user', 'admin')
+    ('team-project', 'Team Project', 'Team collaboration project', 'typescript', true, 'internal', 'admin')
 ON CONFLICT (project_id) DO NOTHING;
 
 -- Insert initial admin account (default password: admin123)
@@ -170,12 +310,7 @@ ON CONFLICT (username) DO NOTHING;
 
 -- Sample users removed - only admin account is created by default
 
--- Insert sample API keys (hashed values - in production, use proper hashing)
-INSERT INTO api_keys (key_hash, name, description, access_level, created_by) VALUES
-    ('admin_key_hash_123', 'Admin API Key', 'Full access to all features', 'admin', 'admin'),
-    ('user_key_hash_456', 'User API Key', 'Standard user access', 'user', 'admin'),
-    ('public_key_hash_789', 'Public API Key', 'Public read-only access', 'public', 'admin')
-ON CONFLICT (key_hash) DO NOTHING;
+-- APIキーはハッシュのみを保存するため、サンプルは用意しない（管理画面または POST /api/v1/admin/api-keys で発行する）
 
 -- Project members will be added through the admin interface
 
@@ -187,11 +322,19 @@ INSERT INTO global_rules (language, rule_id, name, description, type, severity,
     ('javascript', 'no-inline-styles', 'No Inline Styles', 'CSS styles should be in separate stylesheets, not inline', 'style', 'warning', 'style=\"', 'Inline styles detected. Move to CSS file.', 'public', 'system'),
     ('go', 'naming-convention', 'Naming Convention', 'Functions and variables should use camelCase', 'style', 'warning', 'function_name', 'Function name should use camelCase (e.g., functionName).', 'public', 'system'),
     ('go', 'error-handling', 'Error Handling', 'All async operations must have proper error handling', 'reliability', 'warning', 'if err != nil', 'Error handling required. Check if err != nil.', 'public', 'system'),
-    ('python', 'no-print', 'No Print Statements', 'Print statements should not be in production code', 'style', 'warning', 'print(', 'Print statement detected. Use proper logging framework in production.', 'public', 'system'),
+    ('python', 'no-print', 'No Print Statements', 'Print statements should not be in production code', 'style', 'warning', 'print\(', 'Print statement detected. Use proper logging framework in production.', 'public', 'system'),
     ('python', 'type-hints', 'Type Hints Required', 'Function parameters should have type hints', 'style', 'warning', 'def ', 'Function definition without type hints detected.', 'public', 'system'),
     ('typescript', 'strict-null-checks', 'Strict Null Checks', 'Enable strict null checks for better type safety', 'quality', 'warning', 'strictNullChecks', 'Enable strict null checks in tsconfig.json', 'user', 'admin')
 ON CONFLICT (language, rule_id) DO NOTHING;
 
+-- Go: 構文木ベースのルール（コメントや文字列リテラル内では検出しない）
+INSERT INTO global_rules (language, rule_id, name, description, type, severity, pattern, message, matcher_kind, access_level, created_by) VALUES
+    ('go', 'no-fmt-println', 'No fmt.Println', 'fmt.Println should not be used for logging in production code', 'style', 'warning', 'call:fmt.Println', 'fmt.Println detected. Use a structured logger instead.', 'go_ast', 'public', 'system'),
+    ('go', 'no-panic', 'No Panic', 'Return errors instead of calling panic', 'reliability', 'warning', 'call:panic', 'panic detected. Return an error instead.', 'go_ast', 'public', 'system'),
+    ('go', 'exported-func-doc', 'Exported Function Doc', 'Exported functions and methods must have doc comments', 'style', 'info', 'doc:exported', 'Exported function without doc comment.', 'go_ast', 'public', 'system'),
+    ('go', 'no-init-func', 'No init()', 'Avoid init functions; initialize explicitly', 'quality', 'info', 'func:init', 'init() detected. Initialize explicitly instead.', 'go_ast', 'public', 'system')
+ON CONFLICT (language, rule_id) DO NOTHING;
+
 INSERT INTO languages (code, name, description, icon, color) VALUES
     ('javascript', 'JavaScript', 'JavaScript programming language', 'js', '#f7df1e'),
     ('typescript', 'TypeScript', 'TypeScript programming language', 'ts', '#3178c6'),
@@ -214,7 +357,7 @@ INSERT INTO rules (project_id, rule_id, name, description, type, severity, patte
     ('web-app', 'no-console-log', 'No Console Log', 'Console.log statements should not be in production code', 'style', 'warning', 'console.log', 'Console.log detected. Use proper logging framework in production.', 'public', 'system'),
     ('web-app', 'no-inline-styles', 'No Inline Styles', 'CSS styles should be in separate stylesheets, not inline', 'style', 'warning', 'style="', 'Inline styles detected. Move to CSS file.', 'public', 'system'),
     ('api-service', 'input-validation', 'Input Validation', 'All API inputs must be validated', 'security', 'error', 'req.body', 'Direct access to req.body without validation detected.', 'public', 'system'),
-    ('api-service', 'error-handling', 'Error Handling', 'All async operations must have proper error handling', 'reliability', 'warning', 'catch (', 'Async operation without proper error handling detected.', 'public', 'system'),
+    ('api-service', 'error-handling', 'Error Handling', 'All async operations must have proper error handling', 'reliability', 'warning', 'catch \(', 'Async operation without proper error handling detected.', 'public', 'system'),
     ('team-project', 'code-review-required', 'Code Review Required', 'All code changes must go through code review', 'process', 'error', 'TODO:', 'Code review required before merging', 'user', 'admin')
 ON CONFLICT (project_id, rule_id) DO NOTHING;
 
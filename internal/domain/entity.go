@@ -3,15 +3,76 @@ package domain
 import "time"
 
 type Project struct {
-	ProjectID        string    `json:"project_id"`
-	Name             string    `json:"name"`
-	Description      string    `json:"description"`
-	Language         string    `json:"language"`
-	ApplyGlobalRules bool      `json:"apply_global_rules"`
-	AccessLevel      string    `json:"access_level"`
-	CreatedBy        string    `json:"created_by"`
-	CreatedAt        time.Time `json:"created_at"`
-	UpdatedAt        time.Time `json:"updated_at"`
+	ProjectID        string            `json:"project_id"`
+	Name             string            `json:"name"`
+	Description      string            `json:"description"`
+	Language         string            `json:"language"`
+	Languages        []ProjectLanguage `json:"languages,omitempty"` // 複数言語のプロジェクトで主言語以外も含めて扱う言語
+	ApplyGlobalRules bool              `json:"apply_global_rules"`
+	AccessLevel      string            `json:"access_level"`
+	CreatedBy        string            `json:"created_by"`
+	CreatedAt        time.Time         `json:"created_at"`
+	UpdatedAt        time.Time         `json:"updated_at"`
+}
+
+// ProjectLanguage プロジェクト内の言語と、その言語のファイルを示すパスglob
+// Paths に一致しないファイルは拡張子から言語を判定する
+type ProjectLanguage struct {
+	Language string   `json:"language"`
+	Paths    []string `json:"paths,omitempty"`
+}
+
+// LanguageCodes プロジェクトで扱う言語（主言語 Language を先頭に、重複を除く）
+func (p *Project) LanguageCodes() []string {
+	codes := make([]string, 0, len(p.Languages)+1)
+	seen := make(map[string]bool, len(p.Languages)+1)
+	add := func(code string) {
+		if code != "" && !seen[code] {
+			seen[code] = true
+			codes = append(codes, code)
+		}
+	}
+	add(p.Language)
+	for _, l := range p.Languages {
+		add(l.Language)
+	}
+	return codes
+}
+
+// HasLanguage プロジェクトが指定言語を扱うか判定
+func (p *Project) HasLanguage(language string) bool {
+	for _, code := range p.LanguageCodes() {
+		if code == language {
+			return true
+		}
+	}
+	return false
+}
+
+// ルールのマッチャー種別
+const (
+	MatcherRegex         = "regex"           // Patternを正規表現として検索（Flags適用）
+	MatcherLiteral       = "literal"         // Patternを部分文字列として検索
+	MatcherRequired      = "required"        // Patternに一致する箇所が存在しなければ違反
+	MatcherMaxLineLength = "max_line_length" // Limit文字を超える行を違反とする
+	MatcherMaxFileLength = "max_file_length" // Limit行を超えるファイルを違反とする
+	MatcherGoAST         = "go_ast"          // Goの構文木に対するチェック（Patternにチェック内容を指定）
+)
+
+// RuleMatcher ルールの評価方法（RuleとGlobalRuleに埋め込まれる）
+type RuleMatcher struct {
+	MatcherKind string `json:"matcher_kind,omitempty"` // 空の場合は regex
+	Flags       string `json:"flags,omitempty"`        // 正規表現フラグ（i, m, s, U）
+	FileGlob    string `json:"file_glob,omitempty"`    // 対象ファイルのglob（空なら全ファイル）
+	Limit       int    `json:"limit,omitempty"`        // max_line_length / max_file_length の上限値
+}
+
+// Kind マッチャー種別（未設定時は regex）
+func (m RuleMatcher) Kind() string {
+	if m.MatcherKind == "" {
+		return MatcherRegex
+	}
+	return m.MatcherKind
 }
 
 type Rule struct {
@@ -24,7 +85,13 @@ type Rule struct {
 	Severity    string `json:"severity"`
 	Pattern     string `json:"pattern"`
 	Message     string `json:"message"`
+	Replacement string `json:"replacement,omitempty"` // 自動修正のテンプレート（$1, ${name} でキャプチャを参照）
 	IsActive    bool   `json:"is_active"`
+	Language    string `json:"language,omitempty"` // グローバルルール由来の場合の対象言語
+	Pack        string `json:"pack,omitempty"`     // ルールパック由来の場合のパック名
+	// UpdatedAt 最終更新日時（リポジトリが保持していない場合はnil）
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	RuleMatcher
 }
 
 type GlobalRule struct {
@@ -37,18 +104,105 @@ type GlobalRule struct {
 	Severity    string `json:"severity"`
 	Pattern     string `json:"pattern"`
 	Message     string `json:"message"`
+	Replacement string `json:"replacement,omitempty"`
 	IsActive    bool   `json:"is_active"`
+	// UpdatedAt 最終更新日時（リポジトリが保持していない場合はnil）
+	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	RuleMatcher
+}
+
+// AsRule グローバルルールをプロジェクトルール形式に変換
+func (g GlobalRule) AsRule(projectID string) Rule {
+	return Rule{
+		ProjectID:   projectID,
+		RuleID:      g.RuleID,
+		Name:        g.Name,
+		Description: g.Description,
+		Type:        g.Type,
+		Severity:    g.Severity,
+		Pattern:     g.Pattern,
+		Message:     g.Message,
+		Replacement: g.Replacement,
+		IsActive:    g.IsActive,
+		Language:    g.Language,
+		UpdatedAt:   g.UpdatedAt,
+		RuleMatcher: g.RuleMatcher,
+	}
 }
 
 type ValidationResult struct {
-	Valid    bool     `json:"valid"`
-	Errors   []string `json:"errors"`
-	Warnings []string `json:"warnings"`
+	Valid    bool              `json:"valid"`
+	Errors   []string          `json:"errors"`
+	Warnings []string          `json:"warnings"`
+	Issues   []ValidationIssue `json:"issues"`
+	// Suppressed 抑制ディレクティブにより除外された問題（Valid の判定には含めない）
+	Suppressed []SuppressedIssue `json:"suppressed"`
+}
+
+// FixResult 自動修正の結果
+type FixResult struct {
+	FixedCode string            `json:"fixed_code"`
+	Diff      string            `json:"diff"`
+	Applied   []ValidationIssue `json:"applied"`
+	Skipped   []ValidationIssue `json:"skipped"` // 他の修正と範囲が重なったため適用しなかった修正
+	Remaining []ValidationIssue `json:"remaining_issues"`
+}
+
+// FileInput 検証対象のファイル（Languageが空の場合は拡張子から推定）
+type FileInput struct {
+	Path     string `json:"path"`
+	Content  string `json:"content"`
+	Language string `json:"language,omitempty"`
+}
+
+// FileValidationResult ファイル単位の検証結果
+type FileValidationResult struct {
+	Path     string `json:"path"`
+	Language string `json:"language,omitempty"`
+	ValidationResult
+}
+
+// FilesValidationResult 複数ファイルの検証結果
+type FilesValidationResult struct {
+	Valid      bool                   `json:"valid"`
+	IssueCount int                    `json:"issue_count"`
+	Files      []FileValidationResult `json:"files"`
+}
+
+// RuleStats 有効なルールの件数（重要度別・種類別）
+type RuleStats struct {
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"`
+	ByType     map[string]int `json:"by_type"`
+}
+
+// ViolationStats 期間内に検出した違反の件数（重要度別）
+type ViolationStats struct {
+	Since      time.Time      `json:"since"`
+	Total      int            `json:"total"`
+	BySeverity map[string]int `json:"by_severity"`
+}
+
+// ProjectInfo プロジェクトの概要（エージェントがルール一覧を取得する前の判断材料）
+type ProjectInfo struct {
+	Project *Project `json:"project"`
+	// Language 言語のメタデータ（言語マスタに無い場合はnil）
+	Language       *Language `json:"language,omitempty"`
+	ProjectRules   RuleStats `json:"project_rules"`
+	InheritedRules RuleStats `json:"inherited_rules"` // 継承しているグローバルルール
+	// LastRuleUpdate プロジェクトルール・継承ルールの最終更新日時
+	LastRuleUpdate *time.Time `json:"last_rule_update,omitempty"`
+	// RecentViolations 直近の違反件数（違反を記録していない場合はnil）
+	RecentViolations *ViolationStats `json:"recent_violations,omitempty"`
 }
 
 type ProjectRules struct {
 	ProjectID string `json:"project_id"`
 	Rules     []Rule `json:"rules"`
+	// Overrides 継承したグローバルルールに適用した上書き
+	Overrides []AppliedOverride `json:"overrides,omitempty"`
+	// Packs 適用したルールパック（優先度の高い順）
+	Packs []ProjectRulePack `json:"packs,omitempty"`
 }
 
 type Language struct {
@@ -5,24 +5,84 @@ import (
 	"time"
 )
 
-// MCPRequest 標準的なMCPリクエストを表す
+// JSONRPCVersion MCPが使用するJSON-RPCのバージョン
+const JSONRPCVersion = "2.0"
+
+// MCPRequest JSON-RPC 2.0 形式のMCPリクエストを表す
+// IDは文字列・数値・nullのいずれかで、省略された場合は通知として扱う
 type MCPRequest struct {
-	ID     string          `json:"id"`
-	Method string          `json:"method"`
-	Params json.RawMessage `json:"params,omitempty"`
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// IsNotification 応答を返さない通知リクエストか判定
+func (r MCPRequest) IsNotification() bool {
+	return len(r.ID) == 0
 }
 
-// MCPResponse 標準的なMCPレスポンスを表す
+// MCPResponse JSON-RPC 2.0 形式のMCPレスポンスを表す
 type MCPResponse struct {
-	ID     string          `json:"id"`
-	Result json.RawMessage `json:"result,omitempty"`
-	Error  *MCPError       `json:"error,omitempty"`
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id"`
+	Result  json.RawMessage `json:"result,omitempty"`
+	Error   *MCPError       `json:"error,omitempty"`
 }
 
 // MCPError MCPエラーを表す
 type MCPError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
+	Data    interface{} `json:"data,omitempty"`
+}
+
+// MCPInitializeRequest initializeリクエストのパラメータを表す
+type MCPInitializeRequest struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities,omitempty"`
+	ClientInfo      MCPImplementation      `json:"clientInfo"`
+}
+
+// MCPInitializeResult initializeのレスポンスを表す
+type MCPInitializeResult struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ServerInfo      MCPImplementation      `json:"serverInfo"`
+	Instructions    string                 `json:"instructions,omitempty"`
+}
+
+// MCPImplementation クライアント・サーバーの実装情報を表す
+type MCPImplementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// MCPTool tools/listで公開するツールの定義を表す
+type MCPTool struct {
+	Name        string                 `json:"name"`
+	Description string                 `json:"description"`
+	InputSchema map[string]interface{} `json:"inputSchema"`
+}
+
+// MCPToolCallRequest tools/callのパラメータを表す
+type MCPToolCallRequest struct {
+	Name      string          `json:"name"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// MCPToolCallResult tools/callのレスポンスを表す
+// ツールの実行エラーはJSON-RPCエラーではなくIsErrorで返す
+type MCPToolCallResult struct {
+	Content           []MCPContent `json:"content"`
+	StructuredContent interface{}  `json:"structuredContent,omitempty"`
+	IsError           bool         `json:"isError,omitempty"`
+}
+
+// MCPContent ツール結果のコンテンツを表す
+type MCPContent struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
 }
 
 // MCPRuleRequest ルールリクエストを表す
@@ -38,6 +98,10 @@ type MCPRuleResponse struct {
 	Rules        []Rule       `json:"rules"`
 	GlobalRules  []GlobalRule `json:"global_rules,omitempty"`
 	AppliedRules []Rule       `json:"applied_rules"`
+	// Overrides プロジェクトで上書きしたグローバルルール
+	Overrides []AppliedOverride `json:"overrides,omitempty"`
+	// Packs 適用したルールパック（優先度の高い順）
+	Packs []ProjectRulePack `json:"packs,omitempty"`
 }
 
 // MCPValidationRequest コード検証リクエストを表す
@@ -45,35 +109,74 @@ type MCPValidationRequest struct {
 	ProjectID string `json:"project_id"`
 	Code      string `json:"code"`
 	Language  string `json:"language,omitempty"`
+	Filename  string `json:"filename,omitempty"`
+}
+
+// MCPValidateFilesRequest 複数ファイル検証リクエストを表す
+type MCPValidateFilesRequest struct {
+	ProjectID string      `json:"project_id"`
+	Files     []FileInput `json:"files"`
+}
+
+// MCPValidateDiffRequest 差分検証リクエストを表す
+type MCPValidateDiffRequest struct {
+	ProjectID string `json:"project_id"`
+	Diff      string `json:"diff"` // git diff 形式のunified diff
+}
+
+// MCPFixRequest 自動修正リクエストを表す
+type MCPFixRequest struct {
+	ProjectID string   `json:"project_id"`
+	Code      string   `json:"code"`
+	Filename  string   `json:"filename,omitempty"`
+	RuleIDs   []string `json:"rule_ids,omitempty"` // 指定時はこれらのルールの修正のみ適用
 }
 
 // MCPValidationResponse コード検証レスポンスを表す
 type MCPValidationResponse struct {
-	IsValid bool              `json:"is_valid"`
-	Issues  []ValidationIssue `json:"issues,omitempty"`
-	Rules   []Rule            `json:"applied_rules"`
+	IsValid    bool              `json:"is_valid"`
+	Issues     []ValidationIssue `json:"issues,omitempty"`
+	Suppressed []SuppressedIssue `json:"suppressed,omitempty"`
+	Rules      []Rule            `json:"applied_rules"`
 }
 
 // ValidationIssue コードで発見された検証問題を表す
+// 行・列は1始まりで、ColumnEndはマッチ末尾の次の列を指す
 type ValidationIssue struct {
-	RuleID      string `json:"rule_id"`
-	RuleName    string `json:"rule_name"`
-	Severity    string `json:"severity"`
-	Message     string `json:"message"`
-	LineNumber  int    `json:"line_number,omitempty"`
-	ColumnStart int    `json:"column_start,omitempty"`
-	ColumnEnd   int    `json:"column_end,omitempty"`
+	RuleID        string `json:"rule_id"`
+	RuleName      string `json:"rule_name"`
+	Severity      string `json:"severity"`
+	Message       string `json:"message"`
+	LineNumber    int    `json:"line_number,omitempty"`
+	ColumnStart   int    `json:"column_start,omitempty"`
+	EndLineNumber int    `json:"end_line_number,omitempty"`
+	ColumnEnd     int    `json:"column_end,omitempty"`
+	Snippet       string `json:"snippet,omitempty"`
+	Replacement   string `json:"replacement,omitempty"` // 自動修正できる場合の置換後テキスト
+}
+
+// SuppressedIssue 抑制ディレクティブにより除外された検証問題を表す
+type SuppressedIssue struct {
+	ValidationIssue
+	Directive     string `json:"directive"`      // rule-mcp-disable-next-line / rule-mcp-disable-line / rule-mcp-disable
+	DirectiveLine int    `json:"directive_line"` // ディレクティブが書かれた行
+	Justification string `json:"justification,omitempty"`
 }
 
 // MCPNotification サーバーからの通知を表す
 type MCPNotification struct {
-	Method string          `json:"method"`
-	Params json.RawMessage `json:"params,omitempty"`
+	JSONRPC string          `json:"jsonrpc"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
 }
 
-// MCPRuleUpdateNotification ルール更新通知を表す
+// MCPRuleUpdateNotification ルール更新通知（notifications/rules/updated）を表す
 type MCPRuleUpdateNotification struct {
 	ProjectID string    `json:"project_id"`
 	UpdatedAt time.Time `json:"updated_at"`
-	RuleCount int       `json:"rule_count"`
+	RuleCount int       `json:"rule_count"`         // 更新後にプロジェクトへ適用されるルール数
+	Language  string    `json:"language,omitempty"` // グローバルルールの変更による場合の言語
 }
+
+// MCPRuleUpdatedMethod ルール更新通知のメソッド名
+const MCPRuleUpdatedMethod = "notifications/rules/updated"
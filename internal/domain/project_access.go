@@ -0,0 +1,58 @@
+package domain
+
+import "time"
+
+// プロジェクトの公開範囲（Project.AccessLevel）
+const (
+	ProjectAccessPublic   = "public"   // 誰でも閲覧できる（未認証を含む）
+	ProjectAccessInternal = "internal" // 認証済みの利用者（APIキーを含む）が閲覧できる
+	ProjectAccessPrivate  = "private"  // メンバーと管理者のみ閲覧できる
+)
+
+// プロジェクトメンバーのロール（権限の弱い順）
+const (
+	ProjectRoleViewer = "viewer" // ルールの閲覧・コード検証
+	ProjectRoleEditor = "editor" // ルールとプロジェクト設定の編集
+	ProjectRoleOwner  = "owner"  // 公開範囲・メンバーの管理とプロジェクトの削除
+)
+
+// ValidProjectAccessLevel 公開範囲として有効な値か判定
+func ValidProjectAccessLevel(level string) bool {
+	switch level {
+	case ProjectAccessPublic, ProjectAccessInternal, ProjectAccessPrivate:
+		return true
+	}
+	return false
+}
+
+// ProjectRoleRank ロールの強さ（未知のロールは0）
+func ProjectRoleRank(role string) int {
+	switch role {
+	case ProjectRoleViewer:
+		return 1
+	case ProjectRoleEditor:
+		return 2
+	case ProjectRoleOwner:
+		return 3
+	}
+	return 0
+}
+
+// ProjectMember プロジェクトメンバー
+type ProjectMember struct {
+	ProjectID string    `json:"project_id"`
+	UserID    int       `json:"user_id"`
+	Username  string    `json:"username"`
+	Role      string    `json:"role"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ProjectMemberRepository プロジェクトメンバーリポジトリインターフェース
+type ProjectMemberRepository interface {
+	GetByProject(projectID string) ([]*ProjectMember, error)
+	GetByUser(userID int) ([]*ProjectMember, error)
+	Get(projectID string, userID int) (*ProjectMember, error)
+	// Save メンバーを追加し、既に登録済みならロールを更新する
+	Save(member *ProjectMember) error
+	Delete(projectID string, userID int) error
+}
@@ -1,5 +1,7 @@
 package domain
 
+import "time"
+
 type ProjectRepository interface {
 	Create(project *Project) error
 	GetByID(projectID string) (*Project, error)
@@ -19,8 +21,14 @@ type RuleRepository interface {
 
 type GlobalRuleRepository interface {
 	Create(rule *GlobalRule) error
+	// GetByID 無効化したルールも含めて取得
+	GetByID(language, ruleID string) (*GlobalRule, error)
+	// GetByLanguage 有効なルールのみ取得
 	GetByLanguage(language string) ([]*GlobalRule, error)
+	// GetAll 無効化したルールも含めて全言語のルールを取得（言語・ルールIDの順。エクスポートに使う）
+	GetAll() ([]*GlobalRule, error)
 	GetAllLanguages() ([]string, error)
+	Update(rule *GlobalRule) error
 	Delete(language, ruleID string) error
 }
 
@@ -32,6 +40,12 @@ type LanguageRepository interface {
 	Delete(code string) error
 }
 
+// ViolationRepository 検証で検出した違反の記録と集計
+type ViolationRepository interface {
+	Record(projectID, filePath string, issues []ValidationIssue) error
+	CountBySeverity(projectID string, since time.Time) (map[string]int, error)
+}
+
 type ValidationRepository interface {
 	ValidateCode(projectID, code string) (*ValidationResult, error)
 }
@@ -0,0 +1,36 @@
+package domain
+
+import "time"
+
+// GlobalRuleOverride プロジェクト単位でのグローバルルールの上書き
+// Disabled で継承を止め、Severity / Message が空でなければその値で置き換える
+type GlobalRuleOverride struct {
+	ProjectID string    `json:"project_id"`
+	Language  string    `json:"language"`
+	RuleID    string    `json:"rule_id"`
+	Disabled  bool      `json:"disabled"`
+	Severity  string    `json:"severity,omitempty"`
+	Message   string    `json:"message,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// AppliedOverride ルール一覧の取得時に適用した上書き（上書き前の値を併せて返す）
+type AppliedOverride struct {
+	RuleID           string `json:"rule_id"`
+	Language         string `json:"language"`
+	Disabled         bool   `json:"disabled,omitempty"`
+	Severity         string `json:"severity,omitempty"`
+	OriginalSeverity string `json:"original_severity,omitempty"`
+	Message          string `json:"message,omitempty"`
+	OriginalMessage  string `json:"original_message,omitempty"`
+}
+
+// GlobalRuleOverrideRepository グローバルルール上書きリポジトリインターフェース
+type GlobalRuleOverrideRepository interface {
+	GetByProject(projectID string) ([]*GlobalRuleOverride, error)
+	Get(projectID, language, ruleID string) (*GlobalRuleOverride, error)
+	// Save 上書きを追加し、登録済みなら内容を更新する
+	Save(override *GlobalRuleOverride) error
+	Delete(projectID, language, ruleID string) error
+}
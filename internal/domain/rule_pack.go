@@ -0,0 +1,39 @@
+package domain
+
+import "time"
+
+// RulePack 複数のプロジェクトに適用できる名前付きのルール集（security-baseline など）
+// パックのルールは Pack にパック名を持ち、Language を指定した場合はその言語のファイルにのみ適用する
+type RulePack struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Rules       []Rule    `json:"rules"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// ProjectRulePack プロジェクトへのルールパックの適用（Priority が大きいパックほど優先）
+type ProjectRulePack struct {
+	ProjectID string    `json:"project_id"`
+	Pack      string    `json:"pack"`
+	Priority  int       `json:"priority"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// RulePackRepository ルールパックリポジトリインターフェース
+type RulePackRepository interface {
+	Create(pack *RulePack) error
+	// GetByName パックをルールを含めて取得
+	GetByName(name string) (*RulePack, error)
+	GetAll() ([]*RulePack, error)
+	// Update 説明とルールを置き換える
+	Update(pack *RulePack) error
+	Delete(name string) error
+	// GetByProject プロジェクトに適用しているパック（優先度の高い順）
+	GetByProject(projectID string) ([]*ProjectRulePack, error)
+	// GetProjects パックを適用しているプロジェクトID
+	GetProjects(name string) ([]string, error)
+	// Attach パックをプロジェクトに適用し、適用済みなら優先度を更新する
+	Attach(attachment *ProjectRulePack) error
+	Detach(projectID, name string) error
+}
@@ -27,3 +27,94 @@ type UserRepository interface {
 	GetActiveUsers() ([]User, error)
 	GetUsersByRole(role string) ([]User, error)
 }
+
+// APIKey CIボットやエージェント向けのAPIキー（AccessLevel はロール名として扱う）
+// キー本体は作成時に一度だけ返し、保存するのはハッシュのみ。Prefix はキーの公開部分で一覧・検索に使う
+type APIKey struct {
+	ID          int        `json:"id"`
+	Name        string     `json:"name"`
+	Description string     `json:"description"`
+	Prefix      string     `json:"prefix"`
+	KeyHash     string     `json:"-"`
+	AccessLevel string     `json:"access_level"`
+	ProjectIDs  []string   `json:"project_ids"` // 利用できるプロジェクト（空なら制限なし）
+	IsActive    bool       `json:"is_active"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt  *time.Time `json:"last_used_at,omitempty"`
+	CreatedBy   string     `json:"created_by"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+}
+
+// Usable 有効かつ期限切れでないか判定
+func (k *APIKey) Usable(now time.Time) bool {
+	return k.IsActive && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
+
+// AllowsProject キーのスコープにプロジェクトが含まれるか判定
+func (k *APIKey) AllowsProject(projectID string) bool {
+	if len(k.ProjectIDs) == 0 {
+		return true
+	}
+	for _, id := range k.ProjectIDs {
+		if id == projectID {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyRepository APIキーリポジトリインターフェース
+type APIKeyRepository interface {
+	GetByID(id int) (*APIKey, error)
+	GetByPrefix(prefix string) (*APIKey, error)
+	// List prefix が空でなければ前方一致で絞り込む
+	List(prefix string) ([]*APIKey, error)
+	Create(key *APIKey) error
+	Update(key *APIKey) error
+	Delete(id int) error
+	UpdateLastUsed(id int, at time.Time) error
+}
+
+// RefreshToken ログインセッションのリフレッシュトークン（保存するのはハッシュのみ）
+// 使用するたびに失効させて同じ FamilyID の新しいトークンを発行する。失効済みのトークンが再び使われた場合は
+// 漏洩とみなして FamilyID ごと失効させる
+type RefreshToken struct {
+	ID        int
+	UserID    int
+	TokenHash string
+	FamilyID  string
+	// AccessJTI 同時に発行したアクセストークンのJWT ID（セッションを失効させるときに拒否リストへ追加する）
+	AccessJTI       string
+	AccessExpiresAt time.Time
+	ExpiresAt       time.Time
+	RevokedAt       *time.Time
+	CreatedAt       time.Time
+}
+
+// Usable 失効しておらず期限切れでないか判定
+func (t *RefreshToken) Usable(now time.Time) bool {
+	return t.RevokedAt == nil && now.Before(t.ExpiresAt)
+}
+
+// RefreshTokenRepository リフレッシュトークンリポジトリインターフェース
+type RefreshTokenRepository interface {
+	Create(token *RefreshToken) error
+	GetByHash(hash string) (*RefreshToken, error)
+	GetByAccessJTI(jti string) (*RefreshToken, error)
+	// ListActiveByUser・ListActiveByFamily 失効していないトークン
+	ListActiveByUser(userID int) ([]*RefreshToken, error)
+	ListActiveByFamily(familyID string) ([]*RefreshToken, error)
+	// Revoke 失効していないトークンを失効させ、失効させた場合に true を返す（同時に使われたトークンの判定に使う）
+	Revoke(id int, at time.Time) (bool, error)
+	RevokeFamily(familyID string, at time.Time) error
+	RevokeByUser(userID int, at time.Time) error
+}
+
+// TokenDenylistRepository 失効させたアクセストークン（JWT ID）の拒否リスト
+// expiresAt を過ぎたトークンは署名の検証で拒否されるため、行は削除してよい
+type TokenDenylistRepository interface {
+	Add(jti string, expiresAt time.Time) error
+	Contains(jti string) (bool, error)
+	DeleteExpired(now time.Time) error
+}
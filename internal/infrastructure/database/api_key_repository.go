@@ -0,0 +1,120 @@
+package database
+
+import (
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/lib/pq"
+)
+
+type PostgresAPIKeyRepository struct {
+	db *sql.DB
+}
+
+var _ domain.APIKeyRepository = (*PostgresAPIKeyRepository)(nil)
+
+func NewPostgresAPIKeyRepository(db *sql.DB) *PostgresAPIKeyRepository {
+	return &PostgresAPIKeyRepository{db: db}
+}
+
+const apiKeyColumns = `
+	id, name, COALESCE(description, ''), COALESCE(key_prefix, ''), key_hash, access_level,
+	COALESCE(project_ids, '{}'), is_active, expires_at, last_used_at,
+	COALESCE(created_by, ''), created_at, updated_at
+`
+
+func scanAPIKey(row interface{ Scan(...interface{}) error }) (*domain.APIKey, error) {
+	var k domain.APIKey
+	var projectIDs []string
+	err := row.Scan(
+		&k.ID, &k.Name, &k.Description, &k.Prefix, &k.KeyHash, &k.AccessLevel,
+		pq.Array(&projectIDs), &k.IsActive, &k.ExpiresAt, &k.LastUsedAt,
+		&k.CreatedBy, &k.CreatedAt, &k.UpdatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	k.ProjectIDs = projectIDs
+	return &k, nil
+}
+
+func (r *PostgresAPIKeyRepository) GetByID(id int) (*domain.APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE id = $1`, id))
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	return k, nil
+}
+
+// GetByPrefix キーの公開部分でAPIキーを検索
+func (r *PostgresAPIKeyRepository) GetByPrefix(prefix string) (*domain.APIKey, error) {
+	k, err := scanAPIKey(r.db.QueryRow(`SELECT `+apiKeyColumns+` FROM api_keys WHERE key_prefix = $1`, prefix))
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	return k, nil
+}
+
+func (r *PostgresAPIKeyRepository) List(prefix string) ([]*domain.APIKey, error) {
+	query := `SELECT ` + apiKeyColumns + ` FROM api_keys`
+	var args []interface{}
+	if prefix != "" {
+		// LIKE の特殊文字はエスケープして前方一致にする
+		escaped := strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`).Replace(prefix)
+		query += ` WHERE key_prefix LIKE $1`
+		args = append(args, escaped+"%")
+	}
+	query += ` ORDER BY created_at DESC LIMIT 100`
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	defer rows.Close()
+
+	var keys []*domain.APIKey
+	for rows.Next() {
+		k, err := scanAPIKey(rows)
+		if err != nil {
+			return nil, err
+		}
+		keys = append(keys, k)
+	}
+	return keys, rows.Err()
+}
+
+func (r *PostgresAPIKeyRepository) Create(k *domain.APIKey) error {
+	query := `
+		INSERT INTO api_keys (key_prefix, key_hash, name, description, access_level, project_ids, is_active, expires_at, created_by, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id
+	`
+	err := r.db.QueryRow(query,
+		k.Prefix, k.KeyHash, k.Name, k.Description, k.AccessLevel, pq.Array(k.ProjectIDs),
+		k.IsActive, k.ExpiresAt, k.CreatedBy, k.CreatedAt, k.UpdatedAt,
+	).Scan(&k.ID)
+	return mapDBError(err)
+}
+
+// Update キーのハッシュと Prefix は変更しない
+func (r *PostgresAPIKeyRepository) Update(k *domain.APIKey) error {
+	query := `
+		UPDATE api_keys
+		SET name = $2, description = $3, access_level = $4, project_ids = $5, is_active = $6, expires_at = $7, updated_at = $8
+		WHERE id = $1
+	`
+	_, err := r.db.Exec(query, k.ID, k.Name, k.Description, k.AccessLevel, pq.Array(k.ProjectIDs), k.IsActive, k.ExpiresAt, k.UpdatedAt)
+	return mapDBError(err)
+}
+
+func (r *PostgresAPIKeyRepository) Delete(id int) error {
+	_, err := r.db.Exec(`DELETE FROM api_keys WHERE id = $1`, id)
+	return mapDBError(err)
+}
+
+func (r *PostgresAPIKeyRepository) UpdateLastUsed(id int, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE api_keys SET last_used_at = $2 WHERE id = $1`, id, at)
+	return mapDBError(err)
+}
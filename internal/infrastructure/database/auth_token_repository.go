@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+type PostgresRefreshTokenRepository struct {
+	db *sql.DB
+}
+
+var _ domain.RefreshTokenRepository = (*PostgresRefreshTokenRepository)(nil)
+
+func NewPostgresRefreshTokenRepository(db *sql.DB) *PostgresRefreshTokenRepository {
+	return &PostgresRefreshTokenRepository{db: db}
+}
+
+const refreshTokenColumns = `
+	id, user_id, token_hash, family_id, access_jti, access_expires_at, expires_at, revoked_at, created_at
+`
+
+func scanRefreshToken(row interface{ Scan(...interface{}) error }) (*domain.RefreshToken, error) {
+	var t domain.RefreshToken
+	err := row.Scan(&t.ID, &t.UserID, &t.TokenHash, &t.FamilyID, &t.AccessJTI, &t.AccessExpiresAt, &t.ExpiresAt, &t.RevokedAt, &t.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func (r *PostgresRefreshTokenRepository) Create(t *domain.RefreshToken) error {
+	query := `
+		INSERT INTO refresh_tokens (user_id, token_hash, family_id, access_jti, access_expires_at, expires_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING id
+	`
+	err := r.db.QueryRow(query, t.UserID, t.TokenHash, t.FamilyID, t.AccessJTI, t.AccessExpiresAt, t.ExpiresAt, t.CreatedAt).Scan(&t.ID)
+	return mapDBError(err)
+}
+
+func (r *PostgresRefreshTokenRepository) GetByHash(hash string) (*domain.RefreshToken, error) {
+	t, err := scanRefreshToken(r.db.QueryRow(`SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE token_hash = $1`, hash))
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	return t, nil
+}
+
+func (r *PostgresRefreshTokenRepository) GetByAccessJTI(jti string) (*domain.RefreshToken, error) {
+	t, err := scanRefreshToken(r.db.QueryRow(`SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE access_jti = $1`, jti))
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	return t, nil
+}
+
+func (r *PostgresRefreshTokenRepository) ListActiveByUser(userID int) ([]*domain.RefreshToken, error) {
+	return r.list(`SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE user_id = $1 AND revoked_at IS NULL`, userID)
+}
+
+func (r *PostgresRefreshTokenRepository) ListActiveByFamily(familyID string) ([]*domain.RefreshToken, error) {
+	return r.list(`SELECT `+refreshTokenColumns+` FROM refresh_tokens WHERE family_id = $1 AND revoked_at IS NULL`, familyID)
+}
+
+func (r *PostgresRefreshTokenRepository) Revoke(id int, at time.Time) (bool, error) {
+	result, err := r.db.Exec(`UPDATE refresh_tokens SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, at)
+	if err != nil {
+		return false, mapDBError(err)
+	}
+	n, err := result.RowsAffected()
+	if err != nil {
+		return false, mapDBError(err)
+	}
+	return n > 0, nil
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeFamily(familyID string, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`, familyID, at)
+	return mapDBError(err)
+}
+
+func (r *PostgresRefreshTokenRepository) RevokeByUser(userID int, at time.Time) error {
+	_, err := r.db.Exec(`UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`, userID, at)
+	return mapDBError(err)
+}
+
+func (r *PostgresRefreshTokenRepository) list(query string, args ...interface{}) ([]*domain.RefreshToken, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	defer rows.Close()
+
+	var tokens []*domain.RefreshToken
+	for rows.Next() {
+		t, err := scanRefreshToken(rows)
+		if err != nil {
+			return nil, mapDBError(err)
+		}
+		tokens = append(tokens, t)
+	}
+	return tokens, rows.Err()
+}
+
+type PostgresTokenDenylistRepository struct {
+	db *sql.DB
+}
+
+var _ domain.TokenDenylistRepository = (*PostgresTokenDenylistRepository)(nil)
+
+func NewPostgresTokenDenylistRepository(db *sql.DB) *PostgresTokenDenylistRepository {
+	return &PostgresTokenDenylistRepository{db: db}
+}
+
+func (r *PostgresTokenDenylistRepository) Add(jti string, expiresAt time.Time) error {
+	_, err := r.db.Exec(`INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`, jti, expiresAt)
+	return mapDBError(err)
+}
+
+func (r *PostgresTokenDenylistRepository) Contains(jti string) (bool, error) {
+	var exists bool
+	err := r.db.QueryRow(`SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1)`, jti).Scan(&exists)
+	return exists, mapDBError(err)
+}
+
+func (r *PostgresTokenDenylistRepository) DeleteExpired(now time.Time) error {
+	_, err := r.db.Exec(`DELETE FROM revoked_tokens WHERE expires_at < $1`, now)
+	return mapDBError(err)
+}
@@ -0,0 +1,72 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+type PostgresGlobalRuleOverrideRepository struct {
+	db *sql.DB
+}
+
+var _ domain.GlobalRuleOverrideRepository = (*PostgresGlobalRuleOverrideRepository)(nil)
+
+func NewPostgresGlobalRuleOverrideRepository(db *sql.DB) *PostgresGlobalRuleOverrideRepository {
+	return &PostgresGlobalRuleOverrideRepository{db: db}
+}
+
+const globalRuleOverrideQuery = `
+	SELECT project_id, language, rule_id, disabled, severity, message, created_at, updated_at
+	FROM global_rule_overrides
+`
+
+func scanGlobalRuleOverride(row interface{ Scan(...interface{}) error }) (*domain.GlobalRuleOverride, error) {
+	var o domain.GlobalRuleOverride
+	if err := row.Scan(&o.ProjectID, &o.Language, &o.RuleID, &o.Disabled, &o.Severity, &o.Message, &o.CreatedAt, &o.UpdatedAt); err != nil {
+		return nil, err
+	}
+	return &o, nil
+}
+
+func (r *PostgresGlobalRuleOverrideRepository) GetByProject(projectID string) ([]*domain.GlobalRuleOverride, error) {
+	rows, err := r.db.Query(globalRuleOverrideQuery+` WHERE project_id = $1 ORDER BY language, rule_id`, projectID)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	defer rows.Close()
+
+	overrides := []*domain.GlobalRuleOverride{}
+	for rows.Next() {
+		o, err := scanGlobalRuleOverride(rows)
+		if err != nil {
+			return nil, mapDBError(err)
+		}
+		overrides = append(overrides, o)
+	}
+	return overrides, rows.Err()
+}
+
+func (r *PostgresGlobalRuleOverrideRepository) Get(projectID, language, ruleID string) (*domain.GlobalRuleOverride, error) {
+	o, err := scanGlobalRuleOverride(r.db.QueryRow(globalRuleOverrideQuery+` WHERE project_id = $1 AND language = $2 AND rule_id = $3`, projectID, language, ruleID))
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	return o, nil
+}
+
+func (r *PostgresGlobalRuleOverrideRepository) Save(o *domain.GlobalRuleOverride) error {
+	query := `
+		INSERT INTO global_rule_overrides (project_id, language, rule_id, disabled, severity, message, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		ON CONFLICT (project_id, language, rule_id) DO UPDATE SET
+			disabled = EXCLUDED.disabled, severity = EXCLUDED.severity, message = EXCLUDED.message, updated_at = EXCLUDED.updated_at
+	`
+	_, err := r.db.Exec(query, o.ProjectID, o.Language, o.RuleID, o.Disabled, o.Severity, o.Message, o.CreatedAt, o.UpdatedAt)
+	return mapDBError(err)
+}
+
+func (r *PostgresGlobalRuleOverrideRepository) Delete(projectID, language, ruleID string) error {
+	_, err := r.db.Exec(`DELETE FROM global_rule_overrides WHERE project_id = $1 AND language = $2 AND rule_id = $3`, projectID, language, ruleID)
+	return mapDBError(err)
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
+	"strings"
 	"time"
 
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
@@ -36,6 +37,10 @@ type PostgresMetricsRepository struct {
 	DB *sql.DB
 }
 
+type PostgresViolationRepository struct {
+	DB *sql.DB
+}
+
 // Ensure implementations
 var _ domain.ProjectRepository = (*PostgresDatabase)(nil)
 var _ domain.RuleRepository = (*PostgresRuleRepository)(nil)
@@ -43,6 +48,7 @@ var _ domain.GlobalRuleRepository = (*PostgresGlobalRuleRepository)(nil)
 var _ domain.RuleOptionRepository = (*PostgresRuleOptionRepository)(nil)
 var _ domain.RoleRepository = (*PostgresRoleRepository)(nil)
 var _ domain.MetricsRepository = (*PostgresMetricsRepository)(nil)
+var _ domain.ViolationRepository = (*PostgresViolationRepository)(nil)
 
 func NewPostgresDatabase(host, port, user, password, dbname string) (*PostgresDatabase, error) {
 	psqlInfo := fmt.Sprintf("host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
@@ -81,34 +87,41 @@ func NewPostgresMetricsRepository(db *sql.DB) *PostgresMetricsRepository {
 	return &PostgresMetricsRepository{DB: db}
 }
 
+func NewPostgresViolationRepository(db *sql.DB) *PostgresViolationRepository {
+	return &PostgresViolationRepository{DB: db}
+}
+
 func (d *PostgresDatabase) Close() error {
 	return d.DB.Close()
 }
 
 func (d *PostgresDatabase) Create(project *domain.Project) error {
-	query := `INSERT INTO projects (project_id, name, description, language, apply_global_rules, created_at, updated_at) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7)`
-	_, err := d.DB.Exec(query, project.ProjectID, project.Name, project.Description, project.Language, project.ApplyGlobalRules, project.CreatedAt, project.UpdatedAt)
+	query := `INSERT INTO projects (project_id, name, description, language, languages, apply_global_rules, access_level, created_by, created_at, updated_at) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)`
+	_, err := d.DB.Exec(query, project.ProjectID, project.Name, project.Description, project.Language, encodeProjectLanguages(project.Languages),
+		project.ApplyGlobalRules, project.AccessLevel, project.CreatedBy, project.CreatedAt, project.UpdatedAt)
 	return mapDBError(err)
 }
 
 func (d *PostgresDatabase) GetByID(projectID string) (*domain.Project, error) {
-	query := `SELECT project_id, name, description, language, apply_global_rules, created_at, updated_at 
+	query := `SELECT project_id, name, description, language, COALESCE(languages, '[]'), apply_global_rules, COALESCE(access_level, 'public'), COALESCE(created_by, ''), created_at, updated_at 
 			  FROM projects WHERE project_id = $1`
 
 	var project domain.Project
+	var languages []byte
 	err := d.DB.QueryRow(query, projectID).Scan(
-		&project.ProjectID, &project.Name, &project.Description, &project.Language,
-		&project.ApplyGlobalRules, &project.CreatedAt, &project.UpdatedAt)
+		&project.ProjectID, &project.Name, &project.Description, &project.Language, &languages,
+		&project.ApplyGlobalRules, &project.AccessLevel, &project.CreatedBy, &project.CreatedAt, &project.UpdatedAt)
 
 	if err != nil {
 		return nil, mapDBError(err)
 	}
+	project.Languages = decodeProjectLanguages(languages)
 	return &project, nil
 }
 
 func (d *PostgresDatabase) GetAll() ([]*domain.Project, error) {
-	query := `SELECT project_id, name, description, language, apply_global_rules, created_at, updated_at 
+	query := `SELECT project_id, name, description, language, COALESCE(languages, '[]'), apply_global_rules, COALESCE(access_level, 'public'), COALESCE(created_by, ''), created_at, updated_at 
 			  FROM projects ORDER BY created_at DESC`
 
 	rows, err := d.DB.Query(query)
@@ -120,12 +133,14 @@ func (d *PostgresDatabase) GetAll() ([]*domain.Project, error) {
 	var projects []*domain.Project
 	for rows.Next() {
 		var project domain.Project
+		var languages []byte
 		err := rows.Scan(
-			&project.ProjectID, &project.Name, &project.Description, &project.Language,
-			&project.ApplyGlobalRules, &project.CreatedAt, &project.UpdatedAt)
+			&project.ProjectID, &project.Name, &project.Description, &project.Language, &languages,
+			&project.ApplyGlobalRules, &project.AccessLevel, &project.CreatedBy, &project.CreatedAt, &project.UpdatedAt)
 		if err != nil {
 			return nil, mapDBError(err)
 		}
+		project.Languages = decodeProjectLanguages(languages)
 		projects = append(projects, &project)
 	}
 
@@ -133,12 +148,34 @@ func (d *PostgresDatabase) GetAll() ([]*domain.Project, error) {
 }
 
 func (d *PostgresDatabase) Update(project *domain.Project) error {
-	query := `UPDATE projects SET name = $2, description = $3, language = $4, apply_global_rules = $5, updated_at = $6 
+	query := `UPDATE projects SET name = $2, description = $3, language = $4, languages = $5, apply_global_rules = $6, access_level = $7, updated_at = $8 
 			  WHERE project_id = $1`
-	_, err := d.DB.Exec(query, project.ProjectID, project.Name, project.Description, project.Language, project.ApplyGlobalRules, project.UpdatedAt)
+	_, err := d.DB.Exec(query, project.ProjectID, project.Name, project.Description, project.Language, encodeProjectLanguages(project.Languages),
+		project.ApplyGlobalRules, project.AccessLevel, project.UpdatedAt)
 	return mapDBError(err)
 }
 
+// encodeProjectLanguages 言語とパスglobの対応をJSONB列の値に変換
+func encodeProjectLanguages(languages []domain.ProjectLanguage) string {
+	if len(languages) == 0 {
+		return "[]"
+	}
+	raw, _ := json.Marshal(languages)
+	return string(raw)
+}
+
+// decodeProjectLanguages JSONB列の値を言語とパスglobの対応に変換（空なら nil）
+func decodeProjectLanguages(raw []byte) []domain.ProjectLanguage {
+	var languages []domain.ProjectLanguage
+	if len(raw) > 0 {
+		_ = json.Unmarshal(raw, &languages)
+	}
+	if len(languages) == 0 {
+		return nil
+	}
+	return languages
+}
+
 func (d *PostgresDatabase) Delete(projectID string) error {
 	query := `DELETE FROM projects WHERE project_id = $1`
 	_, err := d.DB.Exec(query, projectID)
@@ -147,14 +184,16 @@ func (d *PostgresDatabase) Delete(projectID string) error {
 
 // RuleRepository implementation
 func (d *PostgresRuleRepository) Create(rule *domain.Rule) error {
-	query := `INSERT INTO rules (project_id, rule_id, name, description, type, severity, pattern, message, is_active) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := d.DB.Exec(query, rule.ProjectID, rule.RuleID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.IsActive)
+	query := `INSERT INTO rules (project_id, rule_id, name, description, type, severity, pattern, message, is_active, matcher_kind, flags, file_glob, match_limit, replacement) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	_, err := d.DB.Exec(query, rule.ProjectID, rule.RuleID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.IsActive,
+		rule.Kind(), rule.Flags, rule.FileGlob, rule.Limit, rule.Replacement)
 	return mapDBError(err)
 }
 
 func (d *PostgresRuleRepository) GetByProjectID(projectID string) ([]*domain.Rule, error) {
-	query := `SELECT id, project_id, rule_id, name, description, type, severity, pattern, message, is_active 
+	query := `SELECT id, project_id, rule_id, name, description, type, severity, pattern, message, is_active,
+			  matcher_kind, flags, file_glob, match_limit, replacement, updated_at
 			  FROM rules WHERE project_id = $1 AND is_active = true ORDER BY severity DESC, name ASC`
 
 	rows, err := d.DB.Query(query, projectID)
@@ -168,7 +207,8 @@ func (d *PostgresRuleRepository) GetByProjectID(projectID string) ([]*domain.Rul
 		var rule domain.Rule
 		err := rows.Scan(
 			&rule.ID, &rule.ProjectID, &rule.RuleID, &rule.Name, &rule.Description,
-			&rule.Type, &rule.Severity, &rule.Pattern, &rule.Message, &rule.IsActive)
+			&rule.Type, &rule.Severity, &rule.Pattern, &rule.Message, &rule.IsActive,
+			&rule.MatcherKind, &rule.Flags, &rule.FileGlob, &rule.Limit, &rule.Replacement, &rule.UpdatedAt)
 		if err != nil {
 			return nil, mapDBError(err)
 		}
@@ -179,12 +219,14 @@ func (d *PostgresRuleRepository) GetByProjectID(projectID string) ([]*domain.Rul
 }
 
 func (d *PostgresRuleRepository) GetByID(projectID, ruleID string) (*domain.Rule, error) {
-	query := `SELECT id, project_id, rule_id, name, description, type, severity, pattern, message, is_active
+	query := `SELECT id, project_id, rule_id, name, description, type, severity, pattern, message, is_active,
+              matcher_kind, flags, file_glob, match_limit, replacement, updated_at
               FROM rules WHERE project_id = $1 AND rule_id = $2`
 	var rule domain.Rule
 	err := d.DB.QueryRow(query, projectID, ruleID).Scan(
 		&rule.ID, &rule.ProjectID, &rule.RuleID, &rule.Name, &rule.Description,
 		&rule.Type, &rule.Severity, &rule.Pattern, &rule.Message, &rule.IsActive,
+		&rule.MatcherKind, &rule.Flags, &rule.FileGlob, &rule.Limit, &rule.Replacement, &rule.UpdatedAt,
 	)
 	if err != nil {
 		return nil, mapDBError(err)
@@ -193,9 +235,11 @@ func (d *PostgresRuleRepository) GetByID(projectID, ruleID string) (*domain.Rule
 }
 
 func (d *PostgresRuleRepository) Update(rule *domain.Rule) error {
-	query := `UPDATE rules SET name=$3, description=$4, type=$5, severity=$6, pattern=$7, message=$8, is_active=$9, project_id=$2
+	query := `UPDATE rules SET name=$3, description=$4, type=$5, severity=$6, pattern=$7, message=$8, is_active=$9, project_id=$2,
+              matcher_kind=$11, flags=$12, file_glob=$13, match_limit=$14, replacement=$15, updated_at=NOW()
               WHERE project_id=$1 AND rule_id=$10`
-	_, err := d.DB.Exec(query, rule.ProjectID, rule.ProjectID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.IsActive, rule.RuleID)
+	_, err := d.DB.Exec(query, rule.ProjectID, rule.ProjectID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.IsActive, rule.RuleID,
+		rule.Kind(), rule.Flags, rule.FileGlob, rule.Limit, rule.Replacement)
 	return mapDBError(err)
 }
 
@@ -207,14 +251,16 @@ func (d *PostgresRuleRepository) Delete(projectID, ruleID string) error {
 
 // GlobalRuleRepository implementation
 func (d *PostgresGlobalRuleRepository) Create(rule *domain.GlobalRule) error {
-	query := `INSERT INTO global_rules (language, rule_id, name, description, type, severity, pattern, message, is_active) 
-			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
-	_, err := d.DB.Exec(query, rule.Language, rule.RuleID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.IsActive)
+	query := `INSERT INTO global_rules (language, rule_id, name, description, type, severity, pattern, message, is_active, matcher_kind, flags, file_glob, match_limit, replacement) 
+			  VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	_, err := d.DB.Exec(query, rule.Language, rule.RuleID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.IsActive,
+		rule.Kind(), rule.Flags, rule.FileGlob, rule.Limit, rule.Replacement)
 	return mapDBError(err)
 }
 
 func (d *PostgresGlobalRuleRepository) GetByLanguage(language string) ([]*domain.GlobalRule, error) {
-	query := `SELECT id, language, rule_id, name, description, type, severity, pattern, message, is_active 
+	query := `SELECT id, language, rule_id, name, description, type, severity, pattern, message, is_active,
+			  matcher_kind, flags, file_glob, match_limit, replacement, updated_at
 			  FROM global_rules WHERE language = $1 AND is_active = true ORDER BY severity DESC, name ASC`
 
 	rows, err := d.DB.Query(query, language)
@@ -228,7 +274,8 @@ func (d *PostgresGlobalRuleRepository) GetByLanguage(language string) ([]*domain
 		var rule domain.GlobalRule
 		err := rows.Scan(
 			&rule.ID, &rule.Language, &rule.RuleID, &rule.Name, &rule.Description,
-			&rule.Type, &rule.Severity, &rule.Pattern, &rule.Message, &rule.IsActive)
+			&rule.Type, &rule.Severity, &rule.Pattern, &rule.Message, &rule.IsActive,
+			&rule.MatcherKind, &rule.Flags, &rule.FileGlob, &rule.Limit, &rule.Replacement, &rule.UpdatedAt)
 		if err != nil {
 			return nil, mapDBError(err)
 		}
@@ -238,6 +285,61 @@ func (d *PostgresGlobalRuleRepository) GetByLanguage(language string) ([]*domain
 	return rules, nil
 }
 
+// GetAll 無効化したルールも含めて全言語のグローバルルールを取得
+func (d *PostgresGlobalRuleRepository) GetAll() ([]*domain.GlobalRule, error) {
+	query := `SELECT id, language, rule_id, name, description, type, severity, pattern, message, is_active,
+			  matcher_kind, flags, file_glob, match_limit, replacement, updated_at
+			  FROM global_rules ORDER BY language, rule_id`
+
+	rows, err := d.DB.Query(query)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	defer rows.Close()
+
+	var rules []*domain.GlobalRule
+	for rows.Next() {
+		var rule domain.GlobalRule
+		err := rows.Scan(
+			&rule.ID, &rule.Language, &rule.RuleID, &rule.Name, &rule.Description,
+			&rule.Type, &rule.Severity, &rule.Pattern, &rule.Message, &rule.IsActive,
+			&rule.MatcherKind, &rule.Flags, &rule.FileGlob, &rule.Limit, &rule.Replacement, &rule.UpdatedAt)
+		if err != nil {
+			return nil, mapDBError(err)
+		}
+		rules = append(rules, &rule)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, mapDBError(err)
+	}
+	return rules, nil
+}
+
+func (d *PostgresGlobalRuleRepository) GetByID(language, ruleID string) (*domain.GlobalRule, error) {
+	query := `SELECT id, language, rule_id, name, description, type, severity, pattern, message, is_active,
+              matcher_kind, flags, file_glob, match_limit, replacement, updated_at
+              FROM global_rules WHERE language = $1 AND rule_id = $2`
+	var rule domain.GlobalRule
+	err := d.DB.QueryRow(query, language, ruleID).Scan(
+		&rule.ID, &rule.Language, &rule.RuleID, &rule.Name, &rule.Description,
+		&rule.Type, &rule.Severity, &rule.Pattern, &rule.Message, &rule.IsActive,
+		&rule.MatcherKind, &rule.Flags, &rule.FileGlob, &rule.Limit, &rule.Replacement, &rule.UpdatedAt,
+	)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	return &rule, nil
+}
+
+func (d *PostgresGlobalRuleRepository) Update(rule *domain.GlobalRule) error {
+	query := `UPDATE global_rules SET name=$3, description=$4, type=$5, severity=$6, pattern=$7, message=$8, is_active=$9,
+              matcher_kind=$10, flags=$11, file_glob=$12, match_limit=$13, replacement=$14, updated_at=NOW()
+              WHERE language=$1 AND rule_id=$2`
+	_, err := d.DB.Exec(query, rule.Language, rule.RuleID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.IsActive,
+		rule.Kind(), rule.Flags, rule.FileGlob, rule.Limit, rule.Replacement)
+	return mapDBError(err)
+}
+
 func (d *PostgresGlobalRuleRepository) GetAllLanguages() ([]string, error) {
 	query := `SELECT DISTINCT language FROM global_rules WHERE is_active = true ORDER BY language`
 
@@ -359,10 +461,11 @@ func (r *PostgresRoleRepository) Delete(name string) error {
 	return mapDBError(err)
 }
 
-// GetByLanguage 言語別にプロジェクトを取得
+// GetByLanguage 言語別にプロジェクトを取得（languages に指定言語を含むプロジェクトも対象）
 func (d *PostgresDatabase) GetByLanguage(language string) ([]*domain.Project, error) {
-	query := `SELECT project_id, name, description, language, apply_global_rules, access_level, created_by, created_at, updated_at 
-			  FROM projects WHERE language = $1 ORDER BY created_at DESC`
+	query := `SELECT project_id, name, description, language, COALESCE(languages, '[]'), apply_global_rules, COALESCE(access_level, 'public'), COALESCE(created_by, ''), created_at, updated_at 
+			  FROM projects WHERE language = $1 OR languages @> jsonb_build_array(jsonb_build_object('language', $1::text))
+			  ORDER BY created_at DESC`
 
 	rows, err := d.DB.Query(query, language)
 	if err != nil {
@@ -373,11 +476,13 @@ func (d *PostgresDatabase) GetByLanguage(language string) ([]*domain.Project, er
 	var projects []*domain.Project
 	for rows.Next() {
 		var project domain.Project
+		var languages []byte
 		err := rows.Scan(
 			&project.ProjectID,
 			&project.Name,
 			&project.Description,
 			&project.Language,
+			&languages,
 			&project.ApplyGlobalRules,
 			&project.AccessLevel,
 			&project.CreatedBy,
@@ -387,6 +492,7 @@ func (d *PostgresDatabase) GetByLanguage(language string) ([]*domain.Project, er
 		if err != nil {
 			return nil, mapDBError(err)
 		}
+		project.Languages = decodeProjectLanguages(languages)
 		projects = append(projects, &project)
 	}
 
@@ -499,6 +605,47 @@ func (m *PostgresMetricsRepository) GetMCPRequestsCountLast24h() (int, error) {
 	return cnt, nil
 }
 
+// ViolationRepository implementation
+// Record 違反を1回の複数行INSERTで記録する
+func (v *PostgresViolationRepository) Record(projectID, filePath string, issues []domain.ValidationIssue) error {
+	if len(issues) == 0 {
+		return nil
+	}
+	var b strings.Builder
+	b.WriteString(`INSERT INTO rule_violations (project_id, rule_key, code_snippet, file_path, line_number, severity) VALUES `)
+	args := make([]interface{}, 0, len(issues)*6)
+	for i, issue := range issues {
+		if i > 0 {
+			b.WriteString(", ")
+		}
+		n := len(args)
+		fmt.Fprintf(&b, "($%d, $%d, $%d, $%d, $%d, $%d)", n+1, n+2, n+3, n+4, n+5, n+6)
+		args = append(args, projectID, issue.RuleID, issue.Snippet, filePath, issue.LineNumber, issue.Severity)
+	}
+	_, err := v.DB.Exec(b.String(), args...)
+	return mapDBError(err)
+}
+
+func (v *PostgresViolationRepository) CountBySeverity(projectID string, since time.Time) (map[string]int, error) {
+	q := `SELECT severity, COUNT(*) FROM rule_violations WHERE project_id = $1 AND created_at > $2 GROUP BY severity`
+	rows, err := v.DB.Query(q, projectID, since)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	defer rows.Close()
+
+	counts := map[string]int{}
+	for rows.Next() {
+		var severity string
+		var cnt int
+		if err := rows.Scan(&severity, &cnt); err != nil {
+			return nil, mapDBError(err)
+		}
+		counts[severity] = cnt
+	}
+	return counts, nil
+}
+
 func mapDBError(err error) error {
 	if err == nil {
 		return nil
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+type PostgresProjectMemberRepository struct {
+	db *sql.DB
+}
+
+var _ domain.ProjectMemberRepository = (*PostgresProjectMemberRepository)(nil)
+
+func NewPostgresProjectMemberRepository(db *sql.DB) *PostgresProjectMemberRepository {
+	return &PostgresProjectMemberRepository{db: db}
+}
+
+const projectMemberQuery = `
+	SELECT m.project_id, m.user_id, u.username, m.role, m.created_at
+	FROM project_members m JOIN users u ON u.id = m.user_id
+`
+
+func (r *PostgresProjectMemberRepository) GetByProject(projectID string) ([]*domain.ProjectMember, error) {
+	return r.query(projectMemberQuery+` WHERE m.project_id = $1 ORDER BY u.username`, projectID)
+}
+
+func (r *PostgresProjectMemberRepository) GetByUser(userID int) ([]*domain.ProjectMember, error) {
+	return r.query(projectMemberQuery+` WHERE m.user_id = $1 ORDER BY m.project_id`, userID)
+}
+
+func (r *PostgresProjectMemberRepository) Get(projectID string, userID int) (*domain.ProjectMember, error) {
+	var m domain.ProjectMember
+	err := r.db.QueryRow(projectMemberQuery+` WHERE m.project_id = $1 AND m.user_id = $2`, projectID, userID).Scan(
+		&m.ProjectID, &m.UserID, &m.Username, &m.Role, &m.CreatedAt,
+	)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	return &m, nil
+}
+
+func (r *PostgresProjectMemberRepository) Save(m *domain.ProjectMember) error {
+	query := `
+		INSERT INTO project_members (project_id, user_id, role, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (project_id, user_id) DO UPDATE SET role = EXCLUDED.role
+	`
+	_, err := r.db.Exec(query, m.ProjectID, m.UserID, m.Role, m.CreatedAt)
+	return mapDBError(err)
+}
+
+func (r *PostgresProjectMemberRepository) Delete(projectID string, userID int) error {
+	_, err := r.db.Exec(`DELETE FROM project_members WHERE project_id = $1 AND user_id = $2`, projectID, userID)
+	return mapDBError(err)
+}
+
+func (r *PostgresProjectMemberRepository) query(query string, args ...interface{}) ([]*domain.ProjectMember, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	defer rows.Close()
+
+	members := []*domain.ProjectMember{}
+	for rows.Next() {
+		var m domain.ProjectMember
+		if err := rows.Scan(&m.ProjectID, &m.UserID, &m.Username, &m.Role, &m.CreatedAt); err != nil {
+			return nil, mapDBError(err)
+		}
+		members = append(members, &m)
+	}
+	return members, rows.Err()
+}
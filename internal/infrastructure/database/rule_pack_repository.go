@@ -0,0 +1,205 @@
+package database
+
+import (
+	"database/sql"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+type PostgresRulePackRepository struct {
+	db *sql.DB
+}
+
+var _ domain.RulePackRepository = (*PostgresRulePackRepository)(nil)
+
+func NewPostgresRulePackRepository(db *sql.DB) *PostgresRulePackRepository {
+	return &PostgresRulePackRepository{db: db}
+}
+
+func (r *PostgresRulePackRepository) Create(pack *domain.RulePack) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return mapDBError(err)
+	}
+	defer tx.Rollback()
+
+	_, err = tx.Exec(`INSERT INTO rule_packs (name, description, created_at, updated_at) VALUES ($1, $2, $3, $4)`,
+		pack.Name, pack.Description, pack.CreatedAt, pack.UpdatedAt)
+	if err != nil {
+		return mapDBError(err)
+	}
+	if err := insertPackRules(tx, pack); err != nil {
+		return err
+	}
+	return mapDBError(tx.Commit())
+}
+
+// insertPackRules パックのルールを並び順（position）付きで保存
+func insertPackRules(tx *sql.Tx, pack *domain.RulePack) error {
+	stmt, err := tx.Prepare(`
+		INSERT INTO rule_pack_rules (pack_name, position, rule_id, name, description, type, severity, pattern, message, is_active,
+			language, matcher_kind, flags, file_glob, match_limit, replacement, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	`)
+	if err != nil {
+		return mapDBError(err)
+	}
+	defer stmt.Close()
+	for i, rule := range pack.Rules {
+		_, err := stmt.Exec(pack.Name, i, rule.RuleID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.IsActive,
+			rule.Language, rule.Kind(), rule.Flags, rule.FileGlob, rule.Limit, rule.Replacement, pack.UpdatedAt)
+		if err != nil {
+			return mapDBError(err)
+		}
+	}
+	return nil
+}
+
+func (r *PostgresRulePackRepository) GetByName(name string) (*domain.RulePack, error) {
+	var pack domain.RulePack
+	err := r.db.QueryRow(`SELECT name, description, created_at, updated_at FROM rule_packs WHERE name = $1`, name).Scan(
+		&pack.Name, &pack.Description, &pack.CreatedAt, &pack.UpdatedAt,
+	)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	if pack.Rules, err = r.rules(name); err != nil {
+		return nil, err
+	}
+	return &pack, nil
+}
+
+func (r *PostgresRulePackRepository) rules(name string) ([]domain.Rule, error) {
+	query := `SELECT id, rule_id, name, description, type, severity, pattern, message, is_active,
+			  language, matcher_kind, flags, file_glob, match_limit, replacement, updated_at
+			  FROM rule_pack_rules WHERE pack_name = $1 ORDER BY position`
+	rows, err := r.db.Query(query, name)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	defer rows.Close()
+
+	rules := []domain.Rule{}
+	for rows.Next() {
+		rule := domain.Rule{Pack: name}
+		err := rows.Scan(
+			&rule.ID, &rule.RuleID, &rule.Name, &rule.Description, &rule.Type, &rule.Severity, &rule.Pattern, &rule.Message, &rule.IsActive,
+			&rule.Language, &rule.MatcherKind, &rule.Flags, &rule.FileGlob, &rule.Limit, &rule.Replacement, &rule.UpdatedAt)
+		if err != nil {
+			return nil, mapDBError(err)
+		}
+		rules = append(rules, rule)
+	}
+	return rules, rows.Err()
+}
+
+func (r *PostgresRulePackRepository) GetAll() ([]*domain.RulePack, error) {
+	rows, err := r.db.Query(`SELECT name FROM rule_packs ORDER BY name`)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			rows.Close()
+			return nil, mapDBError(err)
+		}
+		names = append(names, name)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, mapDBError(err)
+	}
+
+	packs := make([]*domain.RulePack, 0, len(names))
+	for _, name := range names {
+		pack, err := r.GetByName(name)
+		if err != nil {
+			return nil, err
+		}
+		packs = append(packs, pack)
+	}
+	return packs, nil
+}
+
+func (r *PostgresRulePackRepository) Update(pack *domain.RulePack) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return mapDBError(err)
+	}
+	defer tx.Rollback()
+
+	res, err := tx.Exec(`UPDATE rule_packs SET description = $2, updated_at = $3 WHERE name = $1`, pack.Name, pack.Description, pack.UpdatedAt)
+	if err != nil {
+		return mapDBError(err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return mapDBError(sql.ErrNoRows)
+	}
+	if _, err := tx.Exec(`DELETE FROM rule_pack_rules WHERE pack_name = $1`, pack.Name); err != nil {
+		return mapDBError(err)
+	}
+	if err := insertPackRules(tx, pack); err != nil {
+		return err
+	}
+	return mapDBError(tx.Commit())
+}
+
+func (r *PostgresRulePackRepository) Delete(name string) error {
+	_, err := r.db.Exec(`DELETE FROM rule_packs WHERE name = $1`, name)
+	return mapDBError(err)
+}
+
+func (r *PostgresRulePackRepository) GetByProject(projectID string) ([]*domain.ProjectRulePack, error) {
+	query := `SELECT project_id, pack_name, priority, created_at FROM project_rule_packs
+			  WHERE project_id = $1 ORDER BY priority DESC, pack_name`
+	rows, err := r.db.Query(query, projectID)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	defer rows.Close()
+
+	attached := []*domain.ProjectRulePack{}
+	for rows.Next() {
+		var a domain.ProjectRulePack
+		if err := rows.Scan(&a.ProjectID, &a.Pack, &a.Priority, &a.CreatedAt); err != nil {
+			return nil, mapDBError(err)
+		}
+		attached = append(attached, &a)
+	}
+	return attached, rows.Err()
+}
+
+func (r *PostgresRulePackRepository) GetProjects(name string) ([]string, error) {
+	rows, err := r.db.Query(`SELECT project_id FROM project_rule_packs WHERE pack_name = $1 ORDER BY project_id`, name)
+	if err != nil {
+		return nil, mapDBError(err)
+	}
+	defer rows.Close()
+
+	var projectIDs []string
+	for rows.Next() {
+		var projectID string
+		if err := rows.Scan(&projectID); err != nil {
+			return nil, mapDBError(err)
+		}
+		projectIDs = append(projectIDs, projectID)
+	}
+	return projectIDs, rows.Err()
+}
+
+func (r *PostgresRulePackRepository) Attach(a *domain.ProjectRulePack) error {
+	query := `
+		INSERT INTO project_rule_packs (project_id, pack_name, priority, created_at)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (project_id, pack_name) DO UPDATE SET priority = EXCLUDED.priority
+	`
+	_, err := r.db.Exec(query, a.ProjectID, a.Pack, a.Priority, a.CreatedAt)
+	return mapDBError(err)
+}
+
+func (r *PostgresRulePackRepository) Detach(projectID, name string) error {
+	_, err := r.db.Exec(`DELETE FROM project_rule_packs WHERE project_id = $1 AND pack_name = $2`, projectID, name)
+	return mapDBError(err)
+}
@@ -0,0 +1,750 @@
+// Package rulesfile はローカルのルールファイル（rules.json）をリポジトリとして提供する
+// データベースを用意できない環境（stdio MCPなど）向けで、変更はメモリ上にのみ保持する
+package rulesfile
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// Ensure implementations
+var _ domain.ProjectRepository = (*ProjectRepository)(nil)
+var _ domain.RuleRepository = (*RuleRepository)(nil)
+var _ domain.GlobalRuleRepository = (*GlobalRuleRepository)(nil)
+var _ domain.ViolationRepository = (*ViolationRepository)(nil)
+var _ domain.GlobalRuleOverrideRepository = (*GlobalRuleOverrideRepository)(nil)
+var _ domain.RulePackRepository = (*RulePackRepository)(nil)
+
+// maxViolations メモリ上に保持する違反記録の上限（古いものから捨てる）
+const maxViolations = 10000
+
+// fileRule ルールファイル上のルール（"id" または "rule_id" でルールIDを指定）
+type fileRule struct {
+	ID          string `json:"id"`
+	RuleID      string `json:"rule_id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Type        string `json:"type"`
+	Severity    string `json:"severity"`
+	Pattern     string `json:"pattern"`
+	Message     string `json:"message"`
+	Replacement string `json:"replacement,omitempty"`
+	IsActive    *bool  `json:"is_active,omitempty"` // 省略時は有効
+	Language    string `json:"language,omitempty"`  // ルールパックのルールのみ（空なら全言語）
+	domain.RuleMatcher
+}
+
+// fileProject ルールファイル上のプロジェクト
+type fileProject struct {
+	ProjectID        string     `json:"project_id"`
+	Name             string     `json:"name"`
+	Description      string     `json:"description"`
+	Language         string     `json:"language"`
+	ApplyGlobalRules *bool      `json:"apply_global_rules,omitempty"` // 省略時は適用
+	AccessLevel      string     `json:"access_level,omitempty"`       // 省略時は public
+	Rules            []fileRule `json:"rules"`
+	// GlobalRuleOverrides 継承するグローバルルールの上書き
+	GlobalRuleOverrides []fileOverride `json:"global_rule_overrides,omitempty"`
+	// RulePacks 適用するルールパック
+	RulePacks []filePackRef `json:"rule_packs,omitempty"`
+	// Languages 複数言語のプロジェクトの言語とパスglob（language 省略時は先頭を主言語とする）
+	Languages []domain.ProjectLanguage `json:"languages,omitempty"`
+}
+
+// filePackRef プロジェクトに適用するルールパック（priority が大きいほど優先）
+type filePackRef struct {
+	Name     string `json:"name"`
+	Priority int    `json:"priority"`
+}
+
+// filePack ルールファイル上のルールパック
+type filePack struct {
+	Description string     `json:"description"`
+	Rules       []fileRule `json:"rules"`
+}
+
+// fileOverride ルールファイル上のグローバルルールの上書き（language 省略時はプロジェクトの言語）
+type fileOverride struct {
+	Language string `json:"language"`
+	RuleID   string `json:"rule_id"`
+	Disabled bool   `json:"disabled"`
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+}
+
+// fileLayout プロジェクトとグローバルルールを併せて記述する形式
+type fileLayout struct {
+	Projects    map[string]fileProject `json:"projects"`
+	GlobalRules map[string][]fileRule  `json:"global_rules"` // キーは言語
+	RulePacks   map[string]filePack    `json:"rule_packs"`   // キーはパック名
+}
+
+// Store ルールファイルから読み込んだプロジェクト・ルール・グローバルルール
+type Store struct {
+	mu          sync.RWMutex
+	projects    map[string]*domain.Project
+	rules       map[string][]*domain.Rule
+	globalRules map[string][]*domain.GlobalRule
+	overrides   map[string][]*domain.GlobalRuleOverride // キーはプロジェクトID
+	packs       map[string]*domain.RulePack
+	attached    map[string][]*domain.ProjectRulePack // キーはプロジェクトID
+	violations  []violation
+}
+
+// violation 記録した違反（集計に必要な項目のみ保持）
+type violation struct {
+	projectID string
+	severity  string
+	at        time.Time
+}
+
+// Load ルールファイルを読み込む
+// 従来の「プロジェクトID → {project_id, rules}」形式と、
+// {"projects": {...}, "global_rules": {"go": [...]}} 形式のどちらにも対応する
+func Load(path string) (*Store, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+// Parse ルールファイルの内容を解析
+func Parse(data []byte) (*Store, error) {
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(data, &top); err != nil {
+		return nil, fmt.Errorf("invalid rules file: %w", err)
+	}
+
+	var layout fileLayout
+	if _, ok := top["projects"]; ok {
+		if err := json.Unmarshal(data, &layout); err != nil {
+			return nil, fmt.Errorf("invalid rules file: %w", err)
+		}
+	} else if err := json.Unmarshal(data, &layout.Projects); err != nil {
+		return nil, fmt.Errorf("invalid rules file: %w", err)
+	}
+
+	s := &Store{
+		projects:    map[string]*domain.Project{},
+		rules:       map[string][]*domain.Rule{},
+		globalRules: map[string][]*domain.GlobalRule{},
+		overrides:   map[string][]*domain.GlobalRuleOverride{},
+		packs:       map[string]*domain.RulePack{},
+		attached:    map[string][]*domain.ProjectRulePack{},
+	}
+	now := time.Now()
+	for key, fp := range layout.Projects {
+		projectID := fp.ProjectID
+		if projectID == "" {
+			projectID = key
+		}
+		name := fp.Name
+		if name == "" {
+			name = projectID
+		}
+		accessLevel := fp.AccessLevel
+		if accessLevel == "" {
+			accessLevel = domain.ProjectAccessPublic
+		}
+		if !domain.ValidProjectAccessLevel(accessLevel) {
+			return nil, fmt.Errorf("invalid rules file: unknown access_level %q in project %q", accessLevel, projectID)
+		}
+		for _, l := range fp.Languages {
+			if l.Language == "" {
+				return nil, fmt.Errorf("invalid rules file: language entry without language in project %q", projectID)
+			}
+		}
+		if fp.Language == "" && len(fp.Languages) > 0 {
+			fp.Language = fp.Languages[0].Language
+		}
+		s.projects[projectID] = &domain.Project{
+			ProjectID:        projectID,
+			Name:             name,
+			Description:      fp.Description,
+			Language:         fp.Language,
+			Languages:        fp.Languages,
+			ApplyGlobalRules: fp.ApplyGlobalRules == nil || *fp.ApplyGlobalRules,
+			AccessLevel:      accessLevel,
+			CreatedAt:        now,
+			UpdatedAt:        now,
+		}
+		for _, fr := range fp.Rules {
+			rule, err := fr.rule(projectID)
+			if err != nil {
+				return nil, err
+			}
+			s.rules[projectID] = append(s.rules[projectID], rule)
+		}
+		for _, fo := range fp.GlobalRuleOverrides {
+			language := fo.Language
+			if language == "" {
+				language = fp.Language
+			}
+			if fo.RuleID == "" {
+				return nil, fmt.Errorf("invalid rules file: global rule override without rule_id in project %q", projectID)
+			}
+			s.overrides[projectID] = append(s.overrides[projectID], &domain.GlobalRuleOverride{
+				ProjectID: projectID,
+				Language:  language,
+				RuleID:    fo.RuleID,
+				Disabled:  fo.Disabled,
+				Severity:  fo.Severity,
+				Message:   fo.Message,
+				CreatedAt: now,
+				UpdatedAt: now,
+			})
+		}
+		for _, ref := range fp.RulePacks {
+			if _, ok := layout.RulePacks[ref.Name]; !ok {
+				return nil, fmt.Errorf("invalid rules file: unknown rule pack %q in project %q", ref.Name, projectID)
+			}
+			s.attached[projectID] = append(s.attached[projectID], &domain.ProjectRulePack{
+				ProjectID: projectID,
+				Pack:      ref.Name,
+				Priority:  ref.Priority,
+				CreatedAt: now,
+			})
+		}
+	}
+	for name, fp := range layout.RulePacks {
+		pack := &domain.RulePack{Name: name, Description: fp.Description, Rules: []domain.Rule{}, CreatedAt: now, UpdatedAt: now}
+		for _, fr := range fp.Rules {
+			rule, err := fr.rule("")
+			if err != nil {
+				return nil, err
+			}
+			rule.Pack = name
+			rule.Language = fr.Language
+			pack.Rules = append(pack.Rules, *rule)
+		}
+		s.packs[name] = pack
+	}
+	for language, frs := range layout.GlobalRules {
+		for _, fr := range frs {
+			rule, err := fr.rule("")
+			if err != nil {
+				return nil, err
+			}
+			s.globalRules[language] = append(s.globalRules[language], &domain.GlobalRule{
+				Language:    language,
+				RuleID:      rule.RuleID,
+				Name:        rule.Name,
+				Description: rule.Description,
+				Type:        rule.Type,
+				Severity:    rule.Severity,
+				Pattern:     rule.Pattern,
+				Message:     rule.Message,
+				Replacement: rule.Replacement,
+				IsActive:    rule.IsActive,
+				RuleMatcher: rule.RuleMatcher,
+			})
+		}
+	}
+	return s, nil
+}
+
+func (fr fileRule) rule(projectID string) (*domain.Rule, error) {
+	ruleID := fr.RuleID
+	if ruleID == "" {
+		ruleID = fr.ID
+	}
+	if ruleID == "" {
+		return nil, fmt.Errorf("invalid rules file: rule without id in project %q", projectID)
+	}
+	return &domain.Rule{
+		ProjectID:   projectID,
+		RuleID:      ruleID,
+		Name:        fr.Name,
+		Description: fr.Description,
+		Type:        fr.Type,
+		Severity:    fr.Severity,
+		Pattern:     fr.Pattern,
+		Message:     fr.Message,
+		Replacement: fr.Replacement,
+		IsActive:    fr.IsActive == nil || *fr.IsActive,
+		RuleMatcher: fr.RuleMatcher,
+	}, nil
+}
+
+// Projects プロジェクトリポジトリ
+func (s *Store) Projects() *ProjectRepository { return &ProjectRepository{s} }
+
+// Rules ルールリポジトリ
+func (s *Store) Rules() *RuleRepository { return &RuleRepository{s} }
+
+// GlobalRules グローバルルールリポジトリ
+func (s *Store) GlobalRules() *GlobalRuleRepository { return &GlobalRuleRepository{s} }
+
+// GlobalRuleOverrides プロジェクト単位のグローバルルールの上書き
+func (s *Store) GlobalRuleOverrides() *GlobalRuleOverrideRepository {
+	return &GlobalRuleOverrideRepository{s}
+}
+
+// RulePacks ルールパック
+func (s *Store) RulePacks() *RulePackRepository { return &RulePackRepository{s} }
+
+// Violations 違反の記録先（プロセス内でのみ保持）
+func (s *Store) Violations() *ViolationRepository { return &ViolationRepository{s} }
+
+func notFound() error {
+	return apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+
+func conflict() error {
+	return apperr.Wrap(apperr.ErrConflict, "一意制約に違反しています")
+}
+
+// ProjectRepository ルールファイルのプロジェクト
+type ProjectRepository struct{ s *Store }
+
+func (r *ProjectRepository) Create(project *domain.Project) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projects[project.ProjectID]; ok {
+		return conflict()
+	}
+	p := *project
+	r.s.projects[p.ProjectID] = &p
+	return nil
+}
+
+func (r *ProjectRepository) GetByID(projectID string) (*domain.Project, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	p, ok := r.s.projects[projectID]
+	if !ok {
+		return nil, notFound()
+	}
+	cp := *p
+	return &cp, nil
+}
+
+func (r *ProjectRepository) GetAll() ([]*domain.Project, error) {
+	return r.filter(func(*domain.Project) bool { return true }), nil
+}
+
+func (r *ProjectRepository) GetByLanguage(language string) ([]*domain.Project, error) {
+	return r.filter(func(p *domain.Project) bool { return p.HasLanguage(language) }), nil
+}
+
+func (r *ProjectRepository) filter(keep func(*domain.Project) bool) []*domain.Project {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	projects := []*domain.Project{}
+	for _, p := range r.s.projects {
+		if keep(p) {
+			cp := *p
+			projects = append(projects, &cp)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ProjectID < projects[j].ProjectID })
+	return projects
+}
+
+func (r *ProjectRepository) Update(project *domain.Project) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.projects[project.ProjectID]; !ok {
+		return notFound()
+	}
+	p := *project
+	r.s.projects[p.ProjectID] = &p
+	return nil
+}
+
+func (r *ProjectRepository) Delete(projectID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.projects, projectID)
+	delete(r.s.rules, projectID)
+	delete(r.s.overrides, projectID)
+	delete(r.s.attached, projectID)
+	return nil
+}
+
+// RuleRepository ルールファイルのプロジェクトルール
+type RuleRepository struct{ s *Store }
+
+func (r *RuleRepository) Create(rule *domain.Rule) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, existing := range r.s.rules[rule.ProjectID] {
+		if existing.RuleID == rule.RuleID {
+			return conflict()
+		}
+	}
+	cp := *rule
+	r.s.rules[rule.ProjectID] = append(r.s.rules[rule.ProjectID], &cp)
+	return nil
+}
+
+func (r *RuleRepository) GetByProjectID(projectID string) ([]*domain.Rule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rules := make([]*domain.Rule, 0, len(r.s.rules[projectID]))
+	for _, rule := range r.s.rules[projectID] {
+		cp := *rule
+		rules = append(rules, &cp)
+	}
+	return rules, nil
+}
+
+func (r *RuleRepository) GetByID(projectID, ruleID string) (*domain.Rule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, rule := range r.s.rules[projectID] {
+		if rule.RuleID == ruleID {
+			cp := *rule
+			return &cp, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (r *RuleRepository) Update(rule *domain.Rule) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i, existing := range r.s.rules[rule.ProjectID] {
+		if existing.RuleID == rule.RuleID {
+			cp := *rule
+			r.s.rules[rule.ProjectID][i] = &cp
+			return nil
+		}
+	}
+	return notFound()
+}
+
+func (r *RuleRepository) Delete(projectID, ruleID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rules := r.s.rules[projectID]
+	for i, existing := range rules {
+		if existing.RuleID == ruleID {
+			r.s.rules[projectID] = append(rules[:i:i], rules[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// GlobalRuleRepository ルールファイルの言語別グローバルルール
+type GlobalRuleRepository struct{ s *Store }
+
+func (r *GlobalRuleRepository) Create(rule *domain.GlobalRule) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for _, existing := range r.s.globalRules[rule.Language] {
+		if existing.RuleID == rule.RuleID {
+			return conflict()
+		}
+	}
+	cp := *rule
+	r.s.globalRules[rule.Language] = append(r.s.globalRules[rule.Language], &cp)
+	return nil
+}
+
+// GetByLanguage 有効なグローバルルールを取得（Postgres実装と同じく無効なルールは除く）
+func (r *GlobalRuleRepository) GetByLanguage(language string) ([]*domain.GlobalRule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rules := []*domain.GlobalRule{}
+	for _, rule := range r.s.globalRules[language] {
+		if rule.IsActive {
+			cp := *rule
+			rules = append(rules, &cp)
+		}
+	}
+	return rules, nil
+}
+
+// GetAll 無効化したルールも含めて全言語のグローバルルールを取得
+func (r *GlobalRuleRepository) GetAll() ([]*domain.GlobalRule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	rules := []*domain.GlobalRule{}
+	for _, langRules := range r.s.globalRules {
+		for _, rule := range langRules {
+			cp := *rule
+			rules = append(rules, &cp)
+		}
+	}
+	sort.Slice(rules, func(i, j int) bool {
+		if rules[i].Language != rules[j].Language {
+			return rules[i].Language < rules[j].Language
+		}
+		return rules[i].RuleID < rules[j].RuleID
+	})
+	return rules, nil
+}
+
+func (r *GlobalRuleRepository) GetByID(language, ruleID string) (*domain.GlobalRule, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, rule := range r.s.globalRules[language] {
+		if rule.RuleID == ruleID {
+			cp := *rule
+			return &cp, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (r *GlobalRuleRepository) Update(rule *domain.GlobalRule) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	for i, existing := range r.s.globalRules[rule.Language] {
+		if existing.RuleID == rule.RuleID {
+			cp := *rule
+			r.s.globalRules[rule.Language][i] = &cp
+			return nil
+		}
+	}
+	return notFound()
+}
+
+func (r *GlobalRuleRepository) GetAllLanguages() ([]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	languages := make([]string, 0, len(r.s.globalRules))
+	for language := range r.s.globalRules {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	return languages, nil
+}
+
+func (r *GlobalRuleRepository) Delete(language, ruleID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	rules := r.s.globalRules[language]
+	for i, existing := range rules {
+		if existing.RuleID == ruleID {
+			r.s.globalRules[language] = append(rules[:i:i], rules[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// GlobalRuleOverrideRepository ルールファイルのプロジェクト単位のグローバルルール上書き
+type GlobalRuleOverrideRepository struct{ s *Store }
+
+func (r *GlobalRuleOverrideRepository) GetByProject(projectID string) ([]*domain.GlobalRuleOverride, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	overrides := make([]*domain.GlobalRuleOverride, 0, len(r.s.overrides[projectID]))
+	for _, o := range r.s.overrides[projectID] {
+		cp := *o
+		overrides = append(overrides, &cp)
+	}
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].Language != overrides[j].Language {
+			return overrides[i].Language < overrides[j].Language
+		}
+		return overrides[i].RuleID < overrides[j].RuleID
+	})
+	return overrides, nil
+}
+
+func (r *GlobalRuleOverrideRepository) Get(projectID, language, ruleID string) (*domain.GlobalRuleOverride, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	for _, o := range r.s.overrides[projectID] {
+		if o.Language == language && o.RuleID == ruleID {
+			cp := *o
+			return &cp, nil
+		}
+	}
+	return nil, notFound()
+}
+
+func (r *GlobalRuleOverrideRepository) Save(override *domain.GlobalRuleOverride) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	cp := *override
+	for i, o := range r.s.overrides[cp.ProjectID] {
+		if o.Language == cp.Language && o.RuleID == cp.RuleID {
+			r.s.overrides[cp.ProjectID][i] = &cp
+			return nil
+		}
+	}
+	r.s.overrides[cp.ProjectID] = append(r.s.overrides[cp.ProjectID], &cp)
+	return nil
+}
+
+func (r *GlobalRuleOverrideRepository) Delete(projectID, language, ruleID string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	overrides := r.s.overrides[projectID]
+	for i, o := range overrides {
+		if o.Language == language && o.RuleID == ruleID {
+			r.s.overrides[projectID] = append(overrides[:i:i], overrides[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// RulePackRepository ルールファイルのルールパック
+type RulePackRepository struct{ s *Store }
+
+// copyPack ルールのスライスを含めて複製
+func copyPack(pack *domain.RulePack) *domain.RulePack {
+	cp := *pack
+	cp.Rules = append([]domain.Rule{}, pack.Rules...)
+	return &cp
+}
+
+func (r *RulePackRepository) Create(pack *domain.RulePack) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.packs[pack.Name]; ok {
+		return conflict()
+	}
+	r.s.packs[pack.Name] = copyPack(pack)
+	return nil
+}
+
+func (r *RulePackRepository) GetByName(name string) (*domain.RulePack, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	pack, ok := r.s.packs[name]
+	if !ok {
+		return nil, notFound()
+	}
+	return copyPack(pack), nil
+}
+
+func (r *RulePackRepository) GetAll() ([]*domain.RulePack, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	packs := make([]*domain.RulePack, 0, len(r.s.packs))
+	for _, pack := range r.s.packs {
+		packs = append(packs, copyPack(pack))
+	}
+	sort.Slice(packs, func(i, j int) bool { return packs[i].Name < packs[j].Name })
+	return packs, nil
+}
+
+func (r *RulePackRepository) Update(pack *domain.RulePack) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.packs[pack.Name]; !ok {
+		return notFound()
+	}
+	r.s.packs[pack.Name] = copyPack(pack)
+	return nil
+}
+
+func (r *RulePackRepository) Delete(name string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	delete(r.s.packs, name)
+	for projectID, attached := range r.s.attached {
+		for i, a := range attached {
+			if a.Pack == name {
+				r.s.attached[projectID] = append(attached[:i:i], attached[i+1:]...)
+				break
+			}
+		}
+	}
+	return nil
+}
+
+func (r *RulePackRepository) GetByProject(projectID string) ([]*domain.ProjectRulePack, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	attached := make([]*domain.ProjectRulePack, 0, len(r.s.attached[projectID]))
+	for _, a := range r.s.attached[projectID] {
+		cp := *a
+		attached = append(attached, &cp)
+	}
+	sort.Slice(attached, func(i, j int) bool {
+		if attached[i].Priority != attached[j].Priority {
+			return attached[i].Priority > attached[j].Priority
+		}
+		return attached[i].Pack < attached[j].Pack
+	})
+	return attached, nil
+}
+
+func (r *RulePackRepository) GetProjects(name string) ([]string, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	var projectIDs []string
+	for projectID, attached := range r.s.attached {
+		for _, a := range attached {
+			if a.Pack == name {
+				projectIDs = append(projectIDs, projectID)
+			}
+		}
+	}
+	sort.Strings(projectIDs)
+	return projectIDs, nil
+}
+
+func (r *RulePackRepository) Attach(attachment *domain.ProjectRulePack) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	if _, ok := r.s.packs[attachment.Pack]; !ok {
+		return notFound()
+	}
+	for _, a := range r.s.attached[attachment.ProjectID] {
+		if a.Pack == attachment.Pack {
+			a.Priority = attachment.Priority
+			return nil
+		}
+	}
+	cp := *attachment
+	r.s.attached[cp.ProjectID] = append(r.s.attached[cp.ProjectID], &cp)
+	return nil
+}
+
+func (r *RulePackRepository) Detach(projectID, name string) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	attached := r.s.attached[projectID]
+	for i, a := range attached {
+		if a.Pack == name {
+			r.s.attached[projectID] = append(attached[:i:i], attached[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+// ViolationRepository 検証で検出した違反をメモリ上に記録する
+type ViolationRepository struct{ s *Store }
+
+func (r *ViolationRepository) Record(projectID, filePath string, issues []domain.ValidationIssue) error {
+	r.s.mu.Lock()
+	defer r.s.mu.Unlock()
+	now := time.Now()
+	for _, issue := range issues {
+		r.s.violations = append(r.s.violations, violation{projectID: projectID, severity: issue.Severity, at: now})
+	}
+	if over := len(r.s.violations) - maxViolations; over > 0 {
+		r.s.violations = append(r.s.violations[:0:0], r.s.violations[over:]...)
+	}
+	return nil
+}
+
+func (r *ViolationRepository) CountBySeverity(projectID string, since time.Time) (map[string]int, error) {
+	r.s.mu.RLock()
+	defer r.s.mu.RUnlock()
+	counts := map[string]int{}
+	for _, v := range r.s.violations {
+		if v.projectID == projectID && v.at.After(since) {
+			counts[v.severity]++
+		}
+	}
+	return counts, nil
+}
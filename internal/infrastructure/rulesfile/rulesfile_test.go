@@ -0,0 +1,197 @@
+package rulesfile
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+func TestParseLegacyLayout(t *testing.T) {
+	store, err := Parse([]byte(`{
+		"web-app": {
+			"project_id": "web-app",
+			"rules": [
+				{"id": "no-console-log", "name": "No Console Log", "severity": "warning", "pattern": "console\\.log", "message": "m"},
+				{"id": "off", "pattern": "x", "is_active": false}
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := store.Projects().GetByID("web-app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Name != "web-app" || !project.ApplyGlobalRules {
+		t.Errorf("project = %+v, want name defaulted and global rules applied", project)
+	}
+
+	rules, _ := store.Rules().GetByProjectID("web-app")
+	if len(rules) != 2 {
+		t.Fatalf("got %d rules, want 2", len(rules))
+	}
+	if rules[0].RuleID != "no-console-log" || rules[0].ProjectID != "web-app" || !rules[0].IsActive {
+		t.Errorf("rule = %+v", rules[0])
+	}
+	if rules[1].IsActive {
+		t.Errorf("rule %q should be inactive", rules[1].RuleID)
+	}
+
+	if _, err := store.Projects().GetByID("missing"); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("GetByID(missing) error = %v, want not found", err)
+	}
+}
+
+func TestParseProjectsAndGlobalRules(t *testing.T) {
+	store, err := Parse([]byte(`{
+		"projects": {
+			"api": {"language": "go", "rules": [{"rule_id": "no-todo", "pattern": "TODO"}]}
+		},
+		"global_rules": {
+			"go": [
+				{"id": "no-println", "matcher_kind": "go_ast", "pattern": "call:fmt.Println"},
+				{"id": "disabled", "pattern": "x", "is_active": false}
+			]
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	project, err := store.Projects().GetByID("api")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Language != "go" {
+		t.Errorf("language = %q, want go", project.Language)
+	}
+
+	globals, _ := store.GlobalRules().GetByLanguage("go")
+	if len(globals) != 1 || globals[0].RuleID != "no-println" || globals[0].Kind() != "go_ast" {
+		t.Errorf("global rules = %+v, want only the active go_ast rule", globals)
+	}
+	languages, _ := store.GlobalRules().GetAllLanguages()
+	if len(languages) != 1 || languages[0] != "go" {
+		t.Errorf("languages = %v", languages)
+	}
+}
+
+func TestParseGlobalRuleOverrides(t *testing.T) {
+	store, err := Parse([]byte(`{"projects": {"legacy": {"language": "go", "rules": [], "global_rule_overrides": [
+		{"rule_id": "no-panic", "disabled": true},
+		{"language": "python", "rule_id": "no-print", "severity": "info"}
+	]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	overrides, _ := store.GlobalRuleOverrides().GetByProject("legacy")
+	if len(overrides) != 2 || overrides[0].Language != "go" || !overrides[0].Disabled || overrides[1].Severity != "info" {
+		t.Errorf("overrides = %+v, want language defaulted to the project's", overrides)
+	}
+
+	repo := store.GlobalRuleOverrides()
+	if err := repo.Save(&domain.GlobalRuleOverride{ProjectID: "legacy", Language: "go", RuleID: "no-panic", Severity: "warning"}); err != nil {
+		t.Fatal(err)
+	}
+	if o, err := repo.Get("legacy", "go", "no-panic"); err != nil || o.Disabled || o.Severity != "warning" {
+		t.Errorf("saved override = %+v, %v; want replaced", o, err)
+	}
+	_ = store.Projects().Delete("legacy")
+	if overrides, _ := repo.GetByProject("legacy"); len(overrides) != 0 {
+		t.Errorf("overrides kept after deleting the project: %+v", overrides)
+	}
+}
+
+func TestParseRulePacks(t *testing.T) {
+	store, err := Parse([]byte(`{
+		"projects": {"web": {"rules": [], "rule_packs": [{"name": "base"}, {"name": "strict", "priority": 5}]}},
+		"rule_packs": {
+			"base": {"description": "d", "rules": [{"id": "no-todo", "name": "No TODO", "pattern": "TODO", "language": "go"}]},
+			"strict": {"rules": []}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	pack, err := store.RulePacks().GetByName("base")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pack.Rules) != 1 || pack.Rules[0].Pack != "base" || pack.Rules[0].Language != "go" || !pack.Rules[0].IsActive {
+		t.Errorf("pack = %+v", pack)
+	}
+	attached, _ := store.RulePacks().GetByProject("web")
+	if len(attached) != 2 || attached[0].Pack != "strict" {
+		t.Errorf("attached = %+v, want strict (priority 5) first", attached)
+	}
+
+	_ = store.RulePacks().Delete("strict")
+	if projects, _ := store.RulePacks().GetProjects("strict"); len(projects) != 0 {
+		t.Errorf("deleted pack still attached to %v", projects)
+	}
+	if _, err := Parse([]byte(`{"projects": {"web": {"rule_packs": [{"name": "missing"}]}}}`)); err == nil {
+		t.Error("expected an error for an unknown rule pack")
+	}
+}
+
+func TestParseProjectLanguages(t *testing.T) {
+	store, err := Parse([]byte(`{"projects": {"mono": {"rules": [], "languages": [
+		{"language": "go", "paths": ["backend/**"]},
+		{"language": "typescript", "paths": ["frontend/**"]}
+	]}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	project, err := store.Projects().GetByID("mono")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project.Language != "go" || len(project.Languages) != 2 || project.Languages[1].Paths[0] != "frontend/**" {
+		t.Errorf("project = %+v", project)
+	}
+	if projects, _ := store.Projects().GetByLanguage("typescript"); len(projects) != 1 {
+		t.Errorf("projects for typescript = %+v, want the secondary language to match", projects)
+	}
+	if _, err := Parse([]byte(`{"projects": {"p": {"languages": [{"paths": ["x/**"]}]}}}`)); err == nil {
+		t.Error("expected an error for a language entry without language")
+	}
+}
+
+func TestParseRejectsRuleWithoutID(t *testing.T) {
+	if _, err := Parse([]byte(`{"p": {"rules": [{"pattern": "x"}]}}`)); err == nil {
+		t.Error("expected an error for a rule without id")
+	}
+}
+
+func TestParseRejectsUnknownAccessLevel(t *testing.T) {
+	if _, err := Parse([]byte(`{"projects": {"p": {"access_level": "team", "rules": []}}}`)); err == nil {
+		t.Error("expected an error for an unknown access_level")
+	}
+}
+
+func TestViolationsCountBySeverity(t *testing.T) {
+	store, err := Parse([]byte(`{}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	violations := store.Violations()
+	since := time.Now().Add(-time.Minute)
+	_ = violations.Record("web", "a.go", []domain.ValidationIssue{{Severity: "error"}, {Severity: "warning"}, {Severity: "error"}})
+	_ = violations.Record("other", "b.go", []domain.ValidationIssue{{Severity: "error"}})
+
+	counts, err := violations.CountBySeverity("web", since)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if counts["error"] != 2 || counts["warning"] != 1 || len(counts) != 2 {
+		t.Errorf("counts = %v", counts)
+	}
+	if counts, _ := violations.CountBySeverity("web", time.Now().Add(time.Minute)); len(counts) != 0 {
+		t.Errorf("counts after window = %v", counts)
+	}
+}
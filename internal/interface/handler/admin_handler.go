@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
@@ -11,18 +14,6 @@ import (
 	"github.com/gin-gonic/gin"
 )
 
-func hasPerm(c *gin.Context, key string) bool {
-	v, ok := c.Get("permissions")
-	if !ok || v == nil {
-		return false
-	}
-	m, ok := v.(map[string]bool)
-	if !ok || m == nil {
-		return false
-	}
-	return m[key]
-}
-
 type AdminHandler struct {
 	userRepo          domain.UserRepository
 	projectRepo       domain.ProjectRepository
@@ -33,6 +24,8 @@ type AdminHandler struct {
 	projectUseCase    *usecase.ProjectUseCase
 	ruleUseCase       *usecase.RuleUseCase
 	globalRuleUseCase *usecase.GlobalRuleUseCase
+	apiKeyUseCase     *usecase.APIKeyUseCase
+	authTokenUseCase  *usecase.AuthTokenUseCase
 }
 
 type AdminStats struct {
@@ -55,14 +48,43 @@ type AdminUser struct {
 	LastLogin time.Time `json:"lastLogin"`
 }
 
+// AdminApiKey 管理画面向けのAPIキー
+// Key は作成時のみキー全体を返し、一覧では Prefix 以降を伏せた表示用の値になる
 type AdminApiKey struct {
-	ID          int       `json:"id"`
-	Name        string    `json:"name"`
-	Key         string    `json:"key"`
-	AccessLevel string    `json:"accessLevel"`
-	Status      string    `json:"status"`
-	CreatedAt   time.Time `json:"createdAt"`
-	LastUsed    time.Time `json:"lastUsed"`
+	ID          int      `json:"id"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Prefix      string   `json:"prefix"`
+	Key         string   `json:"key"`
+	AccessLevel string   `json:"accessLevel"`
+	ProjectIDs  []string `json:"projectIds"`
+	Status      string   `json:"status"` // active | inactive | expired
+	ExpiresAt   string   `json:"expiresAt"`
+	CreatedAt   string   `json:"createdAt"`
+	LastUsed    string   `json:"lastUsed"`
+}
+
+func newAdminApiKey(k *domain.APIKey, now time.Time) AdminApiKey {
+	out := AdminApiKey{
+		ID: k.ID, Name: k.Name, Description: k.Description, Prefix: k.Prefix, Key: k.Prefix + "_…",
+		AccessLevel: k.AccessLevel, ProjectIDs: k.ProjectIDs, Status: "active", CreatedAt: k.CreatedAt.Format(time.RFC3339),
+	}
+	if out.ProjectIDs == nil {
+		out.ProjectIDs = []string{}
+	}
+	switch {
+	case !k.IsActive:
+		out.Status = "inactive"
+	case !k.Usable(now):
+		out.Status = "expired"
+	}
+	if k.ExpiresAt != nil {
+		out.ExpiresAt = k.ExpiresAt.Format(time.RFC3339)
+	}
+	if k.LastUsedAt != nil {
+		out.LastUsed = k.LastUsedAt.Format(time.RFC3339)
+	}
+	return out
 }
 
 type McpStats struct {
@@ -79,28 +101,52 @@ type SystemLog struct {
 }
 
 func NewAdminHandler(userRepo domain.UserRepository, projectRepo domain.ProjectRepository, ruleRepo domain.RuleRepository, globalRuleRepo domain.GlobalRuleRepository, ruleOptionRepo domain.RuleOptionRepository, roleRepo domain.RoleRepository) *AdminHandler {
-	projectUseCase := usecase.NewProjectUseCase(projectRepo)
-	ruleUseCase := usecase.NewRuleUseCase(ruleRepo, globalRuleRepo, projectRepo)
-	globalRuleUseCase := usecase.NewGlobalRuleUseCase(globalRuleRepo)
-
 	return &AdminHandler{
-		userRepo:          userRepo,
-		projectRepo:       projectRepo,
-		ruleRepo:          ruleRepo,
-		globalRuleRepo:    globalRuleRepo,
-		ruleOptionRepo:    ruleOptionRepo,
-		roleRepo:          roleRepo,
-		projectUseCase:    projectUseCase,
-		ruleUseCase:       ruleUseCase,
-		globalRuleUseCase: globalRuleUseCase,
+		userRepo:       userRepo,
+		projectRepo:    projectRepo,
+		ruleRepo:       ruleRepo,
+		globalRuleRepo: globalRuleRepo,
+		ruleOptionRepo: ruleOptionRepo,
+		roleRepo:       roleRepo,
 	}
 }
 
-func (h *AdminHandler) GetStats(c *gin.Context) {
-	if role, ok := c.Get("userRole"); !ok || role != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
-		return
+// SetRuleUseCases 一括エクスポート・インポートで使うユースケースを注入（未設定の場合は Database not available を返す）
+// RESTやMCPと同じものを渡し、ルールエンジンのキャッシュ破棄・MCPセッションへの通知・上書きやパックの解決を共有する
+func (h *AdminHandler) SetRuleUseCases(projectUseCase *usecase.ProjectUseCase, ruleUseCase *usecase.RuleUseCase, globalRuleUseCase *usecase.GlobalRuleUseCase) {
+	h.projectUseCase = projectUseCase
+	h.ruleUseCase = ruleUseCase
+	h.globalRuleUseCase = globalRuleUseCase
+}
+
+// bulkAvailable 一括エクスポート・インポートに必要なユースケースが注入されているか
+func (h *AdminHandler) bulkAvailable(c *gin.Context) bool {
+	if h.projectUseCase == nil || h.ruleUseCase == nil || h.globalRuleUseCase == nil {
+		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
+		return false
+	}
+	return true
+}
+
+// SetAPIKeyUseCase APIキー管理を注入（未設定の場合は Database not available を返す）
+func (h *AdminHandler) SetAPIKeyUseCase(uc *usecase.APIKeyUseCase) {
+	h.apiKeyUseCase = uc
+}
+
+// SetAuthTokenUseCase ユーザーの無効化・削除時にセッションを失効させるため注入（未設定の場合はトークンの期限まで有効）
+func (h *AdminHandler) SetAuthTokenUseCase(uc *usecase.AuthTokenUseCase) {
+	h.authTokenUseCase = uc
+}
+
+// revokeSessions ユーザーのリフレッシュトークンと期限内のアクセストークンを失効させる
+func (h *AdminHandler) revokeSessions(userID int) error {
+	if h.authTokenUseCase == nil {
+		return nil
 	}
+	return h.authTokenUseCase.RevokeUser(userID, time.Now())
+}
+
+func (h *AdminHandler) GetStats(c *gin.Context) {
 	if h.userRepo == nil || h.projectRepo == nil || h.ruleRepo == nil {
 		stats := AdminStats{
 			TotalUsers:     3,
@@ -157,10 +203,6 @@ func (h *AdminHandler) GetStats(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetUsers(c *gin.Context) {
-	if !hasPerm(c, "manage_users") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_users required", nil)
-		return
-	}
 	if h.userRepo == nil {
 		users := []AdminUser{
 			{ID: 1, Username: "admin", Email: "admin@rulemcp.com", FullName: "System Administrator", Role: "admin", IsActive: true, LastLogin: time.Now().Add(-time.Hour)},
@@ -185,23 +227,26 @@ func (h *AdminHandler) GetUsers(c *gin.Context) {
 	c.JSON(http.StatusOK, adminUsers)
 }
 
+// GetApiKeys APIキーの一覧（?prefix= で前方一致検索。キー本体は返さない）
 func (h *AdminHandler) GetApiKeys(c *gin.Context) {
-	if role, ok := c.Get("userRole"); !ok || role != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
+	if h.apiKeyUseCase == nil {
+		c.JSON(http.StatusOK, []AdminApiKey{})
+		return
+	}
+	keys, err := h.apiKeyUseCase.ListAPIKeys(c.Query("prefix"))
+	if err != nil {
+		httpx.JSONFromError(c, err)
 		return
 	}
-	apiKeys := []AdminApiKey{
-		{ID: 1, Name: "Admin API Key", Key: "admin_key_123", AccessLevel: "admin", Status: "active", CreatedAt: time.Now().Add(-24 * time.Hour), LastUsed: time.Now().Add(-time.Hour)},
-		{ID: 2, Name: "User API Key", Key: "user_key_456", AccessLevel: "user", Status: "expired", CreatedAt: time.Now().Add(-48 * time.Hour), LastUsed: time.Now().Add(-2 * time.Hour)},
+	now := time.Now()
+	out := make([]AdminApiKey, len(keys))
+	for i, k := range keys {
+		out[i] = newAdminApiKey(k, now)
 	}
-	c.JSON(http.StatusOK, apiKeys)
+	c.JSON(http.StatusOK, out)
 }
 
 func (h *AdminHandler) GetMcpStats(c *gin.Context) {
-	if role, ok := c.Get("userRole"); !ok || role != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
-		return
-	}
 	stats := []McpStats{
 		{Method: "getRules", Count: 1234, LastUsed: "2分前", Status: "正常"},
 		{Method: "validateCode", Count: 567, LastUsed: "5分前", Status: "正常"},
@@ -210,10 +255,6 @@ func (h *AdminHandler) GetMcpStats(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetSystemLogs(c *gin.Context) {
-	if role, ok := c.Get("userRole"); !ok || role != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
-		return
-	}
 	logs := []SystemLog{
 		{Timestamp: time.Now().Add(-5 * time.Minute), Level: "INFO", Message: "User 'admin' logged in successfully"},
 		{Timestamp: time.Now().Add(-10 * time.Minute), Level: "WARN", Message: "API key 'user_key_456' expired"},
@@ -224,10 +265,6 @@ func (h *AdminHandler) GetSystemLogs(c *gin.Context) {
 }
 
 func (h *AdminHandler) CreateUser(c *gin.Context) {
-	if !hasPerm(c, "manage_users") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_users required", nil)
-		return
-	}
 	var req struct {
 		Username string `json:"username" binding:"required"`
 		Email    string `json:"email" binding:"required,email"`
@@ -261,10 +298,6 @@ func (h *AdminHandler) CreateUser(c *gin.Context) {
 }
 
 func (h *AdminHandler) UpdateUser(c *gin.Context) {
-	if !hasPerm(c, "manage_users") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_users required", nil)
-		return
-	}
 	userID := c.Param("id")
 	if userID == "" {
 		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "User ID is required", nil)
@@ -304,10 +337,14 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 	if req.FullName != "" {
 		user.FullName = req.FullName
 	}
+	// ロールはアクセストークンに含まれるため、変更時も発行済みのセッションを失効させる
+	revoke := false
 	if req.Role != "" {
+		revoke = revoke || req.Role != user.Role
 		user.Role = req.Role
 	}
 	if req.IsActive != nil {
+		revoke = revoke || (user.IsActive && !*req.IsActive)
 		user.IsActive = *req.IsActive
 	}
 	user.UpdatedAt = time.Now()
@@ -315,15 +352,17 @@ func (h *AdminHandler) UpdateUser(c *gin.Context) {
 		httpx.JSONError(c, http.StatusInternalServerError, httpx.CodeInternal, "Failed to update user", nil)
 		return
 	}
+	if revoke {
+		if err := h.revokeSessions(user.ID); err != nil {
+			httpx.JSONFromError(c, err)
+			return
+		}
+	}
 	adminUser := AdminUser{ID: user.ID, Username: user.Username, Email: user.Email, FullName: user.FullName, Role: user.Role, IsActive: user.IsActive, LastLogin: user.UpdatedAt}
 	c.JSON(http.StatusOK, adminUser)
 }
 
 func (h *AdminHandler) DeleteUser(c *gin.Context) {
-	if !hasPerm(c, "manage_users") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_users required", nil)
-		return
-	}
 	userID := c.Param("id")
 	if userID == "" {
 		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "User ID is required", nil)
@@ -342,6 +381,11 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 		httpx.JSONError(c, http.StatusNotFound, httpx.CodeNotFound, "User not found", nil)
 		return
 	}
+	// リフレッシュトークンは削除で消えるため、先にアクセストークンを拒否リストへ追加する
+	if err := h.revokeSessions(id); err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
 	if err := h.userRepo.Delete(id); err != nil {
 		httpx.JSONError(c, http.StatusInternalServerError, httpx.CodeInternal, "Failed to delete user", nil)
 		return
@@ -349,37 +393,122 @@ func (h *AdminHandler) DeleteUser(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "User deleted successfully"})
 }
 
+// GenerateApiKey APIキーを発行（キー全体はこのレスポンスでのみ返す）
 func (h *AdminHandler) GenerateApiKey(c *gin.Context) {
-	if role, ok := c.Get("userRole"); !ok || role != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
+	var req struct {
+		Name        string     `json:"name" binding:"required"`
+		Description string     `json:"description"`
+		AccessLevel string     `json:"accessLevel" binding:"required"`
+		ExpiresAt   *time.Time `json:"expiresAt"`
+		ProjectIDs  []string   `json:"projectIds"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+	if h.apiKeyUseCase == nil {
+		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
+		return
+	}
+	createdBy := c.GetString(ContextKeyUsername)
+	if createdBy == "" {
+		createdBy = "admin"
+	}
+	key, token, err := h.apiKeyUseCase.CreateAPIKey(req.Name, req.Description, req.AccessLevel, req.ProjectIDs, req.ExpiresAt, createdBy)
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	out := newAdminApiKey(key, time.Now())
+	out.Key = token
+	c.JSON(http.StatusCreated, out)
+}
+
+// UpdateApiKey APIキーの名前・説明・有効状態・有効期限・プロジェクトスコープを更新
+// expiresAt に空文字を指定すると有効期限を削除する
+func (h *AdminHandler) UpdateApiKey(c *gin.Context) {
+	id, ok := apiKeyIDParam(c)
+	if !ok {
 		return
 	}
 	var req struct {
-		Name        string `json:"name" binding:"required"`
-		AccessLevel string `json:"accessLevel" binding:"required"`
+		Name        *string   `json:"name"`
+		Description *string   `json:"description"`
+		IsActive    *bool     `json:"isActive"`
+		ExpiresAt   *string   `json:"expiresAt"`
+		ProjectIDs  *[]string `json:"projectIds"`
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
 		return
 	}
-	apiKey := fmt.Sprintf("%s_%d_%s", req.AccessLevel, time.Now().Unix(), generateRandomString(16))
-	newApiKey := AdminApiKey{ID: int(time.Now().Unix()), Name: req.Name, Key: apiKey, AccessLevel: req.AccessLevel, Status: "active", CreatedAt: time.Now(), LastUsed: time.Time{}}
-	c.JSON(http.StatusCreated, newApiKey)
+	if h.apiKeyUseCase == nil {
+		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
+		return
+	}
+	update := usecase.APIKeyUpdate{Name: req.Name, Description: req.Description, IsActive: req.IsActive, ProjectIDs: req.ProjectIDs}
+	if req.ExpiresAt != nil {
+		if *req.ExpiresAt == "" {
+			update.ClearExpiry = true
+		} else {
+			t, err := time.Parse(time.RFC3339, *req.ExpiresAt)
+			if err != nil {
+				httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "expiresAt はRFC3339形式で指定してください", err.Error())
+				return
+			}
+			update.ExpiresAt = &t
+		}
+	}
+	key, err := h.apiKeyUseCase.UpdateAPIKey(id, update)
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, newAdminApiKey(key, time.Now()))
+}
+
+// RevokeApiKey APIキーを無効化（記録は残す）
+func (h *AdminHandler) RevokeApiKey(c *gin.Context) {
+	id, ok := apiKeyIDParam(c)
+	if !ok {
+		return
+	}
+	if h.apiKeyUseCase == nil {
+		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
+		return
+	}
+	if err := h.apiKeyUseCase.RevokeAPIKey(id); err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "API Key revoked"})
 }
 
 func (h *AdminHandler) DeleteApiKey(c *gin.Context) {
-	if role, ok := c.Get("userRole"); !ok || role != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
+	id, ok := apiKeyIDParam(c)
+	if !ok {
+		return
+	}
+	if h.apiKeyUseCase == nil {
+		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
 		return
 	}
-	keyID := c.Param("id")
-	if keyID == "" {
-		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "API Key ID is required", nil)
+	if err := h.apiKeyUseCase.DeleteAPIKey(id); err != nil {
+		httpx.JSONFromError(c, err)
 		return
 	}
 	c.JSON(http.StatusOK, gin.H{"message": "API Key deleted successfully"})
 }
 
+func apiKeyIDParam(c *gin.Context) (int, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "Invalid API Key ID", nil)
+		return 0, false
+	}
+	return id, true
+}
+
 // ルールオプション
 func (h *AdminHandler) GetRuleOptions(c *gin.Context) {
 	kind := c.Query("kind")
@@ -400,10 +529,6 @@ func (h *AdminHandler) GetRuleOptions(c *gin.Context) {
 }
 
 func (h *AdminHandler) AddRuleOption(c *gin.Context) {
-	if !hasPerm(c, "manage_rules") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
-		return
-	}
 	var req struct {
 		Kind  string `json:"kind" binding:"required"`
 		Value string `json:"value" binding:"required"`
@@ -428,10 +553,6 @@ func (h *AdminHandler) AddRuleOption(c *gin.Context) {
 }
 
 func (h *AdminHandler) DeleteRuleOption(c *gin.Context) {
-	if !hasPerm(c, "manage_rules") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
-		return
-	}
 	var req struct {
 		Kind  string `json:"kind" binding:"required"`
 		Value string `json:"value" binding:"required"`
@@ -457,10 +578,6 @@ func (h *AdminHandler) DeleteRuleOption(c *gin.Context) {
 
 // ロール管理
 func (h *AdminHandler) GetRoles(c *gin.Context) {
-	if !hasPerm(c, "manage_roles") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_roles required", nil)
-		return
-	}
 	if h.roleRepo == nil {
 		c.JSON(http.StatusOK, []domain.Role{})
 		return
@@ -475,10 +592,6 @@ func (h *AdminHandler) GetRoles(c *gin.Context) {
 }
 
 func (h *AdminHandler) CreateRole(c *gin.Context) {
-	if !hasPerm(c, "manage_roles") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_roles required", nil)
-		return
-	}
 	if h.roleRepo == nil {
 		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Role repository not available", nil)
 		return
@@ -505,10 +618,6 @@ func (h *AdminHandler) CreateRole(c *gin.Context) {
 }
 
 func (h *AdminHandler) UpdateRole(c *gin.Context) {
-	if !hasPerm(c, "manage_roles") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_roles required", nil)
-		return
-	}
 	if h.roleRepo == nil {
 		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Role repository not available", nil)
 		return
@@ -539,10 +648,6 @@ func (h *AdminHandler) UpdateRole(c *gin.Context) {
 }
 
 func (h *AdminHandler) DeleteRole(c *gin.Context) {
-	if !hasPerm(c, "manage_roles") {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_roles required", nil)
-		return
-	}
 	if h.roleRepo == nil {
 		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Role repository not available", nil)
 		return
@@ -559,15 +664,6 @@ func (h *AdminHandler) DeleteRole(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"message": "Role deleted"})
 }
 
-func generateRandomString(length int) string {
-	const charset = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	result := make([]byte, length)
-	for i := range result {
-		result[i] = charset[time.Now().UnixNano()%int64(len(charset))]
-	}
-	return string(result)
-}
-
 // BulkExportRequest 一括エクスポートリクエスト
 type BulkExportRequest struct {
 	Format string `json:"format"` // json, yaml, csv
@@ -582,19 +678,15 @@ type BulkImportRequest struct {
 
 // BulkExport 一括エクスポート
 func (h *AdminHandler) BulkExport(c *gin.Context) {
+	if !h.bulkAvailable(c) {
+		return
+	}
 	var req BulkExportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "Invalid request data", nil)
 		return
 	}
 
-	// 管理者権限チェック
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
-		return
-	}
-
 	// エクスポートデータの構築
 	exportData := make(map[string]interface{})
 	exportData["exportedAt"] = time.Now().Format(time.RFC3339)
@@ -619,16 +711,16 @@ func (h *AdminHandler) BulkExport(c *gin.Context) {
 		}
 	}
 
-	// グローバルルールの取得
-	if req.Scope == "all" || req.Scope == "global" {
-		// 主要な言語のグローバルルールを取得
-		languages := []string{"javascript", "typescript", "python", "go", "java", "cpp", "csharp"}
-		globalRules := make(map[string]interface{})
-		for _, lang := range languages {
-			rules, err := h.globalRuleUseCase.GetGlobalRules(lang)
-			if err == nil && len(rules) > 0 {
-				globalRules[lang] = rules
-			}
+	// グローバルルールの取得（登録されている全言語。言語ごとに一覧を持つ）
+	if (req.Scope == "all" || req.Scope == "global") && h.globalRuleRepo != nil {
+		rules, err := h.globalRuleUseCase.ExportGlobalRules("", nil)
+		if err != nil {
+			httpx.JSONFromError(c, err)
+			return
+		}
+		globalRules := make(map[string][]*domain.GlobalRule)
+		for _, rule := range rules {
+			globalRules[rule.Language] = append(globalRules[rule.Language], rule)
 		}
 		if len(globalRules) > 0 {
 			exportData["globalRules"] = globalRules
@@ -656,18 +748,29 @@ func (h *AdminHandler) BulkExport(c *gin.Context) {
 
 // BulkImport 一括インポート
 func (h *AdminHandler) BulkImport(c *gin.Context) {
+	if !h.bulkAvailable(c) {
+		return
+	}
 	var req BulkImportRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
 		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "Invalid request data", nil)
 		return
 	}
 
-	// 管理者権限チェック
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
+	// 不正なパターンのプロジェクトルールを含む場合はインポート全体を拒否
+	if err := usecase.ValidateRules(bulkImportRules(req.Data)); err != nil {
+		httpx.JSONFromError(c, err)
 		return
 	}
+	// globalRules の形式が不正な場合は何も取り込まない
+	var globalRules []domain.GlobalRule
+	if data, ok := req.Data["globalRules"]; ok {
+		var err error
+		if globalRules, err = bulkGlobalRules(data); err != nil {
+			httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "globalRules の形式が不正です", err.Error())
+			return
+		}
+	}
 
 	importedCount := 0
 	skippedCount := 0
@@ -688,12 +791,13 @@ func (h *AdminHandler) BulkImport(c *gin.Context) {
 					projectLanguage = "general"
 				}
 				applyGlobalRules, _ := projectInfo["apply_global_rules"].(bool)
+				accessLevel, _ := projectInfo["access_level"].(string)
 
 				// プロジェクトが存在しない場合は作成
 				_, err := h.projectUseCase.GetByID(projectID)
 				if err != nil {
 					// プロジェクトを作成
-					err = h.projectUseCase.CreateProject(projectID, projectName, projectDescription, projectLanguage, applyGlobalRules)
+					err = h.projectUseCase.CreateProject(projectID, projectName, projectDescription, projectLanguage, nil, applyGlobalRules, accessLevel, c.GetString(ContextKeyUsername))
 					if err != nil {
 						errors = append(errors, fmt.Sprintf("Failed to create project %s: %v", projectID, err))
 						continue
@@ -711,6 +815,7 @@ func (h *AdminHandler) BulkImport(c *gin.Context) {
 								severity, _ := rule["severity"].(string)
 								pattern, _ := rule["pattern"].(string)
 								message, _ := rule["message"].(string)
+								replacement, _ := rule["replacement"].(string)
 
 								// 重複チェック
 								if !req.Overwrite {
@@ -722,7 +827,7 @@ func (h *AdminHandler) BulkImport(c *gin.Context) {
 								}
 
 								// ルール作成
-								err := h.ruleUseCase.CreateRule(projectID, ruleID, name, description, ruleType, severity, pattern, message)
+								err := h.ruleUseCase.CreateRule(projectID, ruleID, name, description, ruleType, severity, pattern, message, replacement, bulkRuleMatcher(rule))
 								if err != nil {
 									errors = append(errors, fmt.Sprintf("Failed to import rule %s: %v", ruleID, err))
 									continue
@@ -736,23 +841,95 @@ func (h *AdminHandler) BulkImport(c *gin.Context) {
 		}
 	}
 
-	// グローバルルールのインポート
-	if globalRules, ok := req.Data["globalRules"].([]interface{}); ok {
-		for _, ruleData := range globalRules {
-			if _, ok := ruleData.(map[string]interface{}); ok {
-				// グローバルルールのインポート処理
-				// 簡易実装（実際のプロダクションでは適切な実装が必要）
-				importedCount++
-			}
+	// グローバルルールのインポート（上書き・スキップは overwrite に従い、ルールごとの結果を返す）
+	globalResults := []usecase.GlobalRuleImportResult{}
+	if globalRules != nil && h.globalRuleRepo != nil {
+		report, err := h.globalRuleUseCase.ImportGlobalRules("", globalRules, req.Overwrite)
+		if err != nil {
+			httpx.JSONFromError(c, err)
+			return
 		}
+		importedCount += report.Imported()
+		skippedCount += report.Skipped
+		errors = append(errors, importErrors(report)...)
+		globalResults = report.Results
 	}
 
 	c.JSON(http.StatusOK, gin.H{
-		"message":       "Bulk import completed",
-		"importedCount": importedCount,
-		"skippedCount":  skippedCount,
-		"errorCount":    len(errors),
-		"errors":        errors,
-		"importedAt":    time.Now().Format(time.RFC3339),
+		"message":           "Bulk import completed",
+		"importedCount":     importedCount,
+		"skippedCount":      skippedCount,
+		"errorCount":        len(errors),
+		"errors":            errors,
+		"globalRuleResults": globalResults,
+		"importedAt":        time.Now().Format(time.RFC3339),
 	})
 }
+
+// bulkGlobalRules 一括インポートデータの globalRules を取り出す
+// 一括エクスポートの形式（言語ごとの一覧）とルールの一覧の両方を受け付ける（言語ごとの一覧では、言語が空のルールをキーの言語とする）
+func bulkGlobalRules(data interface{}) ([]domain.GlobalRule, error) {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return nil, err
+	}
+	var list []ImportGlobalRule
+	if err := json.Unmarshal(raw, &list); err == nil {
+		return globalRulesFromImport(list), nil
+	}
+	var byLanguage map[string][]ImportGlobalRule
+	if err := json.Unmarshal(raw, &byLanguage); err != nil {
+		return nil, err
+	}
+	languages := make([]string, 0, len(byLanguage))
+	for language := range byLanguage {
+		languages = append(languages, language)
+	}
+	sort.Strings(languages)
+	var rules []domain.GlobalRule
+	for _, language := range languages {
+		for _, rule := range globalRulesFromImport(byLanguage[language]) {
+			if rule.Language == "" {
+				rule.Language = language
+			}
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// bulkImportRules 一括インポートデータ内のプロジェクトルールを収集（キーは "プロジェクトID/ルールID" 形式）
+// グローバルルールは ImportGlobalRules がルールごとに検証し、不正なものを結果に記録する
+func bulkImportRules(data map[string]interface{}) map[string]domain.Rule {
+	rules := map[string]domain.Rule{}
+	if projectRules, ok := data["projectRules"].(map[string]interface{}); ok {
+		for projectID, projectData := range projectRules {
+			projectInfo, ok := projectData.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			ruleList, _ := projectInfo["rules"].([]interface{})
+			for _, ruleData := range ruleList {
+				if rule, ok := ruleData.(map[string]interface{}); ok {
+					ruleID, _ := rule["rule_id"].(string)
+					pattern, _ := rule["pattern"].(string)
+					replacement, _ := rule["replacement"].(string)
+					rules[projectID+"/"+ruleID] = domain.Rule{RuleID: ruleID, Pattern: pattern, Replacement: replacement, RuleMatcher: bulkRuleMatcher(rule)}
+				}
+			}
+		}
+	}
+	return rules
+}
+
+// bulkRuleMatcher 一括インポートデータのルールからマッチャー設定を取り出す
+func bulkRuleMatcher(rule map[string]interface{}) domain.RuleMatcher {
+	var m domain.RuleMatcher
+	m.MatcherKind, _ = rule["matcher_kind"].(string)
+	m.Flags, _ = rule["flags"].(string)
+	m.FileGlob, _ = rule["file_glob"].(string)
+	if limit, ok := rule["limit"].(float64); ok {
+		m.Limit = int(limit)
+	}
+	return m
+}
@@ -0,0 +1,86 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/infrastructure/rulesfile"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func TestBulkImportExportUseSharedUseCases(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	store, err := rulesfile.Parse([]byte(`{
+		"rule_packs": {"baseline": {"rules": [{"id": "no-secrets", "pattern": "api_key"}]}},
+		"projects": {
+			"web": {"language": "go", "rules": [], "rule_packs": [{"name": "baseline"}]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	events := usecase.NewRuleEvents()
+	projectUseCase := usecase.NewProjectUseCase(store.Projects())
+	projectUseCase.SetRuleEvents(events)
+	ruleUseCase := usecase.NewRuleUseCase(store.Rules(), store.GlobalRules(), store.Projects())
+	ruleUseCase.SetRuleEvents(events)
+	ruleUseCase.SetRulePackRepository(store.RulePacks())
+	globalRuleUseCase := usecase.NewGlobalRuleUseCase(store.GlobalRules())
+	globalRuleUseCase.SetRuleEvents(events)
+
+	var changes []usecase.RuleChange
+	events.Subscribe(func(change usecase.RuleChange) { changes = append(changes, change) })
+
+	h := NewAdminHandler(nil, store.Projects(), store.Rules(), store.GlobalRules(), nil, nil)
+	r := gin.New()
+	r.POST("/bulk-export", h.BulkExport)
+	r.POST("/bulk-import", h.BulkImport)
+
+	// ユースケースを注入するまでは使えない
+	if w := sendJSON(r, http.MethodPost, "/bulk-export", `{"scope": "all"}`); w.Code != http.StatusServiceUnavailable {
+		t.Fatalf("export without use cases: status %d, want 503", w.Code)
+	}
+	h.SetRuleUseCases(projectUseCase, ruleUseCase, globalRuleUseCase)
+
+	w := sendJSON(r, http.MethodPost, "/bulk-import", `{"data": {
+		"projectRules": {"web": {"rules": [{"rule_id": "no-todo", "name": "No TODO", "severity": "warning", "pattern": "TODO"}]}},
+		"globalRules": [{"language": "go", "rule_id": "no-panic", "name": "No panic", "pattern": "panic"}]
+	}}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("import status %d: %s", w.Code, w.Body.String())
+	}
+	// 取り込んだルールの変更はMCPセッションへの通知と同じイベントで配信される
+	var notified []string
+	for _, change := range changes {
+		notified = append(notified, change.ProjectID+"/"+change.Language)
+	}
+	sort.Strings(notified)
+	if len(notified) != 2 || notified[0] != "/go" || notified[1] != "web/" {
+		t.Errorf("rule changes = %v, want web project and go global rules", notified)
+	}
+
+	// エクスポートはルールパックを含めて解決したルールを返す
+	w = sendJSON(r, http.MethodPost, "/bulk-export", `{"scope": "projects"}`)
+	var exported struct {
+		ProjectRules map[string]struct {
+			Rules []domain.Rule `json:"rules"`
+		} `json:"projectRules"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &exported); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("export status %d: %s", w.Code, w.Body.String())
+	}
+	packs := map[string]string{}
+	for _, rule := range exported.ProjectRules["web"].Rules {
+		packs[rule.RuleID] = rule.Pack
+	}
+	if pack, ok := packs["no-secrets"]; !ok || pack != "baseline" {
+		t.Errorf("exported rules = %v, want no-secrets from the baseline pack", packs)
+	}
+	if _, ok := packs["no-todo"]; !ok {
+		t.Errorf("exported rules = %v, want the imported no-todo rule", packs)
+	}
+}
@@ -8,6 +8,7 @@ import (
 	"unicode"
 
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/httpx"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt/v5"
@@ -15,9 +16,9 @@ import (
 )
 
 type AuthHandler struct {
-	jwtSecret []byte
-	userRepo  domain.UserRepository
-	roleRepo  domain.RoleRepository
+	tokens   *usecase.AuthTokenUseCase
+	userRepo domain.UserRepository
+	roleRepo domain.RoleRepository
 }
 
 type LoginRequest struct {
@@ -25,10 +26,19 @@ type LoginRequest struct {
 	Password string `json:"password" binding:"required"`
 }
 
+// LoginResponse ログイン・リフレッシュのレスポンス（token は短命のアクセストークン）
 type LoginResponse struct {
-	Token   string `json:"token"`
-	User    User   `json:"user"`
-	Message string `json:"message"`
+	Token            string     `json:"token"`
+	ExpiresAt        time.Time  `json:"expires_at"`
+	RefreshToken     string     `json:"refresh_token,omitempty"`
+	RefreshExpiresAt *time.Time `json:"refresh_expires_at,omitempty"`
+	User             User       `json:"user"`
+	Message          string     `json:"message"`
+}
+
+// RefreshRequest リフレッシュ・ログアウトのリクエスト（ログアウトでは省略可）
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
 }
 
 type User struct {
@@ -38,12 +48,8 @@ type User struct {
 	Role     string `json:"role"`
 }
 
-type Claims struct {
-	UserID   int    `json:"user_id"`
-	Username string `json:"username"`
-	Role     string `json:"role"`
-	jwt.RegisteredClaims
-}
+// Claims アクセストークンのクレーム
+type Claims = usecase.AccessClaims
 
 // ChangePasswordRequest パスワード変更リクエスト
 type ChangePasswordRequest struct {
@@ -58,13 +64,20 @@ type ApproveUserRequest struct {
 }
 
 func NewAuthHandler(jwtSecret string, userRepo domain.UserRepository, roleRepo domain.RoleRepository) *AuthHandler {
+	tokens := usecase.NewAuthTokenUseCase(jwtSecret)
+	tokens.SetUserRepository(userRepo)
 	return &AuthHandler{
-		jwtSecret: []byte(jwtSecret),
-		userRepo:  userRepo,
-		roleRepo:  roleRepo,
+		tokens:   tokens,
+		userRepo: userRepo,
+		roleRepo: roleRepo,
 	}
 }
 
+// SetTokenUseCase トークンの発行・失効を注入（拒否リストを共有するため Authenticator と同じものを渡す）
+func (h *AuthHandler) SetTokenUseCase(uc *usecase.AuthTokenUseCase) {
+	h.tokens = uc
+}
+
 // validatePasswordStrength パスワードの複雑性要件を検証
 func validatePasswordStrength(password string) error {
 	if len(password) < 12 {
@@ -140,43 +153,65 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	// JWTトークンを生成
-	claims := Claims{
-		UserID:   user.ID,
-		Username: user.Username,
-		Role:     user.Role,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(24 * time.Hour)),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			NotBefore: jwt.NewNumericDate(time.Now()),
-		},
+	pair, err := h.tokens.Issue(user, time.Now())
+	if err != nil {
+		httpx.JSONError(c, http.StatusInternalServerError, httpx.CodeInternal, "トークン生成に失敗しました", nil)
+		return
 	}
+	c.JSON(http.StatusOK, newLoginResponse(pair, user, "Login successful"))
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString(h.jwtSecret)
+// Refresh リフレッシュトークンを新しいアクセストークン・リフレッシュトークンに交換（使用したトークンは無効になる）
+func (h *AuthHandler) Refresh(c *gin.Context) {
+	var req RefreshRequest
+	if err := c.ShouldBindJSON(&req); err != nil || req.RefreshToken == "" {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "refresh_token は必須です", nil)
+		return
+	}
+	pair, user, err := h.tokens.Refresh(req.RefreshToken, time.Now())
 	if err != nil {
-		httpx.JSONError(c, http.StatusInternalServerError, httpx.CodeInternal, "トークン生成に失敗しました", nil)
+		httpx.JSONFromError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, newLoginResponse(pair, user, "Token refreshed"))
+}
+
+// Logout アクセストークンを拒否リストに追加し、セッションのリフレッシュトークンを失効させる
+// 認証されていない場合も、ボディの refresh_token があればそのセッションを失効させる
+func (h *AuthHandler) Logout(c *gin.Context) {
+	var req RefreshRequest
+	_ = c.ShouldBindJSON(&req)
 
+	var claims *Claims
+	if c.GetString(ContextKeyAuthMethod) == "jwt" {
+		claims = &Claims{UserID: c.GetInt(ContextKeyUserID)}
+		claims.ID = c.GetString(ContextKeyTokenID)
+		claims.ExpiresAt = jwt.NewNumericDate(c.GetTime(ContextKeyTokenExpiresAt))
+	}
+	if err := h.tokens.Logout(claims, req.RefreshToken, time.Now()); err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
+}
+
+func newLoginResponse(pair *usecase.TokenPair, user *domain.User, message string) LoginResponse {
 	response := LoginResponse{
-		Token: tokenString,
+		Token:        pair.AccessToken,
+		ExpiresAt:    pair.AccessExpiresAt,
+		RefreshToken: pair.RefreshToken,
 		User: User{
 			ID:       user.ID,
 			Username: user.Username,
 			Email:    user.Email,
 			Role:     user.Role,
 		},
-		Message: "Login successful",
+		Message: message,
 	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-func (h *AuthHandler) Logout(c *gin.Context) {
-	// ログアウトは基本的にクライアント側でトークンを削除するだけ
-	// サーバー側では特に何もする必要がない（JWTはステートレス）
-	c.JSON(http.StatusOK, gin.H{"message": "Logout successful"})
+	if pair.RefreshToken != "" {
+		response.RefreshExpiresAt = &pair.RefreshExpiresAt
+	}
+	return response
 }
 
 func (h *AuthHandler) Register(c *gin.Context) {
@@ -241,9 +276,27 @@ func (h *AuthHandler) Register(c *gin.Context) {
 	})
 }
 
+// ValidateToken アクセストークンが有効か確認（署名・期限・拒否リストは認証ミドルウェアで検証済み）
+// ユーザーが無効化・削除されている場合は、トークンの期限内でも無効とする
 func (h *AuthHandler) ValidateToken(c *gin.Context) {
-	// トークン検証の実装（必要に応じて）
-	httpx.JSONError(c, http.StatusNotImplemented, httpx.CodeUnprocessable, "Token validation not implemented yet", nil)
+	if c.GetString(ContextKeyAuthMethod) != "jwt" {
+		httpx.JSONError(c, http.StatusUnauthorized, httpx.CodeUnauthorized, "トークンが無効です", gin.H{"valid": false})
+		return
+	}
+	user := User{ID: c.GetInt(ContextKeyUserID), Username: c.GetString(ContextKeyUsername), Role: c.GetString(ContextKeyUserRole)}
+	if h.userRepo != nil {
+		current, err := h.userRepo.GetByID(user.ID)
+		if err != nil || !current.IsActive {
+			httpx.JSONError(c, http.StatusUnauthorized, httpx.CodeUnauthorized, "トークンが無効です", gin.H{"valid": false})
+			return
+		}
+		user.Email = current.Email
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"valid":      true,
+		"user":       user,
+		"expires_at": c.GetTime(ContextKeyTokenExpiresAt),
+	})
 }
 
 // ChangePassword パスワード変更
@@ -307,13 +360,6 @@ func (h *AuthHandler) ApproveUser(c *gin.Context) {
 		return
 	}
 
-	// 管理者権限チェック
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "管理者権限が必要です", nil)
-		return
-	}
-
 	// ユーザーを取得
 	user, err := h.userRepo.GetByID(req.UserID)
 	if err != nil {
@@ -332,6 +378,11 @@ func (h *AuthHandler) ApproveUser(c *gin.Context) {
 	action := "承認"
 	if !req.Approve {
 		action = "拒否"
+		// 承認を取り消したユーザーのセッションを即座に失効させる
+		if err := h.tokens.RevokeUser(user.ID, time.Now()); err != nil {
+			httpx.JSONFromError(c, err)
+			return
+		}
 	}
 
 	c.JSON(http.StatusOK, gin.H{"message": "ユーザーを" + action + "しました"})
@@ -339,13 +390,6 @@ func (h *AuthHandler) ApproveUser(c *gin.Context) {
 
 // GetPendingUsers 承認待ちユーザー一覧取得
 func (h *AuthHandler) GetPendingUsers(c *gin.Context) {
-	// 管理者権限チェック
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "管理者権限が必要です", nil)
-		return
-	}
-
 	// 非アクティブユーザーを取得
 	users, err := h.userRepo.GetAll()
 	if err != nil {
@@ -373,21 +417,14 @@ func (h *AuthHandler) GetPendingUsers(c *gin.Context) {
 
 // Me 現在のユーザー情報
 func (h *AuthHandler) Me(c *gin.Context) {
-	auth := c.GetHeader("Authorization")
-	if len(auth) <= 7 {
+	// トークンは認証ミドルウェアで検証済み（失効したトークンは jwt として認証されない）
+	if c.GetString(ContextKeyAuthMethod) != "jwt" {
 		httpx.JSONError(c, http.StatusUnauthorized, httpx.CodeUnauthorized, "Unauthorized", nil)
 		return
 	}
-	tokenStr := auth[7:]
-	claims := &Claims{}
-	token, err := jwt.ParseWithClaims(tokenStr, claims, func(token *jwt.Token) (interface{}, error) { return h.jwtSecret, nil })
-	if err != nil || !token.Valid {
-		httpx.JSONError(c, http.StatusUnauthorized, httpx.CodeUnauthorized, "Invalid token", nil)
-		return
-	}
 
 	// データベースから最新のユーザー情報を取得
-	user, err := h.userRepo.GetByID(claims.UserID)
+	user, err := h.userRepo.GetByID(c.GetInt(ContextKeyUserID))
 	if err != nil {
 		httpx.JSONError(c, http.StatusNotFound, httpx.CodeNotFound, "User not found", nil)
 		return
@@ -0,0 +1,267 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/httpx"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+// 認証ミドルウェアがコンテキストに設定するキー
+const (
+	ContextKeyUserRole      = "userRole"
+	ContextKeyPermissions   = "permissions"
+	ContextKeyUserID        = "userID"
+	ContextKeyUsername      = "username"
+	ContextKeyAuthenticated = "authenticated"
+	ContextKeyAuthMethod    = "authMethod" // jwt | api_key
+	ContextKeyAPIKeyID      = "apiKeyID"
+	// ContextKeyTokenID・ContextKeyTokenExpiresAt JWTの jti と有効期限（ログアウト時に拒否リストへ追加する）
+	ContextKeyTokenID        = "tokenID"
+	ContextKeyTokenExpiresAt = "tokenExpiresAt"
+	// ContextKeyProjectScope APIキーで利用できるプロジェクト（[]string。未設定なら制限なし）
+	ContextKeyProjectScope = "projectScope"
+)
+
+// APIKeyHeader APIキーを送るヘッダー
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyTouchInterval 最終使用日時を更新する最小間隔（リクエストごとの書き込みを避ける）
+const apiKeyTouchInterval = time.Minute
+
+// defaultPermissions ロールの権限が見つからない場合のフォールバック
+func defaultPermissions(role string) map[string]bool {
+	perm := map[string]bool{PermissionAdmin: false, PermissionManageUsers: false, PermissionManageRules: false, PermissionManageRoles: false}
+	switch role {
+	case "admin":
+		perm[PermissionAdmin] = true
+		perm[PermissionManageUsers] = true
+		perm[PermissionManageRules] = true
+		perm[PermissionManageRoles] = true
+	case "user":
+		perm[PermissionManageRules] = true
+	}
+	return perm
+}
+
+// Authenticator Bearer JWT と X-API-Key による認証
+type Authenticator struct {
+	tokens          *usecase.AuthTokenUseCase
+	roleRepo        domain.RoleRepository
+	apiKeys         *usecase.APIKeyUseCase
+	onAuthenticated func(username string)
+
+	touchMu   sync.Mutex
+	lastTouch map[int]time.Time
+}
+
+func NewAuthenticator(jwtSecret string, roleRepo domain.RoleRepository) *Authenticator {
+	return &Authenticator{
+		tokens:    usecase.NewAuthTokenUseCase(jwtSecret),
+		roleRepo:  roleRepo,
+		lastTouch: make(map[int]time.Time),
+	}
+}
+
+// SetTokenUseCase JWTの検証を注入（拒否リストを共有するため AuthHandler と同じものを渡す）
+func (a *Authenticator) SetTokenUseCase(uc *usecase.AuthTokenUseCase) {
+	a.tokens = uc
+}
+
+// SetAPIKeyUseCase APIキーの検証を注入（未設定の場合 X-API-Key は無視する）
+func (a *Authenticator) SetAPIKeyUseCase(uc *usecase.APIKeyUseCase) {
+	a.apiKeys = uc
+}
+
+// SetOnAuthenticated 認証に成功したときの通知先を設定（アクティブセッションの追跡など）
+func (a *Authenticator) SetOnAuthenticated(fn func(username string)) {
+	a.onAuthenticated = fn
+}
+
+// Middleware 認証情報を検証し、ロールと権限をコンテキストに設定する
+// 認証情報が無い・JWTが不正・失効済みの場合は public として扱う（拒否は RequireAuthentication で行う）
+// X-API-Key が送られた場合は、未登録・無効・期限切れのキーを401で拒否する
+// ブラウザはWebSocketにヘッダーを付けられないため、アップグレード要求では access_token・api_key クエリも受け付ける
+func (a *Authenticator) Middleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Set(ContextKeyUserRole, "public")
+		c.Set(ContextKeyAuthenticated, false)
+
+		upgrade := websocket.IsWebSocketUpgrade(c.Request)
+		tokenStr := bearerToken(c.GetHeader("Authorization"))
+		if tokenStr == "" && upgrade {
+			tokenStr = c.Query("access_token")
+		}
+		if tokenStr != "" && a.authenticateJWT(c, tokenStr) {
+			c.Next()
+			return
+		}
+
+		key := c.GetHeader(APIKeyHeader)
+		if key == "" && upgrade {
+			key = c.Query("api_key")
+		}
+		if key != "" && a.apiKeys != nil {
+			if !a.authenticateAPIKey(c, key) {
+				httpx.JSONError(c, http.StatusUnauthorized, httpx.CodeUnauthorized, "APIキーが無効です", nil)
+				return
+			}
+			c.Next()
+			return
+		}
+		// 未認証のリクエストにもロールマスタで設定した public ロールの権限を適用する
+		c.Set(ContextKeyPermissions, a.rolePermissions("public"))
+		c.Next()
+	}
+}
+
+// authenticateJWT JWTを検証してコンテキストに設定（拒否リストにある jti は受け付けない）
+func (a *Authenticator) authenticateJWT(c *gin.Context, tokenStr string) bool {
+	claims, err := a.tokens.Parse(tokenStr, time.Now())
+	if err != nil {
+		return false
+	}
+	a.setIdentity(c, claims.Role, "jwt")
+	c.Set(ContextKeyUserID, claims.UserID)
+	c.Set(ContextKeyUsername, claims.Username)
+	c.Set(ContextKeyTokenID, claims.ID)
+	c.Set(ContextKeyTokenExpiresAt, claims.ExpiresAt.Time)
+	a.notify(claims.Username)
+	return true
+}
+
+// authenticateAPIKey APIキーを検証してコンテキストに設定（アクセスレベルをロールとして扱う）
+func (a *Authenticator) authenticateAPIKey(c *gin.Context, key string) bool {
+	now := time.Now()
+	apiKey, err := a.apiKeys.Authenticate(key, now)
+	if err != nil {
+		return false
+	}
+	a.setIdentity(c, apiKey.AccessLevel, "api_key")
+	c.Set(ContextKeyAPIKeyID, apiKey.ID)
+	if len(apiKey.ProjectIDs) > 0 {
+		c.Set(ContextKeyProjectScope, apiKey.ProjectIDs)
+	}
+	c.Set(ContextKeyUsername, "api-key:"+apiKey.Name)
+	a.touch(apiKey.ID, now)
+	a.notify("api-key:" + apiKey.Name)
+	return true
+}
+
+// setIdentity ロールと権限を設定
+func (a *Authenticator) setIdentity(c *gin.Context, role, method string) {
+	if role != "" {
+		c.Set(ContextKeyUserRole, role)
+	}
+	c.Set(ContextKeyPermissions, a.rolePermissions(role))
+	c.Set(ContextKeyAuthenticated, true)
+	c.Set(ContextKeyAuthMethod, method)
+}
+
+// rolePermissions ロールの権限（ロールマスタから検索し、無ければフォールバック）
+func (a *Authenticator) rolePermissions(role string) map[string]bool {
+	if a.roleRepo != nil {
+		if r, err := a.roleRepo.GetByName(role); err == nil && r.Permissions != nil {
+			return r.Permissions
+		}
+	}
+	return defaultPermissions(role)
+}
+
+// touch APIキーの最終使用日時を更新（apiKeyTouchInterval に1回まで）
+func (a *Authenticator) touch(id int, now time.Time) {
+	a.touchMu.Lock()
+	if last, ok := a.lastTouch[id]; ok && now.Sub(last) < apiKeyTouchInterval {
+		a.touchMu.Unlock()
+		return
+	}
+	a.lastTouch[id] = now
+	a.touchMu.Unlock()
+	_ = a.apiKeys.MarkUsed(id, now)
+}
+
+func (a *Authenticator) notify(username string) {
+	if a.onAuthenticated != nil {
+		a.onAuthenticated(username)
+	}
+}
+
+// RequireAuthentication 認証済みでないリクエストを401で拒否
+func RequireAuthentication() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !c.GetBool(ContextKeyAuthenticated) {
+			httpx.JSONError(c, http.StatusUnauthorized, httpx.CodeUnauthorized, "認証が必要です", nil)
+			return
+		}
+		c.Next()
+	}
+}
+
+// RestrictProjectScope プロジェクトスコープ付きのAPIキーで、スコープ外のプロジェクトを対象とするリクエストを403で拒否
+// 対象のプロジェクトはパスの :project_id、project_id クエリ、JSONボディの project_id・projectId から判定する
+func RestrictProjectScope() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if len(c.GetStringSlice(ContextKeyProjectScope)) == 0 {
+			c.Next()
+			return
+		}
+		for _, projectID := range requestProjectIDs(c) {
+			if !projectAllowed(c, projectID) {
+				httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "このAPIキーでは対象のプロジェクトにアクセスできません", gin.H{"project_id": projectID})
+				return
+			}
+		}
+		c.Next()
+	}
+}
+
+// projectAllowed APIキーのプロジェクトスコープにプロジェクトが含まれるか判定（スコープが無ければ常に true）
+func projectAllowed(c *gin.Context, projectID string) bool {
+	return (&domain.APIKey{ProjectIDs: c.GetStringSlice(ContextKeyProjectScope)}).AllowsProject(projectID)
+}
+
+// requestProjectIDs リクエストが対象とするプロジェクトID（ボディは読み取った後に戻す）
+func requestProjectIDs(c *gin.Context) []string {
+	var ids []string
+	for _, id := range []string{c.Param("project_id"), c.Query("project_id")} {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+	if c.Request.Body == nil || !strings.HasPrefix(c.ContentType(), "application/json") {
+		return ids
+	}
+	body, err := io.ReadAll(c.Request.Body)
+	c.Request.Body = io.NopCloser(bytes.NewReader(body))
+	if err != nil {
+		return ids
+	}
+	var target struct {
+		ProjectID      string `json:"project_id"`
+		ProjectIDCamel string `json:"projectId"`
+	}
+	if json.Unmarshal(body, &target) == nil {
+		for _, id := range []string{target.ProjectID, target.ProjectIDCamel} {
+			if id != "" {
+				ids = append(ids, id)
+			}
+		}
+	}
+	return ids
+}
+
+func bearerToken(auth string) string {
+	if len(auth) > 7 && strings.EqualFold(auth[:7], "Bearer ") {
+		return auth[7:]
+	}
+	return ""
+}
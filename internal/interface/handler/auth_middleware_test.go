@@ -0,0 +1,350 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testJWTSecret = "test-secret"
+
+func signTestToken(t *testing.T, role string) string {
+	t.Helper()
+	claims := &Claims{
+		UserID:   1,
+		Username: "alice",
+		Role:     role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        "jti-" + role,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return token
+}
+
+// memAPIKeyRepo テスト用のAPIキーリポジトリ
+type memAPIKeyRepo struct {
+	keys    []*domain.APIKey
+	touched []int
+}
+
+func (r *memAPIKeyRepo) GetByID(id int) (*domain.APIKey, error) {
+	for _, k := range r.keys {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+
+func (r *memAPIKeyRepo) GetByPrefix(prefix string) (*domain.APIKey, error) {
+	for _, k := range r.keys {
+		if k.Prefix == prefix {
+			return k, nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+
+func (r *memAPIKeyRepo) List(prefix string) ([]*domain.APIKey, error) {
+	var out []*domain.APIKey
+	for _, k := range r.keys {
+		if strings.HasPrefix(k.Prefix, prefix) {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (r *memAPIKeyRepo) Create(key *domain.APIKey) error {
+	key.ID = len(r.keys) + 1
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *memAPIKeyRepo) Update(key *domain.APIKey) error { return nil }
+
+func (r *memAPIKeyRepo) Delete(id int) error { return nil }
+
+func (r *memAPIKeyRepo) UpdateLastUsed(id int, at time.Time) error {
+	r.touched = append(r.touched, id)
+	return nil
+}
+
+func newAuthRouter(touched *[]string) *gin.Engine {
+	return newAuthRouterWithKeys(touched, nil)
+}
+
+func newAuthRouterWithKeys(touched *[]string, keys *usecase.APIKeyUseCase) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	auth := NewAuthenticator(testJWTSecret, nil)
+	auth.SetAPIKeyUseCase(keys)
+	auth.SetOnAuthenticated(func(username string) { *touched = append(*touched, username) })
+	r.Use(auth.Middleware())
+	r.GET("/whoami", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"role": c.GetString(ContextKeyUserRole), "authenticated": c.GetBool(ContextKeyAuthenticated), "method": c.GetString(ContextKeyAuthMethod)})
+	})
+	r.GET("/private", RequireAuthentication(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/projects/:project_id", RestrictProjectScope(), func(c *gin.Context) { c.Status(http.StatusOK) })
+	r.POST("/rules", RestrictProjectScope(), func(c *gin.Context) {
+		var body struct {
+			ProjectID string `json:"project_id"`
+		}
+		// ボディはスコープ確認の後もハンドラーで読める
+		if err := c.ShouldBindJSON(&body); err != nil || body.ProjectID == "" {
+			c.Status(http.StatusBadRequest)
+			return
+		}
+		c.Status(http.StatusOK)
+	})
+	return r
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	token := signTestToken(t, "admin")
+	tests := []struct {
+		name    string
+		path    string
+		header  http.Header
+		want    int
+		touched bool
+	}{
+		{"bearer token", "/private", http.Header{"Authorization": {"Bearer " + token}}, http.StatusOK, true},
+		{"no credentials", "/private", nil, http.StatusUnauthorized, false},
+		{"invalid token", "/private", http.Header{"Authorization": {"Bearer broken"}}, http.StatusUnauthorized, false},
+		{"query token on plain request", "/private?access_token=" + token, nil, http.StatusUnauthorized, false},
+		{"query token on websocket upgrade", "/private?access_token=" + token, http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}}, http.StatusOK, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var touched []string
+			r := newAuthRouter(&touched)
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.header {
+				req.Header.Set(k, v[0])
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+			if (len(touched) > 0) != tt.touched {
+				t.Errorf("onAuthenticated called with %v", touched)
+			}
+		})
+	}
+}
+
+func TestAuthMiddlewareSetsRole(t *testing.T) {
+	var touched []string
+	r := newAuthRouter(&touched)
+	req := httptest.NewRequest(http.MethodGet, "/whoami", nil)
+	req.Header.Set("Authorization", "bearer "+signTestToken(t, "user"))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	if body := w.Body.String(); body != `{"authenticated":true,"method":"jwt","role":"user"}` {
+		t.Errorf("body = %s", body)
+	}
+
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/whoami", nil))
+	if body := w.Body.String(); body != `{"authenticated":false,"method":"","role":"public"}` {
+		t.Errorf("anonymous body = %s", body)
+	}
+}
+
+// memRoleRepo テスト用のロールリポジトリ（登録されていないロールはエラー）
+type memRoleRepo map[string]domain.Role
+
+func (r memRoleRepo) GetAll() ([]domain.Role, error) { return nil, nil }
+func (r memRoleRepo) GetByName(name string) (domain.Role, error) {
+	role, ok := r[name]
+	if !ok {
+		return domain.Role{}, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+	}
+	return role, nil
+}
+func (r memRoleRepo) Create(role domain.Role) error              { return nil }
+func (r memRoleRepo) Update(name string, role domain.Role) error { return nil }
+func (r memRoleRepo) Delete(name string) error                   { return nil }
+
+func TestAuthMiddlewarePublicRolePermissions(t *testing.T) {
+	tests := []struct {
+		name  string
+		roles domain.RoleRepository
+		want  int
+	}{
+		{"public role grants permission", memRoleRepo{"public": {Name: "public", Permissions: map[string]bool{PermissionManageRules: true}}}, http.StatusOK},
+		{"public role without permission", memRoleRepo{"public": {Name: "public", Permissions: map[string]bool{}}}, http.StatusUnauthorized},
+		{"lookup failure falls back to defaults", memRoleRepo{}, http.StatusUnauthorized},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gin.SetMode(gin.TestMode)
+			r := gin.New()
+			r.Use(NewAuthenticator(testJWTSecret, tt.roles).Middleware())
+			r.GET("/rules", RequirePermission(PermissionManageRules), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/rules", nil))
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
+
+// memDenylist テスト用の拒否リスト
+type memDenylist map[string]bool
+
+func (d memDenylist) Add(jti string, expiresAt time.Time) error { d[jti] = true; return nil }
+func (d memDenylist) Contains(jti string) (bool, error)         { return d[jti], nil }
+func (d memDenylist) DeleteExpired(now time.Time) error         { return nil }
+
+func TestAuthMiddlewareRejectsRevokedToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tokens := usecase.NewAuthTokenUseCase(testJWTSecret)
+	tokens.SetDenylistRepository(memDenylist{"jti-user": true})
+	auth := NewAuthenticator(testJWTSecret, nil)
+	auth.SetTokenUseCase(tokens)
+	r := gin.New()
+	r.Use(auth.Middleware())
+	r.GET("/private", RequireAuthentication(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+	// jti の無いトークンは失効させられないため受け付けない
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &Claims{UserID: 1, Role: "admin", RegisteredClaims: jwt.RegisteredClaims{
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}}).SignedString([]byte(testJWTSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, token := range map[string]string{"revoked": signTestToken(t, "user"), "without jti": legacy, "valid": signTestToken(t, "admin")} {
+		req := httptest.NewRequest(http.MethodGet, "/private", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		want := http.StatusUnauthorized
+		if name == "valid" {
+			want = http.StatusOK
+		}
+		if w.Code != want {
+			t.Errorf("%s token: status %d, want %d", name, w.Code, want)
+		}
+	}
+}
+
+// issueTestKey テスト用にAPIキーを発行してキー全体を返す
+func issueTestKey(t *testing.T, keys *usecase.APIKeyUseCase, name string, projectIDs []string) (*domain.APIKey, string) {
+	t.Helper()
+	key, token, err := keys.CreateAPIKey(name, "", "admin", projectIDs, nil, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return key, token
+}
+
+func TestAuthMiddlewareAPIKey(t *testing.T) {
+	repo := &memAPIKeyRepo{}
+	keys := usecase.NewAPIKeyUseCase(repo)
+	_, ciKey := issueTestKey(t, keys, "ci", nil)
+	revoked, revokedKey := issueTestKey(t, keys, "revoked", nil)
+	if err := keys.RevokeAPIKey(revoked.ID); err != nil {
+		t.Fatal(err)
+	}
+	expired, expiredKey := issueTestKey(t, keys, "expired", nil)
+	past := time.Now().Add(-time.Hour)
+	expired.ExpiresAt = &past
+	var touched []string
+	r := newAuthRouterWithKeys(&touched, keys)
+
+	get := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		for k, v := range header {
+			req.Header.Set(k, v[0])
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := get("/whoami", http.Header{APIKeyHeader: {ciKey}})
+	if body := w.Body.String(); body != `{"authenticated":true,"method":"api_key","role":"admin"}` {
+		t.Errorf("valid key: %d %s", w.Code, body)
+	}
+	if len(repo.touched) != 1 || repo.touched[0] != 1 || len(touched) != 1 || touched[0] != "api-key:ci" {
+		t.Errorf("last used updates = %v, tracked = %v", repo.touched, touched)
+	}
+	// 短時間の再利用では最終使用日時を書き込まない
+	get("/private", http.Header{APIKeyHeader: {ciKey}})
+	if len(repo.touched) != 1 {
+		t.Errorf("last used updated again: %v", repo.touched)
+	}
+
+	// 保存されたハッシュや Prefix だけではキーとして使えない
+	for _, key := range []string{revokedKey, expiredKey, "unknown", repo.keys[0].KeyHash, repo.keys[0].Prefix, ciKey + "x"} {
+		if w := get("/whoami", http.Header{APIKeyHeader: {key}}); w.Code != http.StatusUnauthorized {
+			t.Errorf("%s: status %d, want 401", key, w.Code)
+		}
+	}
+
+	// 有効なJWTがあればAPIキーより優先する
+	w = get("/whoami", http.Header{"Authorization": {"Bearer " + signTestToken(t, "user")}, APIKeyHeader: {"unknown"}})
+	if w.Code != http.StatusOK {
+		t.Errorf("jwt with bad key: status %d", w.Code)
+	}
+
+	if w := get("/private?api_key="+ciKey, http.Header{"Connection": {"Upgrade"}, "Upgrade": {"websocket"}}); w.Code != http.StatusOK {
+		t.Errorf("api_key query on upgrade: status %d", w.Code)
+	}
+}
+
+func TestRestrictProjectScope(t *testing.T) {
+	keys := usecase.NewAPIKeyUseCase(&memAPIKeyRepo{})
+	_, scoped := issueTestKey(t, keys, "web-ci", []string{"web"})
+	_, unscoped := issueTestKey(t, keys, "ci", nil)
+	var touched []string
+	r := newAuthRouterWithKeys(&touched, keys)
+
+	tests := []struct {
+		name string
+		key  string
+		path string
+		body string
+		want int
+	}{
+		{"path in scope", scoped, "/projects/web", "", http.StatusOK},
+		{"path out of scope", scoped, "/projects/other", "", http.StatusForbidden},
+		{"query out of scope", scoped, "/projects/web?project_id=other", "", http.StatusForbidden},
+		{"body in scope", scoped, "/rules", `{"project_id":"web"}`, http.StatusOK},
+		{"body out of scope", scoped, "/rules", `{"project_id":"other"}`, http.StatusForbidden},
+		{"camel case body out of scope", scoped, "/rules", `{"projectId":"other","project_id":"web"}`, http.StatusForbidden},
+		{"unscoped key", unscoped, "/rules", `{"project_id":"other"}`, http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set(APIKeyHeader, tt.key)
+			if tt.body != "" {
+				req.Header.Set("Content-Type", "application/json")
+			}
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if w.Code != tt.want {
+				t.Errorf("status %d, want %d: %s", w.Code, tt.want, w.Body.String())
+			}
+		})
+	}
+}
@@ -1,7 +1,11 @@
 package handler
 
 import (
+	"bytes"
+	"encoding/csv"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
@@ -29,7 +33,9 @@ func (h *GlobalRuleHandler) GetGlobalRules(c *gin.Context) {
 		return
 	}
 
-	rules, err := h.globalRuleUseCase.GetGlobalRules(language)
+	// include_inactive=true で無効化したルールも返す（is_active で区別できる）
+	includeInactive, _ := strconv.ParseBool(c.Query("include_inactive"))
+	rules, err := h.globalRuleUseCase.ListGlobalRules(language, includeInactive)
 	if err != nil {
 		httpx.JSONFromError(c, err)
 		return
@@ -39,11 +45,6 @@ func (h *GlobalRuleHandler) GetGlobalRules(c *gin.Context) {
 }
 
 func (h *GlobalRuleHandler) CreateGlobalRule(c *gin.Context) {
-	// 権限チェック（manage_rules）
-	if perms, ok := c.Get("permissions"); !ok || !perms.(map[string]bool)["manage_rules"] {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
-		return
-	}
 	var req struct {
 		Language    string `json:"language" binding:"required"`
 		RuleID      string `json:"rule_id" binding:"required"`
@@ -53,6 +54,8 @@ func (h *GlobalRuleHandler) CreateGlobalRule(c *gin.Context) {
 		Severity    string `json:"severity"`
 		Pattern     string `json:"pattern"`
 		Message     string `json:"message"`
+		Replacement string `json:"replacement"`
+		domain.RuleMatcher
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -60,7 +63,7 @@ func (h *GlobalRuleHandler) CreateGlobalRule(c *gin.Context) {
 		return
 	}
 
-	err := h.globalRuleUseCase.CreateGlobalRule(req.Language, req.RuleID, req.Name, req.Description, req.Type, req.Severity, req.Pattern, req.Message)
+	err := h.globalRuleUseCase.CreateGlobalRule(req.Language, req.RuleID, req.Name, req.Description, req.Type, req.Severity, req.Pattern, req.Message, req.Replacement, req.RuleMatcher)
 	if err != nil {
 		httpx.JSONFromError(c, err)
 		return
@@ -69,12 +72,65 @@ func (h *GlobalRuleHandler) CreateGlobalRule(c *gin.Context) {
 	c.JSON(http.StatusCreated, gin.H{"message": "Global rule created successfully"})
 }
 
-func (h *GlobalRuleHandler) DeleteGlobalRule(c *gin.Context) {
-	// 権限チェック（manage_rules）
-	if perms, ok := c.Get("permissions"); !ok || !perms.(map[string]bool)["manage_rules"] {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
+// GetGlobalRule グローバルルールを1件取得（無効化したルールも含む）
+func (h *GlobalRuleHandler) GetGlobalRule(c *gin.Context) {
+	rule, err := h.globalRuleUseCase.GetGlobalRule(c.Param("language"), c.Param("rule_id"))
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, rule)
+}
+
+// UpdateGlobalRule グローバルルールを更新（指定した項目のみ変更。is_active: false で無効化）
+func (h *GlobalRuleHandler) UpdateGlobalRule(c *gin.Context) {
+	language := c.Param("language")
+	ruleID := c.Param("rule_id")
+	if language == "" || ruleID == "" {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "language and rule_id are required", nil)
+		return
+	}
+
+	var req struct {
+		Name        *string `json:"name"`
+		Description *string `json:"description"`
+		Type        *string `json:"type"`
+		Severity    *string `json:"severity"`
+		Pattern     *string `json:"pattern"`
+		Message     *string `json:"message"`
+		Replacement *string `json:"replacement"`
+		IsActive    *bool   `json:"is_active"`
+		domain.RuleMatcher
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+
+	update := usecase.GlobalRuleUpdate{
+		Name:        req.Name,
+		Description: req.Description,
+		Type:        req.Type,
+		Severity:    req.Severity,
+		Pattern:     req.Pattern,
+		Message:     req.Message,
+		Replacement: req.Replacement,
+		IsActive:    req.IsActive,
+	}
+	// matcher_kind 指定時のみマッチャー設定を置き換える
+	if req.MatcherKind != "" {
+		update.Matcher = &req.RuleMatcher
+	}
+
+	rule, err := h.globalRuleUseCase.UpdateGlobalRule(language, ruleID, update)
+	if err != nil {
+		httpx.JSONFromError(c, err)
 		return
 	}
+	c.JSON(http.StatusOK, gin.H{"message": "Global rule updated successfully", "rule": rule})
+}
+
+func (h *GlobalRuleHandler) DeleteGlobalRule(c *gin.Context) {
 	language := c.Param("language")
 	ruleID := c.Param("rule_id")
 
@@ -102,18 +158,36 @@ func (h *GlobalRuleHandler) GetLanguages(c *gin.Context) {
 	c.JSON(http.StatusOK, gin.H{"languages": languages})
 }
 
-// ExportGlobalRulesRequest グローバルルールエクスポートリクエスト
+// ExportGlobalRulesRequest グローバルルールエクスポートリクエスト（language を省略した場合は全言語）
 type ExportGlobalRulesRequest struct {
 	Language string   `json:"language"`
 	RuleIDs  []string `json:"ruleIds,omitempty"`
 	Format   string   `json:"format"` // json, yaml, csv
 }
 
-// ImportGlobalRulesRequest グローバルルールインポートリクエスト
+// ImportGlobalRulesRequest グローバルルールインポートリクエスト（JSON形式のエクスポート結果をそのまま渡せる）
+// overwrite が true の場合は既存のルールを上書きし、false の場合はスキップする
 type ImportGlobalRulesRequest struct {
-	Language  string              `json:"language"`
-	Rules     []domain.GlobalRule `json:"rules"`
-	Overwrite bool                `json:"overwrite"`
+	Language  string             `json:"language"`
+	Rules     []ImportGlobalRule `json:"rules"`
+	Overwrite bool               `json:"overwrite"`
+}
+
+// ImportGlobalRule インポートするグローバルルール（is_active を省略した場合は有効）
+type ImportGlobalRule struct {
+	domain.GlobalRule
+	IsActive *bool `json:"is_active"`
+}
+
+// globalRulesFromImport インポートデータをグローバルルールに変換
+func globalRulesFromImport(items []ImportGlobalRule) []domain.GlobalRule {
+	rules := make([]domain.GlobalRule, 0, len(items))
+	for _, item := range items {
+		rule := item.GlobalRule
+		rule.IsActive = item.IsActive == nil || *item.IsActive
+		rules = append(rules, rule)
+	}
+	return rules
 }
 
 // ExportGlobalRules グローバルルールエクスポート
@@ -124,68 +198,34 @@ func (h *GlobalRuleHandler) ExportGlobalRules(c *gin.Context) {
 		return
 	}
 
-	// 管理者権限チェック
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
+	rules, err := h.globalRuleUseCase.ExportGlobalRules(req.Language, req.RuleIDs)
+	if err != nil {
+		httpx.JSONFromError(c, err)
 		return
 	}
 
-	// グローバルルール取得ロジック
-	var rules []domain.GlobalRule
-	if len(req.RuleIDs) > 0 {
-		// 特定のルールIDを指定
-		for _, ruleID := range req.RuleIDs {
-			rule := domain.GlobalRule{
-				RuleID:      ruleID,
-				Language:    req.Language,
-				Name:        "Global Rule " + ruleID,
-				Description: "Exported global rule",
-				Pattern:     "global_pattern_" + ruleID,
-				Message:     "Global message for " + ruleID,
-				Severity:    "warning",
-				IsActive:    true,
-			}
-			rules = append(rules, rule)
-		}
-	} else {
-		// 言語全体のルール
-		rules = []domain.GlobalRule{
-			{
-				RuleID:      "global1",
-				Language:    req.Language,
-				Name:        "Global Rule 1",
-				Description: "Exported global rule 1",
-				Pattern:     "global_pattern_1",
-				Message:     "Global message for rule 1",
-				Severity:    "warning",
-				IsActive:    true,
-			},
-			{
-				RuleID:      "global2",
-				Language:    req.Language,
-				Name:        "Global Rule 2",
-				Description: "Exported global rule 2",
-				Pattern:     "global_pattern_2",
-				Message:     "Global message for rule 2",
-				Severity:    "error",
-				IsActive:    true,
-			},
-		}
+	filename := "global_rules"
+	if req.Language != "" {
+		filename += "_" + req.Language
 	}
 
 	// フォーマットに応じてレスポンス
 	switch req.Format {
 	case "yaml":
 		c.Header("Content-Type", "application/x-yaml")
-		c.Header("Content-Disposition", "attachment; filename=global_rules_"+req.Language+".yaml")
-		c.String(http.StatusOK, "language: "+req.Language+"\nrules:\n  - name: Global Rule 1\n    pattern: global_pattern_1\n  - name: Global Rule 2\n    pattern: global_pattern_2")
+		c.Header("Content-Disposition", "attachment; filename="+filename+".yaml")
+		c.String(http.StatusOK, globalRulesYAML(req.Language, rules))
 	case "csv":
+		content, err := globalRulesCSV(rules)
+		if err != nil {
+			httpx.JSONError(c, http.StatusInternalServerError, httpx.CodeInternal, "CSVの生成に失敗しました", nil)
+			return
+		}
 		c.Header("Content-Type", "text/csv")
-		c.Header("Content-Disposition", "attachment; filename=global_rules_"+req.Language+".csv")
-		c.String(http.StatusOK, "Language,RuleID,Name,Pattern,Message,Severity\n"+req.Language+",global1,Global Rule 1,global_pattern_1,Global message for rule 1,warning\n"+req.Language+",global2,Global Rule 2,global_pattern_2,Global message for rule 2,error")
+		c.Header("Content-Disposition", "attachment; filename="+filename+".csv")
+		c.String(http.StatusOK, content)
 	default:
-		// JSON形式（デフォルト）
+		// JSON形式（デフォルト）。rules はそのままインポートに使える
 		c.JSON(http.StatusOK, gin.H{
 			"language":   req.Language,
 			"format":     req.Format,
@@ -195,59 +235,92 @@ func (h *GlobalRuleHandler) ExportGlobalRules(c *gin.Context) {
 	}
 }
 
-// ImportGlobalRules グローバルルールインポート
+// globalRuleColumns CSV・YAML出力の列（JSONのフィールド名と同じ）
+var globalRuleColumns = []string{"language", "rule_id", "name", "description", "type", "severity", "pattern", "message", "replacement", "is_active", "matcher_kind", "flags", "file_glob", "limit"}
+
+func globalRuleValues(rule *domain.GlobalRule) []string {
+	return []string{rule.Language, rule.RuleID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.Replacement,
+		strconv.FormatBool(rule.IsActive), rule.Kind(), rule.Flags, rule.FileGlob, strconv.Itoa(rule.Limit)}
+}
+
+func globalRulesCSV(rules []*domain.GlobalRule) (string, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(globalRuleColumns); err != nil {
+		return "", err
+	}
+	for _, rule := range rules {
+		if err := w.Write(globalRuleValues(rule)); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+// globalRulesYAML 文字列はすべてダブルクォートで出力する（パターンの記号をそのまま保持するため）
+func globalRulesYAML(language string, rules []*domain.GlobalRule) string {
+	var b strings.Builder
+	b.WriteString("language: " + strconv.Quote(language) + "\nrules:")
+	if len(rules) == 0 {
+		b.WriteString(" []")
+	}
+	b.WriteString("\n")
+	for _, rule := range rules {
+		for i, value := range globalRuleValues(rule) {
+			prefix := "    "
+			if i == 0 {
+				prefix = "  - "
+			}
+			column := globalRuleColumns[i]
+			if column == "is_active" || column == "limit" {
+				b.WriteString(prefix + column + ": " + value + "\n")
+				continue
+			}
+			b.WriteString(prefix + column + ": " + strconv.Quote(value) + "\n")
+		}
+	}
+	return b.String()
+}
+
+// ImportGlobalRules グローバルルールインポート（ルールごとの結果を results で返す）
 func (h *GlobalRuleHandler) ImportGlobalRules(c *gin.Context) {
 	var req ImportGlobalRulesRequest
 	if err := c.ShouldBindJSON(&req); err != nil {
-		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "Invalid request data", nil)
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "Invalid request data", err.Error())
 		return
 	}
 
-	// 管理者権限チェック
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
+	report, err := h.globalRuleUseCase.ImportGlobalRules(req.Language, globalRulesFromImport(req.Rules), req.Overwrite)
+	if err != nil {
+		httpx.JSONFromError(c, err)
 		return
 	}
 
-	// インポート処理
-	importedCount := 0
-	skippedCount := 0
-	errors := []string{}
-
-	for _, rule := range req.Rules {
-		// ルール検証
-		if rule.Name == "" || rule.Pattern == "" {
-			errors = append(errors, "Global rule "+rule.RuleID+" has invalid data")
-			continue
-		}
-
-		// 言語一致チェック
-		if rule.Language != req.Language {
-			errors = append(errors, "Global rule "+rule.RuleID+" language mismatch")
-			continue
-		}
+	c.JSON(http.StatusOK, gin.H{
+		"message":          "Global rules import completed",
+		"language":         req.Language,
+		"overwrite":        req.Overwrite,
+		"importedCount":    report.Imported(),
+		"createdCount":     report.Created,
+		"overwrittenCount": report.Overwritten,
+		"skippedCount":     report.Skipped,
+		"errorCount":       report.Failed,
+		"errors":           importErrors(report),
+		"results":          report.Results,
+		"importedAt":       time.Now().Format(time.RFC3339),
+	})
+}
 
-		// 重複チェック
-		if !req.Overwrite {
-			// 既存ルールとの重複チェック（簡易実装）
-			skippedCount++
-			continue
+// importErrors 失敗したルールのエラーメッセージ（従来の errors フィールド向け）
+func importErrors(report *usecase.GlobalRuleImportReport) []string {
+	errors := []string{}
+	for _, result := range report.Results {
+		if result.Status == usecase.ImportStatusFailed {
+			errors = append(errors, "Global rule "+result.Language+"/"+result.RuleID+": "+result.Error)
 		}
-
-		// ルール保存処理（簡易実装）
-		importedCount++
 	}
-
-	c.JSON(http.StatusOK, gin.H{
-		"message":       "Global rules import completed",
-		"language":      req.Language,
-		"importedCount": importedCount,
-		"skippedCount":  skippedCount,
-		"errorCount":    len(errors),
-		"errors":        errors,
-		"importedAt":    time.Now().Format(time.RFC3339),
-	})
+	return errors
 }
 
 // LanguageInfo 言語情報
@@ -288,13 +361,6 @@ func (h *GlobalRuleHandler) CreateLanguage(c *gin.Context) {
 		return
 	}
 
-	// 管理者権限チェック
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
-		return
-	}
-
 	// 言語コードの重複チェック
 	_, err := h.languageRepo.GetByCode(req.Code)
 	if err == nil {
@@ -338,13 +404,6 @@ func (h *GlobalRuleHandler) UpdateLanguage(c *gin.Context) {
 		return
 	}
 
-	// 管理者権限チェック
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
-		return
-	}
-
 	// 既存の言語を取得
 	language, err := h.languageRepo.GetByCode(languageCode)
 	if err != nil {
@@ -390,13 +449,6 @@ func (h *GlobalRuleHandler) DeleteLanguage(c *gin.Context) {
 		return
 	}
 
-	// 管理者権限チェック
-	userRole, exists := c.Get("userRole")
-	if !exists || userRole != "admin" {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Admin access required", nil)
-		return
-	}
-
 	// 言語が存在するかチェック
 	_, err := h.languageRepo.GetByCode(languageCode)
 	if err != nil {
@@ -0,0 +1,121 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/infrastructure/rulesfile"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func newGlobalRuleRouter(t *testing.T, data string) (*gin.Engine, *rulesfile.Store) {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store, err := rulesfile.Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewGlobalRuleHandler(usecase.NewGlobalRuleUseCase(store.GlobalRules()), nil)
+	r := gin.New()
+	r.GET("/global-rules/:language", h.GetGlobalRules)
+	r.POST("/global-rules/export", h.ExportGlobalRules)
+	r.POST("/global-rules/import", h.ImportGlobalRules)
+	return r, store
+}
+
+func postJSON(r *gin.Engine, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func TestGetGlobalRulesIncludeInactive(t *testing.T) {
+	r, _ := newGlobalRuleRouter(t, `{"projects": {}, "global_rules": {"go": [
+		{"id": "active", "name": "Active", "pattern": "x"},
+		{"id": "retired", "name": "Retired", "pattern": "y", "is_active": false}
+	]}}`)
+	list := func(query string) map[string]bool {
+		t.Helper()
+		w := sendJSON(r, http.MethodGet, "/global-rules/go"+query, "")
+		var body struct {
+			Rules []domain.GlobalRule `json:"rules"`
+		}
+		if err := json.Unmarshal(w.Body.Bytes(), &body); err != nil || w.Code != http.StatusOK {
+			t.Fatalf("GET %s status %d: %s", query, w.Code, w.Body.String())
+		}
+		active := map[string]bool{}
+		for _, rule := range body.Rules {
+			active[rule.RuleID] = rule.IsActive
+		}
+		return active
+	}
+
+	// 既定では継承される有効なルールのみ
+	if got := list(""); len(got) != 1 || !got["active"] {
+		t.Errorf("default list = %v, want only the active rule", got)
+	}
+	// 無効化したルールも is_active: false で一覧に含め、再び有効にできるようにする
+	if got := list("?include_inactive=true"); len(got) != 2 || !got["active"] || got["retired"] {
+		t.Errorf("include_inactive list = %v, want both rules with is_active", got)
+	}
+}
+
+func TestGlobalRulesExportImportRoundTrip(t *testing.T) {
+	source, _ := newGlobalRuleRouter(t, `{"projects": {}, "global_rules": {"go": [
+		{"id": "no-println", "name": "No Println", "severity": "warning", "pattern": "fmt\\.Println", "message": "use log"},
+		{"id": "doc", "name": "Doc", "matcher_kind": "go_ast", "pattern": "doc:exported"}
+	]}}`)
+	w := postJSON(source, "/global-rules/export", `{"language": "go"}`)
+	if w.Code != http.StatusOK {
+		t.Fatalf("export status %d: %s", w.Code, w.Body.String())
+	}
+	var exported struct {
+		Rules json.RawMessage `json:"rules"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &exported); err != nil {
+		t.Fatal(err)
+	}
+
+	target, store := newGlobalRuleRouter(t, `{"projects": {}, "global_rules": {"go": [{"id": "no-println", "name": "Old", "pattern": "x"}]}}`)
+	w = postJSON(target, "/global-rules/import", `{"language": "go", "overwrite": false, "rules": `+string(exported.Rules)+`}`)
+	var report struct {
+		ImportedCount int                              `json:"importedCount"`
+		SkippedCount  int                              `json:"skippedCount"`
+		Results       []usecase.GlobalRuleImportResult `json:"results"`
+	}
+	if err := json.Unmarshal(w.Body.Bytes(), &report); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("import status %d: %s", w.Code, w.Body.String())
+	}
+	if report.ImportedCount != 1 || report.SkippedCount != 1 || len(report.Results) != 2 {
+		t.Fatalf("report = %+v", report)
+	}
+
+	rules, _ := store.GlobalRules().GetByLanguage("go")
+	if len(rules) != 2 {
+		t.Fatalf("stored rules = %+v", rules)
+	}
+	for _, rule := range rules {
+		if rule.RuleID == "doc" && (rule.Kind() != "go_ast" || !rule.IsActive) {
+			t.Errorf("imported rule lost its matcher or active flag: %+v", rule)
+		}
+		if rule.RuleID == "no-println" && rule.Name != "Old" {
+			t.Errorf("existing rule overwritten without overwrite: %+v", rule)
+		}
+	}
+}
+
+func TestExportGlobalRulesCSV(t *testing.T) {
+	r, _ := newGlobalRuleRouter(t, `{"projects": {}, "global_rules": {"go": [{"id": "a", "name": "A, with comma", "pattern": "x"}]}}`)
+	w := postJSON(r, "/global-rules/export", `{"language": "go", "format": "csv"}`)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	if len(lines) != 2 || !strings.HasPrefix(lines[0], "language,rule_id,name") || !strings.Contains(lines[1], `"A, with comma"`) {
+		t.Errorf("csv = %q", w.Body.String())
+	}
+}
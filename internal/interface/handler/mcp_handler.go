@@ -3,10 +3,12 @@ package handler
 import (
 	"encoding/json"
 	"net/http"
+	"sync"
 	"time"
 
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/httpx"
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/mcpx"
 	"github.com/gin-gonic/gin"
 	"github.com/gorilla/websocket"
@@ -16,16 +18,23 @@ type MCPHandler struct {
 	ruleUseCase       *usecase.RuleUseCase
 	globalRuleUseCase *usecase.GlobalRuleUseCase
 	projectDetector   *usecase.ProjectDetector
+	projectAccess     *usecase.ProjectAccessUseCase
 	metricsRepo       domain.MetricsRepository
 	metricsHandler    *MetricsHandler
+	tools             *mcpToolRegistry
+	sessions          *mcpSessionStore
+	allowedOrigins    []string
 }
 
 func NewMCPHandler(ruleUseCase *usecase.RuleUseCase, globalRuleUseCase *usecase.GlobalRuleUseCase, projectDetector *usecase.ProjectDetector) *MCPHandler {
-	return &MCPHandler{
+	h := &MCPHandler{
 		ruleUseCase:       ruleUseCase,
 		globalRuleUseCase: globalRuleUseCase,
 		projectDetector:   projectDetector,
+		sessions:          newMCPSessionStore(),
 	}
+	h.tools = h.newToolRegistry()
+	return h
 }
 
 // SetMetricsRepo メトリクスリポジトリを注入
@@ -33,6 +42,16 @@ func (h *MCPHandler) SetMetricsRepo(repo domain.MetricsRepository) {
 	h.metricsRepo = repo
 }
 
+// SetProjectAccess プロジェクト単位の認可を注入（未設定の場合はAPIキーのスコープのみ確認）
+func (h *MCPHandler) SetProjectAccess(access *usecase.ProjectAccessUseCase) {
+	h.projectAccess = access
+}
+
+// SetAllowedOrigins WebSocket接続を許可するオリジンを設定
+func (h *MCPHandler) SetAllowedOrigins(origins []string) {
+	h.allowedOrigins = origins
+}
+
 // SetMetricsHandler メトリクスハンドラーを注入
 func (h *MCPHandler) SetMetricsHandler(handler *MetricsHandler) {
 	h.metricsHandler = handler
@@ -57,146 +76,206 @@ func (h *MCPHandler) withMetrics(method string, handler func() error) {
 	}
 }
 
-// HandleMCPRequest MCPプロトコルリクエストを処理
-func (h *MCPHandler) HandleMCPRequest(c *gin.Context) {
-	var req domain.MCPRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.sendMCPError(c, "", mcpx.CodeValidation, "Invalid request format")
-		return
-	}
-
-	switch req.Method {
-	case "tools/list":
-		h.withMetrics("tools/list", func() error { h.handleToolsList(c, req); return nil })
-	case "getRules":
-		h.withMetrics("getRules", func() error { h.handleGetRules(c, req); return nil })
-	case "validateCode":
-		h.withMetrics("validateCode", func() error { h.handleValidateCode(c, req); return nil })
-	case "getProjectInfo":
-		h.withMetrics("getProjectInfo", func() error { h.handleGetProjectInfo(c, req); return nil })
-	case "autoDetectProject":
-		h.withMetrics("autoDetectProject", func() error { h.handleAutoDetectProject(c, req); return nil })
-	case "scanLocalProjects":
-		h.withMetrics("scanLocalProjects", func() error { h.handleScanLocalProjects(c, req); return nil })
-	default:
-		h.sendMCPError(c, req.ID, mcpx.CodeNotFound, "Method not found: "+req.Method)
-	}
-}
-
-// handleToolsList tools/list MCPメソッドを処理
-func (h *MCPHandler) handleToolsList(c *gin.Context, req domain.MCPRequest) {
-	tools := []map[string]interface{}{
-		{
-			"name":        "getRules",
-			"description": "Get coding rules for a specific project",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"project_id": map[string]interface{}{
-						"type":        "string",
-						"description": "The project ID to get rules for",
-					},
-					"language": map[string]interface{}{
-						"type":        "string",
-						"description": "Programming language (optional)",
-					},
+// newToolRegistry MCPHandlerが提供するツールを宣言
+// ツールを追加する場合はここに登録するだけで、tools/list と全トランスポートから呼び出せる
+func (h *MCPHandler) newToolRegistry() *mcpToolRegistry {
+	r := newMCPToolRegistry()
+	r.register(mcpTool{
+		name:        "getRules",
+		description: "Get coding rules for a specific project",
+		inputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"project_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The project ID to get rules for",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Programming language (optional)",
 				},
-				"required": []string{"project_id"},
 			},
+			"required": []string{"project_id"},
 		},
-		{
-			"name":        "validateCode",
-			"description": "Validate code against project rules",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"project_id": map[string]interface{}{
-						"type":        "string",
-						"description": "The project ID to validate against",
-					},
-					"code": map[string]interface{}{
-						"type":        "string",
-						"description": "The code to validate",
-					},
-					"language": map[string]interface{}{
-						"type":        "string",
-						"description": "Programming language (optional)",
-					},
+		handler: h.handleGetRules,
+	})
+	r.register(mcpTool{
+		name:        "validateCode",
+		description: "Validate code against project rules",
+		inputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"project_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The project ID to validate against",
+				},
+				"code": map[string]interface{}{
+					"type":        "string",
+					"description": "The code to validate",
+				},
+				"language": map[string]interface{}{
+					"type":        "string",
+					"description": "Programming language (optional)",
+				},
+				"filename": map[string]interface{}{
+					"type":        "string",
+					"description": "File path used for file_glob scoped rules (optional)",
 				},
-				"required": []string{"project_id", "code"},
 			},
+			"required": []string{"project_id", "code"},
 		},
-		{
-			"name":        "getProjectInfo",
-			"description": "Get information about a specific project",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"project_id": map[string]interface{}{
-						"type":        "string",
-						"description": "The project ID to get info for",
+		handler: h.handleValidateCode,
+	})
+	r.register(mcpTool{
+		name:        "validateFiles",
+		description: "Validate several files at once; rules are applied per file by language and file_glob",
+		inputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"project_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The project ID to validate against",
+				},
+				"files": map[string]interface{}{
+					"type":        "array",
+					"description": "Files to validate",
+					"items": map[string]interface{}{
+						"type": "object",
+						"properties": map[string]interface{}{
+							"path":     map[string]interface{}{"type": "string", "description": "File path relative to the project root"},
+							"content":  map[string]interface{}{"type": "string", "description": "File content"},
+							"language": map[string]interface{}{"type": "string", "description": "Programming language (optional, inferred from extension)"},
+						},
+						"required": []string{"path", "content"},
 					},
 				},
-				"required": []string{"project_id"},
 			},
+			"required": []string{"project_id", "files"},
 		},
-		{
-			"name":        "autoDetectProject",
-			"description": "Automatically detect project from path and get appropriate rules",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"path": map[string]interface{}{
-						"type":        "string",
-						"description": "The path to detect project from",
-					},
+		handler: h.handleValidateFiles,
+	})
+	r.register(mcpTool{
+		name:        "validateDiff",
+		description: "Validate a unified diff (git diff output); only issues on added lines are reported, with new-file line numbers",
+		inputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"project_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The project ID to validate against",
+				},
+				"diff": map[string]interface{}{
+					"type":        "string",
+					"description": "Unified diff as produced by git diff",
 				},
-				"required": []string{"path"},
 			},
+			"required": []string{"project_id", "diff"},
 		},
-		{
-			"name":        "scanLocalProjects",
-			"description": "Scan local directory to detect multiple projects",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"base_path": map[string]interface{}{
-						"type":        "string",
-						"description": "The base path to scan for projects (optional, defaults to /)",
-					},
+		handler: h.handleValidateDiff,
+	})
+	r.register(mcpTool{
+		name:        "fixCode",
+		description: "Apply automatic fixes from project rules and return the patched code with a unified diff",
+		inputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"project_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The project ID whose rules are applied",
+				},
+				"code": map[string]interface{}{
+					"type":        "string",
+					"description": "The code to fix",
+				},
+				"filename": map[string]interface{}{
+					"type":        "string",
+					"description": "File path used for file_glob scoped rules (optional)",
+				},
+				"rule_ids": map[string]interface{}{
+					"type":        "array",
+					"items":       map[string]interface{}{"type": "string"},
+					"description": "Only apply fixes from these rules (optional)",
 				},
 			},
+			"required": []string{"project_id", "code"},
 		},
-	}
+		handler: h.handleFixCode,
+	})
+	r.register(mcpTool{
+		name:        "getProjectInfo",
+		description: "Get a project summary: language metadata, project vs inherited rule counts by severity and type, last rule update and recent violation counts",
+		inputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"project_id": map[string]interface{}{
+					"type":        "string",
+					"description": "The project ID to get info for",
+				},
+			},
+			"required": []string{"project_id"},
+		},
+		handler: h.handleGetProjectInfo,
+	})
+	r.register(mcpTool{
+		name:        "autoDetectProject",
+		description: "Automatically detect project from path and get appropriate rules",
+		inputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"path": map[string]interface{}{
+					"type":        "string",
+					"description": "The path to detect project from",
+				},
+			},
+			"required": []string{"path"},
+		},
+		handler: h.handleAutoDetectProject,
+	})
+	r.register(mcpTool{
+		name:        "scanLocalProjects",
+		description: "Scan local directory to detect multiple projects",
+		inputSchema: map[string]interface{}{
+			"type": "object",
+			"properties": map[string]interface{}{
+				"base_path": map[string]interface{}{
+					"type":        "string",
+					"description": "The base path to scan for projects (optional, defaults to /)",
+				},
+			},
+		},
+		// サーバーのファイルシステムを走査するため、ルール管理権限を要求する
+		permission: PermissionManageRules,
+		handler:    h.handleScanLocalProjects,
+	})
+	return r
+}
 
-	h.sendMCPResponse(c, req.ID, map[string]interface{}{"tools": tools})
+// handleToolsList tools/list MCPメソッドを処理（呼び出し元が使えるツールのみ返す）
+func (h *MCPHandler) handleToolsList(caller mcpCaller, params json.RawMessage) (interface{}, error) {
+	return map[string]interface{}{"tools": h.tools.list(caller)}, nil
 }
 
 // handleGetRules getRules MCPメソッドを処理
-func (h *MCPHandler) handleGetRules(c *gin.Context, req domain.MCPRequest) {
-	var params domain.MCPRuleRequest
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendMCPError(c, req.ID, mcpx.CodeValidation, "Invalid parameters")
-		return
+func (h *MCPHandler) handleGetRules(_ mcpCaller, params json.RawMessage) (interface{}, error) {
+	var req domain.MCPRuleRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
 	}
 
-	if params.ProjectID == "" {
-		h.sendMCPError(c, req.ID, mcpx.CodeValidation, "Project ID is required")
-		return
+	if req.ProjectID == "" {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID is required")
 	}
 
 	// プロジェクトルールを取得
-	projectRules, err := h.ruleUseCase.GetProjectRules(params.ProjectID)
+	projectRules, err := h.ruleUseCase.GetProjectRules(req.ProjectID)
 	if err != nil {
-		code, msg := mcpx.MapAppErrorToMCP(err)
-		h.sendMCPError(c, req.ID, code, "Failed to get project rules: "+msg)
-		return
+		return nil, mcpx.FromError(err, "Failed to get project rules: ")
 	}
 
 	// 言語が指定されている場合はグローバルルールを取得
 	var globalRules []domain.GlobalRule
-	if params.Language != "" {
-		globalRulesPtr, err := h.globalRuleUseCase.GetGlobalRules(params.Language)
+	if req.Language != "" {
+		globalRulesPtr, err := h.globalRuleUseCase.GetGlobalRules(req.Language)
 		if err == nil {
 			// ポインタスライスを値スライスに変換
 			globalRules = make([]domain.GlobalRule, len(globalRulesPtr))
@@ -210,233 +289,199 @@ func (h *MCPHandler) handleGetRules(c *gin.Context, req domain.MCPRequest) {
 	appliedRules := make([]domain.Rule, 0, len(projectRules.Rules)+len(globalRules))
 	appliedRules = append(appliedRules, projectRules.Rules...)
 
-	// グローバルルールをプロジェクトルール形式に変換
+	// 継承済み・上書き済みのルールと重複しないグローバルルールをプロジェクトルール形式に変換
+	// （プロジェクトで無効化したルールを applied_rules に戻さない）
+	seen := make(map[string]bool, len(projectRules.Rules)+len(projectRules.Overrides))
+	for _, r := range projectRules.Rules {
+		seen[r.Language+"/"+r.RuleID] = true
+	}
+	for _, o := range projectRules.Overrides {
+		seen[o.Language+"/"+o.RuleID] = true
+	}
 	for _, gr := range globalRules {
-		rule := domain.Rule{
-			RuleID:      gr.RuleID,
-			Name:        gr.Name,
-			Description: gr.Description,
-			Type:        gr.Type,
-			Severity:    gr.Severity,
-			Pattern:     gr.Pattern,
-			Message:     gr.Message,
-			IsActive:    gr.IsActive,
+		if !seen[gr.Language+"/"+gr.RuleID] {
+			appliedRules = append(appliedRules, gr.AsRule(""))
 		}
-		appliedRules = append(appliedRules, rule)
 	}
 
-	response := domain.MCPRuleResponse{
-		ProjectID:    params.ProjectID,
-		Language:     params.Language,
+	return domain.MCPRuleResponse{
+		ProjectID:    req.ProjectID,
+		Language:     req.Language,
 		Rules:        projectRules.Rules,
 		GlobalRules:  globalRules,
 		AppliedRules: appliedRules,
-	}
-
-	h.sendMCPResponse(c, req.ID, response)
+		Overrides:    projectRules.Overrides,
+		Packs:        projectRules.Packs,
+	}, nil
 }
 
 // handleValidateCode validateCode MCPメソッドを処理
-func (h *MCPHandler) handleValidateCode(c *gin.Context, req domain.MCPRequest) {
-	var params domain.MCPValidationRequest
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendMCPError(c, req.ID, mcpx.CodeValidation, "Invalid parameters")
-		return
+func (h *MCPHandler) handleValidateCode(_ mcpCaller, params json.RawMessage) (interface{}, error) {
+	var req domain.MCPValidationRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
 	}
 
-	if params.ProjectID == "" || params.Code == "" {
-		h.sendMCPError(c, req.ID, mcpx.CodeValidation, "Project ID and code are required")
-		return
+	if req.ProjectID == "" || req.Code == "" {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID and code are required")
 	}
 
 	// プロジェクトルールに対してコードを検証
-	validationResult, err := h.ruleUseCase.ValidateCode(params.ProjectID, params.Code)
+	validationResult, err := h.ruleUseCase.ValidateFile(req.ProjectID, req.Filename, req.Language, req.Code)
 	if err != nil {
-		code, msg := mcpx.MapAppErrorToMCP(err)
-		h.sendMCPError(c, req.ID, code, "Failed to validate code: "+msg)
-		return
-	}
-
-	// 検証結果をMCP形式に変換
-	issues := make([]domain.ValidationIssue, 0)
-
-	// エラーを検証問題に変換
-	for _, errorMsg := range validationResult.Errors {
-		issue := domain.ValidationIssue{
-			RuleID:   "validation-error",
-			RuleName: "Code Validation Error",
-			Severity: "error",
-			Message:  errorMsg,
-		}
-		issues = append(issues, issue)
-	}
-
-	// 警告を検証問題に変換
-	for _, warningMsg := range validationResult.Warnings {
-		issue := domain.ValidationIssue{
-			RuleID:   "validation-warning",
-			RuleName: "Code Validation Warning",
-			Severity: "warning",
-			Message:  warningMsg,
-		}
-		issues = append(issues, issue)
+		return nil, mcpx.FromError(err, "Failed to validate code: ")
 	}
 
 	// コンテキスト用に適用されたルールを取得
-	projectRules, err := h.ruleUseCase.GetProjectRules(params.ProjectID)
+	projectRules, err := h.ruleUseCase.GetProjectRules(req.ProjectID)
 	if err != nil {
 		projectRules = &domain.ProjectRules{Rules: []domain.Rule{}}
 	}
 
-	response := domain.MCPValidationResponse{
-		IsValid: validationResult.Valid,
-		Issues:  issues,
-		Rules:   projectRules.Rules,
-	}
-
-	h.sendMCPResponse(c, req.ID, response)
+	return domain.MCPValidationResponse{
+		IsValid:    validationResult.Valid,
+		Issues:     validationResult.Issues,
+		Suppressed: validationResult.Suppressed,
+		Rules:      projectRules.Rules,
+	}, nil
 }
 
-// handleGetProjectInfo getProjectInfo MCPメソッドを処理
-func (h *MCPHandler) handleGetProjectInfo(c *gin.Context, req domain.MCPRequest) {
-	var params struct {
-		ProjectID string `json:"project_id"`
-	}
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendMCPError(c, req.ID, mcpx.CodeValidation, "Invalid parameters")
-		return
+// handleValidateFiles validateFiles MCPメソッドを処理
+func (h *MCPHandler) handleValidateFiles(_ mcpCaller, params json.RawMessage) (interface{}, error) {
+	var req domain.MCPValidateFilesRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
 	}
 
-	if params.ProjectID == "" {
-		h.sendMCPError(c, req.ID, mcpx.CodeValidation, "Project ID is required")
-		return
+	if req.ProjectID == "" || len(req.Files) == 0 {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID and files are required")
 	}
 
-	// プロジェクト情報を取得 - このメソッドはusecaseで実装する必要がある
-	// 今のところ、エラーを返す
-	h.sendMCPError(c, req.ID, mcpx.CodeInternal, "getProjectInfo not yet implemented")
-}
-
-// handleAutoDetectProject autoDetectProject MCPメソッドを処理
-func (h *MCPHandler) handleAutoDetectProject(c *gin.Context, req domain.MCPRequest) {
-	var params struct {
-		Path string `json:"path"`
+	result, err := h.ruleUseCase.ValidateFiles(req.ProjectID, req.Files)
+	if err != nil {
+		return nil, mcpx.FromError(err, "Failed to validate files: ")
 	}
+	return result, nil
+}
 
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendMCPError(c, req.ID, mcpx.CodeValidation, "Invalid parameters")
-		return
+// handleValidateDiff validateDiff MCPメソッドを処理
+func (h *MCPHandler) handleValidateDiff(_ mcpCaller, params json.RawMessage) (interface{}, error) {
+	var req domain.MCPValidateDiffRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
 	}
 
-	if params.Path == "" {
-		h.sendMCPError(c, req.ID, mcpx.CodeValidation, "Path is required")
-		return
+	if req.ProjectID == "" || req.Diff == "" {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID and diff are required")
 	}
 
-	// プロジェクトを自動検出
-	result, err := h.projectDetector.AutoDetectProject(params.Path)
+	result, err := h.ruleUseCase.ValidateDiff(req.ProjectID, req.Diff)
 	if err != nil {
-		code, msg := mcpx.MapAppErrorToMCP(err)
-		h.sendMCPError(c, req.ID, code, "Project not found: "+msg)
-		return
+		return nil, mcpx.FromError(err, "Failed to validate diff: ")
 	}
+	return result, nil
+}
 
-	// 結果をJSONに変換
-	resultJSON, err := json.Marshal(result)
-	if err != nil {
-		h.sendMCPError(c, req.ID, mcpx.CodeInternal, "Failed to serialize result")
-		return
+// handleFixCode fixCode MCPメソッドを処理
+func (h *MCPHandler) handleFixCode(_ mcpCaller, params json.RawMessage) (interface{}, error) {
+	var req domain.MCPFixRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
 	}
 
-	response := domain.MCPResponse{
-		ID:     req.ID,
-		Result: resultJSON,
+	if req.ProjectID == "" || req.Code == "" {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID and code are required")
 	}
 
-	c.JSON(http.StatusOK, response)
+	result, err := h.ruleUseCase.FixCode(req.ProjectID, req.Filename, req.Code, req.RuleIDs)
+	if err != nil {
+		return nil, mcpx.FromError(err, "Failed to fix code: ")
+	}
+	return result, nil
 }
 
-// handleScanLocalProjects scanLocalProjects MCPメソッドを処理
-func (h *MCPHandler) handleScanLocalProjects(c *gin.Context, req domain.MCPRequest) {
-	var params struct {
-		BasePath string `json:"base_path"`
+// handleGetProjectInfo getProjectInfo MCPメソッドを処理
+func (h *MCPHandler) handleGetProjectInfo(_ mcpCaller, params json.RawMessage) (interface{}, error) {
+	var req struct {
+		ProjectID string `json:"project_id"`
 	}
-
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendMCPError(c, req.ID, mcpx.CodeValidation, "Invalid parameters")
-		return
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
 	}
 
-	if params.BasePath == "" {
-		params.BasePath = "/" // デフォルトはルートディレクトリ
+	if req.ProjectID == "" {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID is required")
 	}
 
-	// ローカルプロジェクトをスキャン
-	results, err := h.projectDetector.ScanLocalProjects(params.BasePath)
+	info, err := h.ruleUseCase.GetProjectInfo(req.ProjectID)
 	if err != nil {
-		code, msg := mcpx.MapAppErrorToMCP(err)
-		h.sendMCPError(c, req.ID, code, "Failed to scan local projects: "+msg)
-		return
+		return nil, mcpx.FromError(err, "Failed to get project info: ")
 	}
+	return info, nil
+}
 
-	// 結果をJSONに変換
-	responseData := gin.H{
-		"projects": results,
-		"count":    len(results),
+// handleAutoDetectProject autoDetectProject MCPメソッドを処理
+func (h *MCPHandler) handleAutoDetectProject(caller mcpCaller, params json.RawMessage) (interface{}, error) {
+	var req struct {
+		Path string `json:"path"`
 	}
-
-	responseJSON, err := json.Marshal(responseData)
-	if err != nil {
-		h.sendMCPError(c, req.ID, mcpx.CodeInternal, "Failed to serialize results")
-		return
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
 	}
 
-	response := domain.MCPResponse{
-		ID:     req.ID,
-		Result: responseJSON,
+	if req.Path == "" {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Path is required")
 	}
 
-	c.JSON(http.StatusOK, response)
-}
-
-// sendMCPResponse 成功したMCPレスポンスを送信
-func (h *MCPHandler) sendMCPResponse(c *gin.Context, id string, result interface{}) {
-	resultJSON, err := json.Marshal(result)
+	// プロジェクトを自動検出
+	result, err := h.projectDetector.AutoDetectProject(req.Path)
 	if err != nil {
-		h.sendMCPError(c, id, mcpx.CodeInternal, "Failed to marshal response")
-		return
+		return nil, mcpx.FromError(err, "Project not found: ")
+	}
+	if !h.canViewProject(caller, result.Project.ProjectID) {
+		return nil, mcpx.NewError(mcpx.CodeNotFound, "Project not found: "+req.Path)
 	}
+	return result, nil
+}
 
-	response := domain.MCPResponse{
-		ID:     id,
-		Result: resultJSON,
+// handleScanLocalProjects scanLocalProjects MCPメソッドを処理
+func (h *MCPHandler) handleScanLocalProjects(caller mcpCaller, params json.RawMessage) (interface{}, error) {
+	var req struct {
+		BasePath string `json:"base_path"`
+	}
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
 	}
 
-	c.JSON(http.StatusOK, response)
-}
+	if req.BasePath == "" {
+		req.BasePath = "/" // デフォルトはルートディレクトリ
+	}
 
-// sendMCPError MCPエラーレスポンスを送信
-func (h *MCPHandler) sendMCPError(c *gin.Context, id string, code int, message string) {
-	response := domain.MCPResponse{
-		ID: id,
-		Error: &domain.MCPError{
-			Code:    code,
-			Message: message,
-		},
+	// ローカルプロジェクトをスキャン
+	results, err := h.projectDetector.ScanLocalProjects(req.BasePath)
+	if err != nil {
+		return nil, mcpx.FromError(err, "Failed to scan local projects: ")
+	}
+	visible := results[:0]
+	for _, result := range results {
+		if h.canViewProject(caller, result.Project.ProjectID) {
+			visible = append(visible, result)
+		}
 	}
+	results = visible
 
-	c.JSON(http.StatusOK, response)
+	return gin.H{
+		"projects": results,
+		"count":    len(results),
+	}, nil
 }
 
 // HandleWebSocket リアルタイムMCP通信のためのWebSocket接続を処理
+// メッセージはHTTPと同じJSON-RPC 2.0 形式（単一・バッチ）で処理し、
+// rules/subscribe で購読したプロジェクトのルール更新通知を同じ接続へ送る
 func (h *MCPHandler) HandleWebSocket(c *gin.Context) {
 	// WebSocket接続にアップグレード
-	upgrader := websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true // 開発用にすべてのオリジンを許可
-		},
-	}
-
+	upgrader := websocket.Upgrader{CheckOrigin: h.checkOrigin}
 	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
 	if err != nil {
 		c.Error(err)
@@ -444,179 +489,63 @@ func (h *MCPHandler) HandleWebSocket(c *gin.Context) {
 	}
 	defer conn.Close()
 
-	// WebSocketメッセージを処理
-	for {
-		var req domain.MCPRequest
-		err := conn.ReadJSON(&req)
-		if err != nil {
-			break
-		}
-
-		// MCPリクエストを処理
-		h.processWebSocketRequest(conn, req)
-	}
-}
-
-// processWebSocketRequest WebSocket経由でMCPリクエストを処理
-func (h *MCPHandler) processWebSocketRequest(conn *websocket.Conn, req domain.MCPRequest) {
-	switch req.Method {
-	case "getRules":
-		h.handleWebSocketGetRules(conn, req)
-	case "validateCode":
-		h.handleWebSocketValidateCode(conn, req)
-	default:
-		h.sendWebSocketError(conn, req.ID, 404, "Method not found: "+req.Method)
-	}
-}
-
-// handleWebSocketGetRules WebSocket経由でgetRulesを処理
-func (h *MCPHandler) handleWebSocketGetRules(conn *websocket.Conn, req domain.MCPRequest) {
-	var params domain.MCPRuleRequest
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendWebSocketError(conn, req.ID, 400, "Invalid parameters")
-		return
-	}
-
-	if params.ProjectID == "" {
-		h.sendWebSocketError(conn, req.ID, 400, "Project ID is required")
-		return
-	}
-
-	// プロジェクトルールを取得
-	projectRules, err := h.ruleUseCase.GetProjectRules(params.ProjectID)
+	// 認証情報はアップグレード要求のものを接続全体で使う
+	caller := callerFromContext(c)
+	session, err := h.sessions.create(caller)
 	if err != nil {
-		h.sendWebSocketError(conn, req.ID, 500, "Failed to get project rules: "+err.Error())
 		return
 	}
-
-	// 言語が指定されている場合はグローバルルールを取得
-	var globalRules []domain.GlobalRule
-	if params.Language != "" {
-		globalRulesPtr, err := h.globalRuleUseCase.GetGlobalRules(params.Language)
-		if err == nil {
-			// ポインタスライスを値スライスに変換
-			globalRules = make([]domain.GlobalRule, len(globalRulesPtr))
-			for i, gr := range globalRulesPtr {
-				globalRules[i] = *gr
+	session.attach()
+	defer h.sessions.remove(session.id)
+
+	// gorilla/websocket は並行書き込みに対応しないため、応答と通知の送信を直列化する
+	var writeMu sync.Mutex
+	write := func(messageType int, data []byte) error {
+		writeMu.Lock()
+		defer writeMu.Unlock()
+		return conn.WriteMessage(messageType, data)
+	}
+
+	go func() {
+		for {
+			select {
+			case <-session.closed:
+				return
+			case msg := <-session.events:
+				if write(websocket.TextMessage, msg) != nil {
+					return
+				}
 			}
 		}
-	}
-
-	// プロジェクトルールとグローバルルールを結合
-	appliedRules := make([]domain.Rule, 0, len(projectRules.Rules)+len(globalRules))
-	appliedRules = append(appliedRules, projectRules.Rules...)
+	}()
 
-	// グローバルルールをプロジェクトルール形式に変換
-	for _, gr := range globalRules {
-		rule := domain.Rule{
-			RuleID:      gr.RuleID,
-			Name:        gr.Name,
-			Description: gr.Description,
-			Type:        gr.Type,
-			Severity:    gr.Severity,
-			Pattern:     gr.Pattern,
-			Message:     gr.Message,
-			IsActive:    gr.IsActive,
+	// WebSocketメッセージを処理
+	for {
+		_, message, err := conn.ReadMessage()
+		if err != nil {
+			break
 		}
-		appliedRules = append(appliedRules, rule)
-	}
-
-	response := domain.MCPRuleResponse{
-		ProjectID:    params.ProjectID,
-		Language:     params.Language,
-		Rules:        projectRules.Rules,
-		GlobalRules:  globalRules,
-		AppliedRules: appliedRules,
-	}
-
-	h.sendWebSocketResponse(conn, req.ID, response)
-}
-
-// handleWebSocketValidateCode WebSocket経由でvalidateCodeを処理
-func (h *MCPHandler) handleWebSocketValidateCode(conn *websocket.Conn, req domain.MCPRequest) {
-	var params domain.MCPValidationRequest
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendWebSocketError(conn, req.ID, 400, "Invalid parameters")
-		return
-	}
-
-	if params.ProjectID == "" || params.Code == "" {
-		h.sendWebSocketError(conn, req.ID, 400, "Project ID and code are required")
-		return
-	}
-
-	// プロジェクトルールに対してコードを検証
-	validationResult, err := h.ruleUseCase.ValidateCode(params.ProjectID, params.Code)
-	if err != nil {
-		h.sendWebSocketError(conn, req.ID, 500, "Failed to validate code: "+err.Error())
-		return
-	}
-
-	// 検証結果をMCP形式に変換
-	issues := make([]domain.ValidationIssue, 0)
 
-	// エラーを検証問題に変換
-	for _, errorMsg := range validationResult.Errors {
-		issue := domain.ValidationIssue{
-			RuleID:   "validation-error",
-			RuleName: "Code Validation Error",
-			Severity: "error",
-			Message:  errorMsg,
+		reply, ok := h.handlePayload(caller, session, message)
+		if !ok {
+			continue
 		}
-		issues = append(issues, issue)
-	}
-
-	// 警告を検証問題に変換
-	for _, warningMsg := range validationResult.Warnings {
-		issue := domain.ValidationIssue{
-			RuleID:   "validation-warning",
-			RuleName: "Code Validation Warning",
-			Severity: "warning",
-			Message:  warningMsg,
+		data, err := json.Marshal(reply)
+		if err != nil {
+			break
+		}
+		if err := write(websocket.TextMessage, data); err != nil {
+			break
 		}
-		issues = append(issues, issue)
-	}
-
-	// コンテキスト用に適用されたルールを取得
-	projectRules, err := h.ruleUseCase.GetProjectRules(params.ProjectID)
-	if err != nil {
-		projectRules = &domain.ProjectRules{Rules: []domain.Rule{}}
-	}
-
-	response := domain.MCPValidationResponse{
-		IsValid: validationResult.Valid,
-		Issues:  issues,
-		Rules:   projectRules.Rules,
-	}
-
-	h.sendWebSocketResponse(conn, req.ID, response)
-}
-
-// sendWebSocketResponse WebSocket経由でレスポンスを送信
-func (h *MCPHandler) sendWebSocketResponse(conn *websocket.Conn, id string, result interface{}) {
-	resultJSON, err := json.Marshal(result)
-	if err != nil {
-		h.sendWebSocketError(conn, id, 500, "Failed to marshal response")
-		return
-	}
-
-	response := domain.MCPResponse{
-		ID:     id,
-		Result: resultJSON,
 	}
-
-	conn.WriteJSON(response)
 }
 
-// sendWebSocketError WebSocket経由でエラーを送信
-func (h *MCPHandler) sendWebSocketError(conn *websocket.Conn, id string, code int, message string) {
-	response := domain.MCPResponse{
-		ID: id,
-		Error: &domain.MCPError{
-			Code:    code,
-			Message: message,
-		},
+// checkOrigin WebSocketのOriginを ALLOWED_ORIGINS で検証
+// Originを送らないクライアント（ブラウザ以外）と、許可リスト未設定時（開発環境）は許可する
+func (h *MCPHandler) checkOrigin(r *http.Request) bool {
+	origin := r.Header.Get("Origin")
+	if origin == "" || len(h.allowedOrigins) == 0 {
+		return true
 	}
-
-	conn.WriteJSON(response)
+	return httpx.OriginAllowed(h.allowedOrigins, origin)
 }
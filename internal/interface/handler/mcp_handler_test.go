@@ -0,0 +1,269 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/infrastructure/rulesfile"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/mcpx"
+	"github.com/gin-gonic/gin"
+)
+
+func postMCP(t *testing.T, body string) *httptest.ResponseRecorder {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	r.POST("/mcp/request", NewMCPHandler(nil, nil, nil).HandleMCPRequest)
+
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodPost, "/mcp/request", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func decodeResponse(t *testing.T, w *httptest.ResponseRecorder) domain.MCPResponse {
+	t.Helper()
+	var resp domain.MCPResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %q: %v", w.Body.String(), err)
+	}
+	if resp.JSONRPC != domain.JSONRPCVersion {
+		t.Errorf("jsonrpc = %q, want %q", resp.JSONRPC, domain.JSONRPCVersion)
+	}
+	return resp
+}
+
+func TestHandleMCPRequestInitialize(t *testing.T) {
+	w := postMCP(t, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2024-11-05","capabilities":{},"clientInfo":{"name":"test","version":"0"}}}`)
+	resp := decodeResponse(t, w)
+	if string(resp.ID) != "1" {
+		t.Errorf("id = %s, want numeric 1", resp.ID)
+	}
+	if resp.Error != nil {
+		t.Fatalf("unexpected error: %+v", resp.Error)
+	}
+
+	var result domain.MCPInitializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.ProtocolVersion != "2024-11-05" {
+		t.Errorf("protocolVersion = %q, want the client's supported version", result.ProtocolVersion)
+	}
+	if _, ok := result.Capabilities["tools"]; !ok {
+		t.Errorf("capabilities = %v, want tools", result.Capabilities)
+	}
+	if result.ServerInfo.Name != mcpServerName {
+		t.Errorf("serverInfo = %+v", result.ServerInfo)
+	}
+}
+
+func TestHandleMCPRequestUnknownProtocolVersion(t *testing.T) {
+	w := postMCP(t, `{"jsonrpc":"2.0","id":"init","method":"initialize","params":{"protocolVersion":"1999-01-01"}}`)
+	resp := decodeResponse(t, w)
+	var result domain.MCPInitializeResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if result.ProtocolVersion != supportedProtocolVersions[0] {
+		t.Errorf("protocolVersion = %q, want latest %q", result.ProtocolVersion, supportedProtocolVersions[0])
+	}
+	if string(resp.ID) != `"init"` {
+		t.Errorf("id = %s, want string id", resp.ID)
+	}
+}
+
+func TestHandleMCPRequestNotification(t *testing.T) {
+	w := postMCP(t, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if w.Code != http.StatusAccepted {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusAccepted)
+	}
+	if w.Body.Len() != 0 {
+		t.Errorf("body = %q, want empty", w.Body.String())
+	}
+}
+
+func TestHandleMCPRequestBatch(t *testing.T) {
+	w := postMCP(t, `[
+		{"jsonrpc":"2.0","id":"a","method":"ping"},
+		{"jsonrpc":"2.0","method":"notifications/initialized"},
+		{"jsonrpc":"2.0","id":2,"method":"noSuchMethod"},
+		42
+	]`)
+	var responses []domain.MCPResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("invalid batch response %q: %v", w.Body.String(), err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("got %d responses, want 3 (notifications get none): %s", len(responses), w.Body.String())
+	}
+	if string(responses[0].ID) != `"a"` || responses[0].Error != nil {
+		t.Errorf("ping response = %+v", responses[0])
+	}
+	if string(responses[1].ID) != "2" || responses[1].Error == nil || responses[1].Error.Code != mcpx.CodeMethodNotFound {
+		t.Errorf("unknown method response = %+v, want code %d", responses[1], mcpx.CodeMethodNotFound)
+	}
+	if string(responses[2].ID) != "null" || responses[2].Error == nil || responses[2].Error.Code != mcpx.CodeInvalidRequest {
+		t.Errorf("invalid element response = %+v, want code %d with null id", responses[2], mcpx.CodeInvalidRequest)
+	}
+}
+
+func TestHandleMCPRequestErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+		code int
+	}{
+		{"parse error", `{"jsonrpc":"2.0","id":1,`, mcpx.CodeParseError},
+		{"empty batch", `[]`, mcpx.CodeInvalidRequest},
+		{"wrong version", `{"jsonrpc":"1.0","id":1,"method":"ping"}`, mcpx.CodeInvalidRequest},
+		{"missing method", `{"jsonrpc":"2.0","id":1}`, mcpx.CodeInvalidRequest},
+		{"object id", `{"jsonrpc":"2.0","id":{},"method":"ping"}`, mcpx.CodeInvalidRequest},
+		{"invalid params", `{"jsonrpc":"2.0","id":1,"method":"getRules","params":"x"}`, mcpx.CodeInvalidParams},
+		{"missing project", `{"jsonrpc":"2.0","id":1,"method":"getRules","params":{}}`, mcpx.CodeInvalidParams},
+		{"unknown tool", `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"nope"}}`, mcpx.CodeInvalidParams},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := decodeResponse(t, postMCP(t, tt.body))
+			if resp.Error == nil || resp.Error.Code != tt.code {
+				t.Errorf("error = %+v, want code %d", resp.Error, tt.code)
+			}
+		})
+	}
+}
+
+func TestHandleMCPRequestToolsCall(t *testing.T) {
+	// ツールの実行エラーはJSON-RPCエラーではなく isError で返る
+	w := postMCP(t, `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"validateCode","arguments":{"project_id":"p"}}}`)
+	resp := decodeResponse(t, w)
+	if resp.Error != nil {
+		t.Fatalf("unexpected JSON-RPC error: %+v", resp.Error)
+	}
+	var result domain.MCPToolCallResult
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || len(result.Content) != 1 || !strings.Contains(result.Content[0].Text, "code are required") {
+		t.Errorf("result = %+v, want isError with message", result)
+	}
+}
+
+func TestHandleMCPRequestToolsList(t *testing.T) {
+	resp := decodeResponse(t, postMCP(t, `{"id":"legacy","method":"tools/list"}`))
+	var result struct {
+		Tools []domain.MCPTool `json:"tools"`
+	}
+	if err := json.Unmarshal(resp.Result, &result); err != nil {
+		t.Fatal(err)
+	}
+
+	// 一覧はレジストリから生成され、匿名の呼び出し元には権限の必要なツールが載らない
+	registry := NewMCPHandler(nil, nil, nil).tools
+	want := registry.list(mcpCaller{})
+	if len(result.Tools) != len(want) || len(want) == len(registry.tools) {
+		t.Fatalf("tools/list has %d tools, want %d of %d", len(result.Tools), len(want), len(registry.tools))
+	}
+	for i, tool := range result.Tools {
+		if tool.Name != want[i].Name || tool.InputSchema["type"] != "object" {
+			t.Errorf("tools[%d] = %+v, want %q", i, tool, want[i].Name)
+		}
+	}
+}
+
+func TestHandleMCPRequestToolPermission(t *testing.T) {
+	for _, body := range []string{
+		`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"scanLocalProjects","arguments":{}}}`,
+		`{"jsonrpc":"2.0","id":1,"method":"scanLocalProjects","params":{}}`,
+	} {
+		resp := decodeResponse(t, postMCP(t, body))
+		if resp.Error == nil || resp.Error.Code != mcpx.CodeForbidden {
+			t.Errorf("%s: error = %+v, want forbidden", body, resp.Error)
+		}
+	}
+}
+
+func TestMCPProjectScope(t *testing.T) {
+	f := newStreamableFixture(t)
+	caller := mcpCaller{projectScope: []string{"web"}}
+
+	for _, tt := range []struct{ method, params string }{
+		{"tools/call", `{"name":"getRules","arguments":{"project_id":"other"}}`},
+		{"getRules", `{"project_id":"other"}`},
+		{"rules/subscribe", `{"project_ids":["web","other"]}`},
+	} {
+		_, err := f.handler.dispatch(caller, &mcpSession{projects: map[string]bool{}}, tt.method, json.RawMessage(tt.params))
+		if e, ok := err.(*mcpx.Error); !ok || e.Code != mcpx.CodeForbidden {
+			t.Errorf("%s %s: error = %v, want forbidden", tt.method, tt.params, err)
+		}
+	}
+	if _, err := f.handler.dispatch(caller, nil, "getRules", json.RawMessage(`{"project_id":"web"}`)); err != nil {
+		t.Errorf("project in scope: %v", err)
+	}
+}
+
+func TestMCPProjectAccessLevel(t *testing.T) {
+	store, err := rulesfile.Parse([]byte(`{
+		"projects": {
+			"web": {"language": "go", "rules": []},
+			"client": {"language": "go", "access_level": "private", "rules": [{"id": "secret", "pattern": "TODO"}]}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewMCPHandler(usecase.NewRuleUseCase(store.Rules(), store.GlobalRules(), store.Projects()), nil, nil)
+	h.SetProjectAccess(usecase.NewProjectAccessUseCase(store.Projects()))
+
+	anonymous := mcpCaller{role: "public"}
+	for _, tt := range []struct{ method, params string }{
+		{"tools/call", `{"name":"getRules","arguments":{"project_id":"client"}}`},
+		{"getRules", `{"project_id":"client"}`},
+		{"rules/subscribe", `{"project_id":"client"}`},
+	} {
+		_, err := h.dispatch(anonymous, &mcpSession{projects: map[string]bool{}}, tt.method, json.RawMessage(tt.params))
+		if e, ok := err.(*mcpx.Error); !ok || e.Code != mcpx.CodeNotFound {
+			t.Errorf("%s %s: error = %v, want not found", tt.method, tt.params, err)
+		}
+	}
+	if _, err := h.dispatch(anonymous, nil, "getRules", json.RawMessage(`{"project_id":"web"}`)); err != nil {
+		t.Errorf("public project: %v", err)
+	}
+	admin := mcpCaller{role: "admin", authenticated: true}
+	if _, err := h.dispatch(admin, nil, "getRules", json.RawMessage(`{"project_id":"client"}`)); err != nil {
+		t.Errorf("admin on private project: %v", err)
+	}
+}
+
+func TestMCPValidateCodeSnippetUsesLanguage(t *testing.T) {
+	store, err := rulesfile.Parse([]byte(`{
+		"projects": {"mono": {"rules": [], "languages": [
+			{"language": "go", "paths": ["backend/**"]},
+			{"language": "typescript", "paths": ["frontend/**"]}
+		]}},
+		"global_rules": {
+			"go": [{"id": "no-println", "pattern": "fmt\\.Println", "severity": "error"}],
+			"typescript": [{"id": "no-console-log", "pattern": "console\\.log", "severity": "warning"}]
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := NewMCPHandler(usecase.NewRuleUseCase(store.Rules(), store.GlobalRules(), store.Projects()), nil, nil)
+
+	// ファイル名の無いスニペットでも、指定した言語のグローバルルールだけを適用する
+	result, err := h.dispatch(mcpCaller{}, nil, "validateCode", json.RawMessage(`{"project_id":"mono","language":"typescript","code":"console.log(x)\nfmt.Println(x)"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp := result.(domain.MCPValidationResponse)
+	if len(resp.Issues) != 1 || resp.Issues[0].RuleID != "no-console-log" {
+		t.Errorf("issues = %+v, want only the typescript rule", resp.Issues)
+	}
+}
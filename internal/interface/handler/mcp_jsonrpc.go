@@ -0,0 +1,306 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/mcpx"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	mcpServerName    = "rule-mcp-server"
+	mcpServerVersion = "1.0.0"
+)
+
+// supportedProtocolVersions 対応するMCPプロトコルバージョン（先頭が最新）
+var supportedProtocolVersions = []string{"2025-06-18", "2025-03-26", "2024-11-05"}
+
+// mcpMethod MCPメソッドの実装（エラーは *mcpx.Error またはアプリケーションエラーで返す）
+// caller は結果を呼び出し元が扱えるプロジェクトに絞り込む場合に使う
+type mcpMethod func(caller mcpCaller, params json.RawMessage) (interface{}, error)
+
+// HandleMCPRequest JSON-RPC 2.0 形式のMCPリクエスト（単一・バッチ）を処理
+func (h *MCPHandler) HandleMCPRequest(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusOK, rpcError(nil, mcpx.NewError(mcpx.CodeParseError, "Failed to read request body")))
+		return
+	}
+
+	reply, ok := h.handlePayload(callerFromContext(c), nil, body)
+	if !ok {
+		// 通知のみの場合は応答本文を返さない
+		c.Status(http.StatusAccepted)
+		return
+	}
+	c.JSON(http.StatusOK, reply)
+}
+
+// handlePayload 受信したJSONを処理して返すべき応答（単一またはバッチ）を返す
+// 通知のみで応答が不要な場合はfalseを返す。セッションを伴うトランスポート（Streamable HTTP・WebSocket）では
+// rules/subscribe・rules/unsubscribe も受け付ける
+func (h *MCPHandler) handlePayload(caller mcpCaller, session *mcpSession, body []byte) (interface{}, bool) {
+	body = bytes.TrimSpace(body)
+	if !json.Valid(body) {
+		return rpcError(nil, mcpx.NewError(mcpx.CodeParseError, "Parse error")), true
+	}
+
+	if body[0] != '[' {
+		resp := h.handleMessage(caller, session, body)
+		if resp == nil {
+			return nil, false
+		}
+		return resp, true
+	}
+
+	var batch []json.RawMessage
+	if err := json.Unmarshal(body, &batch); err != nil || len(batch) == 0 {
+		return rpcError(nil, mcpx.NewError(mcpx.CodeInvalidRequest, "Invalid Request")), true
+	}
+	responses := make([]*domain.MCPResponse, 0, len(batch))
+	for _, msg := range batch {
+		if resp := h.handleMessage(caller, session, msg); resp != nil {
+			responses = append(responses, resp)
+		}
+	}
+	if len(responses) == 0 {
+		return nil, false
+	}
+	return responses, true
+}
+
+// handleMessage 1件のJSON-RPCメッセージを処理（通知の場合はnilを返す）
+func (h *MCPHandler) handleMessage(caller mcpCaller, session *mcpSession, msg json.RawMessage) *domain.MCPResponse {
+	var req domain.MCPRequest
+	if err := json.Unmarshal(msg, &req); err != nil {
+		return rpcError(nil, mcpx.NewError(mcpx.CodeInvalidRequest, "Invalid Request"))
+	}
+	// jsonrpc を省略した従来形式のリクエストも受け付ける
+	if (req.JSONRPC != "" && req.JSONRPC != domain.JSONRPCVersion) || req.Method == "" || !validRequestID(req.ID) {
+		id := req.ID
+		if !validRequestID(id) {
+			id = nil
+		}
+		return rpcError(id, mcpx.NewError(mcpx.CodeInvalidRequest, "Invalid Request"))
+	}
+
+	result, err := h.dispatch(caller, session, req.Method, req.Params)
+	if req.IsNotification() {
+		return nil
+	}
+	if err != nil {
+		return rpcError(req.ID, mcpx.FromError(err, ""))
+	}
+	return rpcResult(req.ID, result)
+}
+
+// dispatch メソッド名に対応する処理を実行
+func (h *MCPHandler) dispatch(caller mcpCaller, session *mcpSession, method string, params json.RawMessage) (interface{}, error) {
+	// クライアントからの通知（notifications/initialized など）は受け取るのみ
+	if strings.HasPrefix(method, "notifications/") {
+		return struct{}{}, nil
+	}
+
+	switch method {
+	case "initialize":
+		return h.handleInitialize(params)
+	case "ping":
+		return struct{}{}, nil
+	case "tools/list":
+		return h.invoke(caller, method, h.handleToolsList, params)
+	case "tools/call":
+		return h.handleToolsCall(caller, params)
+	case "rules/subscribe", "rules/unsubscribe":
+		if session != nil && h.ruleUseCase != nil {
+			return h.handleRuleSubscription(caller, session, method == "rules/subscribe", params)
+		}
+	}
+
+	// 従来のメソッド名による直接呼び出し
+	tool, ok := h.tools.lookup(method)
+	if !ok {
+		return nil, mcpx.NewError(mcpx.CodeMethodNotFound, "Method not found: "+method)
+	}
+	if !caller.allowed(tool.permission) {
+		return nil, toolForbidden(tool)
+	}
+	if err := h.authorizeProject(caller, argumentProjectID(params)); err != nil {
+		return nil, err
+	}
+	return h.invoke(caller, method, tool.handler, params)
+}
+
+// toolForbidden 権限が不足している場合のエラー
+func toolForbidden(tool *mcpTool) *mcpx.Error {
+	e := mcpx.NewError(mcpx.CodeForbidden, "Permission denied: "+tool.name)
+	e.Data = map[string]interface{}{"required_permission": tool.permission}
+	return e
+}
+
+// projectForbidden プロジェクトスコープ外のプロジェクトを指定した場合のエラー
+func projectForbidden(projectID string) *mcpx.Error {
+	e := mcpx.NewError(mcpx.CodeForbidden, "Access denied to project: "+projectID)
+	e.Data = map[string]interface{}{"project_id": projectID}
+	return e
+}
+
+// authorizeProject 呼び出し元がプロジェクトを閲覧できるか確認（projectID が空なら何もしない）
+// 閲覧できないプロジェクトは存在を明かさないため NotFound を返す
+func (h *MCPHandler) authorizeProject(caller mcpCaller, projectID string) *mcpx.Error {
+	if projectID == "" || caller.local {
+		return nil
+	}
+	if h.projectAccess == nil {
+		if !caller.canAccessProject(projectID) {
+			return projectForbidden(projectID)
+		}
+		return nil
+	}
+	if _, err := h.projectAccess.Authorize(caller.principal(), projectID, domain.ProjectRoleViewer); err != nil {
+		return mcpx.FromError(err, "")
+	}
+	return nil
+}
+
+// canViewProject 呼び出し元がプロジェクトを閲覧できるか判定
+func (h *MCPHandler) canViewProject(caller mcpCaller, projectID string) bool {
+	return h.authorizeProject(caller, projectID) == nil
+}
+
+// argumentProjectID ツール引数の project_id（無ければ空）
+func argumentProjectID(args json.RawMessage) string {
+	var target struct {
+		ProjectID string `json:"project_id"`
+	}
+	if len(args) == 0 || json.Unmarshal(args, &target) != nil {
+		return ""
+	}
+	return target.ProjectID
+}
+
+// invoke メトリクスを記録しながらメソッドを実行
+func (h *MCPHandler) invoke(caller mcpCaller, method string, fn mcpMethod, params json.RawMessage) (interface{}, error) {
+	var result interface{}
+	var err error
+	h.withMetrics(method, func() error {
+		result, err = fn(caller, params)
+		return err
+	})
+	return result, err
+}
+
+// handleInitialize initializeハンドシェイクを処理
+func (h *MCPHandler) handleInitialize(params json.RawMessage) (interface{}, error) {
+	var req domain.MCPInitializeRequest
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+
+	// クライアントの要求バージョンに対応していればそれを、そうでなければ最新を返す
+	version := supportedProtocolVersions[0]
+	for _, v := range supportedProtocolVersions {
+		if v == req.ProtocolVersion {
+			version = v
+			break
+		}
+	}
+
+	return domain.MCPInitializeResult{
+		ProtocolVersion: version,
+		Capabilities: map[string]interface{}{
+			"tools": map[string]interface{}{"listChanged": false},
+			// Streamable HTTP のセッションでは notifications/rules/updated を送信する
+			"experimental": map[string]interface{}{
+				domain.MCPRuleUpdatedMethod: map[string]interface{}{},
+			},
+		},
+		ServerInfo:   domain.MCPImplementation{Name: mcpServerName, Version: mcpServerVersion},
+		Instructions: "Use getRules to fetch the coding rules of a project, and validateCode, validateFiles or validateDiff to check code against them.",
+	}, nil
+}
+
+// handleToolsCall tools/call を処理
+// 未知のツール・権限不足はJSON-RPCエラー、ツール実行時のエラーは isError 付きの結果として返す
+func (h *MCPHandler) handleToolsCall(caller mcpCaller, params json.RawMessage) (interface{}, error) {
+	var call domain.MCPToolCallRequest
+	if err := decodeParams(params, &call); err != nil {
+		return nil, err
+	}
+	if call.Name == "" {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Tool name is required")
+	}
+	tool, ok := h.tools.lookup(call.Name)
+	if !ok {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Unknown tool: "+call.Name)
+	}
+	if !caller.allowed(tool.permission) {
+		return nil, toolForbidden(tool)
+	}
+	if err := h.authorizeProject(caller, argumentProjectID(call.Arguments)); err != nil {
+		return nil, err
+	}
+
+	result, err := h.invoke(caller, call.Name, tool.handler, call.Arguments)
+	if err != nil {
+		e := mcpx.FromError(err, "")
+		return domain.MCPToolCallResult{
+			Content: []domain.MCPContent{{Type: "text", Text: e.Message}},
+			IsError: true,
+		}, nil
+	}
+
+	text, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, mcpx.NewError(mcpx.CodeInternalError, "Failed to marshal tool result")
+	}
+	return domain.MCPToolCallResult{
+		Content:           []domain.MCPContent{{Type: "text", Text: string(text)}},
+		StructuredContent: result,
+	}, nil
+}
+
+// decodeParams パラメータをデコード（省略時は何もしない）
+func decodeParams(params json.RawMessage, v interface{}) error {
+	if len(params) == 0 || string(params) == "null" {
+		return nil
+	}
+	if err := json.Unmarshal(params, v); err != nil {
+		return mcpx.NewError(mcpx.CodeInvalidParams, "Invalid parameters")
+	}
+	return nil
+}
+
+// validRequestID IDが文字列・数値・nullのいずれか（または省略）か判定
+func validRequestID(id json.RawMessage) bool {
+	if len(id) == 0 {
+		return true
+	}
+	switch c := id[0]; {
+	case c == '"', c == 'n', c == '-':
+		return true
+	default:
+		return c >= '0' && c <= '9'
+	}
+}
+
+// rpcResult 成功レスポンスを作成
+func rpcResult(id json.RawMessage, result interface{}) *domain.MCPResponse {
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return rpcError(id, mcpx.NewError(mcpx.CodeInternalError, "Failed to marshal response"))
+	}
+	return &domain.MCPResponse{JSONRPC: domain.JSONRPCVersion, ID: id, Result: resultJSON}
+}
+
+// rpcError エラーレスポンスを作成（IDが特定できない場合はnull）
+func rpcError(id json.RawMessage, e *mcpx.Error) *domain.MCPResponse {
+	return &domain.MCPResponse{
+		JSONRPC: domain.JSONRPCVersion,
+		ID:      id,
+		Error:   &domain.MCPError{Code: e.Code, Message: e.Message, Data: e.Data},
+	}
+}
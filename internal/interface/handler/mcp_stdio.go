@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"io"
+)
+
+// maxStdioMessageSize stdioで受け付ける1メッセージ（1行）の上限
+const maxStdioMessageSize = 64 << 20
+
+// ServeStdio 改行区切りのJSON-RPCメッセージをrから読み、応答をwへ1行ずつ書き込む
+// MCPのstdioトランスポート用で、HTTPと同じディスパッチャーを使用する。rが閉じられると終了する
+func (h *MCPHandler) ServeStdio(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), maxStdioMessageSize)
+	out := bufio.NewWriter(w)
+	enc := json.NewEncoder(out)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		reply, ok := h.handlePayload(localCaller(), nil, line)
+		if !ok {
+			continue
+		}
+		// Encodeは末尾に改行を付けるため、そのまま1メッセージ1行になる
+		if err := enc.Encode(reply); err != nil {
+			return err
+		}
+		if err := out.Flush(); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,49 @@
+package handler
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/mcpx"
+)
+
+func TestServeStdio(t *testing.T) {
+	in := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/list"}`,
+		`not json`,
+	}, "\n")
+
+	var out bytes.Buffer
+	if err := NewMCPHandler(nil, nil, nil).ServeStdio(strings.NewReader(in), &out); err != nil {
+		t.Fatal(err)
+	}
+
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 3 {
+		t.Fatalf("got %d lines, want 3 (no reply to the notification):\n%s", len(lines), out.String())
+	}
+
+	var responses []domain.MCPResponse
+	for _, line := range lines {
+		var resp domain.MCPResponse
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("invalid line %q: %v", line, err)
+		}
+		responses = append(responses, resp)
+	}
+	if string(responses[0].ID) != "1" || responses[0].Error != nil {
+		t.Errorf("initialize response = %+v", responses[0])
+	}
+	if string(responses[1].ID) != "2" || responses[1].Error != nil {
+		t.Errorf("tools/list response = %+v", responses[1])
+	}
+	if responses[2].Error == nil || responses[2].Error.Code != mcpx.CodeParseError {
+		t.Errorf("parse error response = %+v", responses[2])
+	}
+}
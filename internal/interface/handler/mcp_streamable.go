@@ -0,0 +1,450 @@
+package handler
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/mcpx"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// mcpSessionHeader Streamable HTTP のセッションIDヘッダー
+	mcpSessionHeader = "Mcp-Session-Id"
+	// mcpSessionTTL ストリームを開いていないセッションを破棄するまでの時間
+	mcpSessionTTL = time.Hour
+	// mcpSessionBuffer セッションごとに保持する未送信の通知数
+	mcpSessionBuffer = 32
+	// sseKeepAlive SSEストリームのキープアライブ間隔
+	sseKeepAlive = 25 * time.Second
+)
+
+// mcpSession Streamable HTTP・WebSocket のセッション（ルール更新通知の購読先）
+type mcpSession struct {
+	id     string
+	events chan []byte
+	closed chan struct{}
+	// caller セッションを開始した呼び出し元。通知のたびにプロジェクトを閲覧できるか確認する
+	caller mcpCaller
+
+	mu         sync.Mutex
+	projects   map[string]bool // 通知対象のプロジェクト
+	streams    int
+	lastActive time.Time
+}
+
+// watch 通知対象のプロジェクトを追加
+func (s *mcpSession) watch(projectIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range projectIDs {
+		if id != "" {
+			s.projects[id] = true
+		}
+	}
+}
+
+// unwatch 通知対象からプロジェクトを外す
+func (s *mcpSession) unwatch(projectIDs ...string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, id := range projectIDs {
+		delete(s.projects, id)
+	}
+}
+
+// watched 通知対象のプロジェクト一覧
+func (s *mcpSession) watched() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	projectIDs := make([]string, 0, len(s.projects))
+	for id := range s.projects {
+		projectIDs = append(projectIDs, id)
+	}
+	sort.Strings(projectIDs)
+	return projectIDs
+}
+
+// attach 通知を受け取る接続（SSEストリーム・WebSocket）の開始
+func (s *mcpSession) attach() {
+	s.mu.Lock()
+	s.streams++
+	s.mu.Unlock()
+}
+
+// detach 通知を受け取る接続の終了
+func (s *mcpSession) detach() {
+	s.mu.Lock()
+	s.streams--
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+}
+
+func (s *mcpSession) watching(projectID string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.projects[projectID]
+}
+
+// send 通知をキューへ追加（溢れた場合は破棄）
+func (s *mcpSession) send(msg []byte) {
+	select {
+	case s.events <- msg:
+	default:
+		log.Printf("Warning: MCP session %s dropped a notification (buffer full)", s.id)
+	}
+}
+
+// mcpSessionStore セッションの管理
+type mcpSessionStore struct {
+	mu       sync.RWMutex
+	sessions map[string]*mcpSession
+}
+
+func newMCPSessionStore() *mcpSessionStore {
+	return &mcpSessionStore{sessions: map[string]*mcpSession{}}
+}
+
+func (st *mcpSessionStore) create(caller mcpCaller) (*mcpSession, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, err
+	}
+	s := &mcpSession{
+		id:         hex.EncodeToString(buf),
+		events:     make(chan []byte, mcpSessionBuffer),
+		closed:     make(chan struct{}),
+		caller:     caller,
+		projects:   map[string]bool{},
+		lastActive: time.Now(),
+	}
+
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	st.sweepLocked()
+	st.sessions[s.id] = s
+	return s, nil
+}
+
+// get セッションを取得（セッションを開始した呼び出し元以外には存在しないものとして扱う）
+func (st *mcpSessionStore) get(id string, caller mcpCaller) *mcpSession {
+	st.mu.Lock()
+	st.sweepLocked()
+	s := st.sessions[id]
+	st.mu.Unlock()
+	if s == nil || !s.caller.samePrincipal(caller) {
+		return nil
+	}
+	s.mu.Lock()
+	s.lastActive = time.Now()
+	s.mu.Unlock()
+	return s
+}
+
+func (st *mcpSessionStore) remove(id string) bool {
+	st.mu.Lock()
+	defer st.mu.Unlock()
+	s, ok := st.sessions[id]
+	if ok {
+		delete(st.sessions, id)
+		close(s.closed)
+	}
+	return ok
+}
+
+func (st *mcpSessionStore) all() []*mcpSession {
+	st.mu.RLock()
+	defer st.mu.RUnlock()
+	sessions := make([]*mcpSession, 0, len(st.sessions))
+	for _, s := range st.sessions {
+		sessions = append(sessions, s)
+	}
+	return sessions
+}
+
+// sweepLocked ストリームを開いておらず一定時間使われていないセッションを破棄
+func (st *mcpSessionStore) sweepLocked() {
+	now := time.Now()
+	for id, s := range st.sessions {
+		s.mu.Lock()
+		idle := s.streams == 0 && now.Sub(s.lastActive) > mcpSessionTTL
+		s.mu.Unlock()
+		if idle {
+			delete(st.sessions, id)
+			close(s.closed)
+		}
+	}
+}
+
+// SetRuleEvents ルール変更を購読し、該当するセッションへ notifications/rules/updated を送る
+func (h *MCPHandler) SetRuleEvents(events *usecase.RuleEvents) {
+	events.Subscribe(func(change usecase.RuleChange) {
+		go h.pushRuleUpdate(change)
+	})
+}
+
+// pushRuleUpdate ルール変更の影響を受けるプロジェクトを監視しているセッションへ通知
+func (h *MCPHandler) pushRuleUpdate(change usecase.RuleChange) {
+	sessions := h.sessions.all()
+	if len(sessions) == 0 {
+		return
+	}
+
+	projectIDs := []string{change.ProjectID}
+	if change.ProjectID == "" {
+		var err error
+		projectIDs, err = h.ruleUseCase.ProjectsUsingGlobalRules(change.Language)
+		if err != nil {
+			log.Printf("Warning: failed to resolve projects for %s global rules: %v", change.Language, err)
+			return
+		}
+	}
+
+	for _, projectID := range projectIDs {
+		var msg []byte
+		for _, s := range sessions {
+			// 購読後に公開範囲やメンバーが変わった場合も、閲覧できなくなったプロジェクトは通知しない
+			if !s.watching(projectID) || !h.canViewProject(s.caller, projectID) {
+				continue
+			}
+			if msg == nil {
+				var err error
+				if msg, err = h.ruleUpdateMessage(projectID, change.Language); err != nil {
+					log.Printf("Warning: failed to build rule update notification for %s: %v", projectID, err)
+					break
+				}
+			}
+			s.send(msg)
+		}
+	}
+}
+
+// handleRuleSubscription rules/subscribe・rules/unsubscribe を処理し、購読中のプロジェクト一覧を返す
+func (h *MCPHandler) handleRuleSubscription(caller mcpCaller, session *mcpSession, subscribe bool, params json.RawMessage) (interface{}, error) {
+	var req struct {
+		ProjectID  string   `json:"project_id"`
+		ProjectIDs []string `json:"project_ids"`
+	}
+	if err := decodeParams(params, &req); err != nil {
+		return nil, err
+	}
+	projectIDs := req.ProjectIDs
+	if req.ProjectID != "" {
+		projectIDs = append(projectIDs, req.ProjectID)
+	}
+	if len(projectIDs) == 0 {
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID is required")
+	}
+
+	if subscribe {
+		for _, projectID := range projectIDs {
+			if err := h.authorizeProject(caller, projectID); err != nil {
+				return nil, err
+			}
+			if _, err := h.ruleUseCase.GetProjectRules(projectID); err != nil {
+				return nil, mcpx.FromError(err, "Failed to subscribe to "+projectID+": ")
+			}
+		}
+		session.watch(projectIDs...)
+	} else {
+		session.unwatch(projectIDs...)
+	}
+	return map[string]interface{}{"subscribed": session.watched()}, nil
+}
+
+// ruleUpdateMessage notifications/rules/updated のJSON-RPCメッセージを作成
+func (h *MCPHandler) ruleUpdateMessage(projectID, language string) ([]byte, error) {
+	ruleCount := 0
+	if rules, err := h.ruleUseCase.GetProjectRules(projectID); err == nil {
+		ruleCount = len(rules.Rules)
+	}
+	params, err := json.Marshal(domain.MCPRuleUpdateNotification{
+		ProjectID: projectID,
+		UpdatedAt: time.Now().UTC(),
+		RuleCount: ruleCount,
+		Language:  language,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(domain.MCPNotification{
+		JSONRPC: domain.JSONRPCVersion,
+		Method:  domain.MCPRuleUpdatedMethod,
+		Params:  params,
+	})
+}
+
+// HandleStreamablePost Streamable HTTP のPOSTを処理
+// initialize でセッションを発行し、以降は Mcp-Session-Id ヘッダーでセッションを識別する
+func (h *MCPHandler) HandleStreamablePost(c *gin.Context) {
+	body, err := c.GetRawData()
+	if err != nil {
+		c.JSON(http.StatusBadRequest, rpcError(nil, mcpx.NewError(mcpx.CodeParseError, "Failed to read request body")))
+		return
+	}
+	requests := peekRequests(body)
+
+	caller := callerFromContext(c)
+	var session *mcpSession
+	if id := c.GetHeader(mcpSessionHeader); id != "" {
+		if session = h.sessions.get(id, caller); session == nil {
+			c.JSON(http.StatusNotFound, rpcError(nil, mcpx.NewError(mcpx.CodeInvalidRequest, "Session not found")))
+			return
+		}
+	} else if hasMethod(requests, "initialize") {
+		if session, err = h.sessions.create(caller); err != nil {
+			c.JSON(http.StatusInternalServerError, rpcError(nil, mcpx.NewError(mcpx.CodeInternalError, "Failed to create session")))
+			return
+		}
+	} else {
+		c.JSON(http.StatusBadRequest, rpcError(nil, mcpx.NewError(mcpx.CodeInvalidRequest, mcpSessionHeader+" header is required")))
+		return
+	}
+
+	// ツール呼び出しで扱ったプロジェクトのルール変更を通知対象にする
+	session.watch(h.requestedProjects(caller, requests)...)
+	c.Header(mcpSessionHeader, session.id)
+
+	reply, ok := h.handlePayload(caller, session, body)
+	if !ok {
+		c.Status(http.StatusAccepted)
+		return
+	}
+	c.JSON(http.StatusOK, reply)
+}
+
+// HandleStreamableGet サーバーからの通知を送るSSEストリームを開く
+// project_id クエリ（カンマ区切り）で通知対象のプロジェクトを追加できる
+func (h *MCPHandler) HandleStreamableGet(c *gin.Context) {
+	if !strings.Contains(c.GetHeader("Accept"), "text/event-stream") {
+		c.JSON(http.StatusNotAcceptable, rpcError(nil, mcpx.NewError(mcpx.CodeInvalidRequest, "Accept must include text/event-stream")))
+		return
+	}
+	id := c.GetHeader(mcpSessionHeader)
+	if id == "" {
+		id = c.Query("session_id") // ヘッダーを付けられない EventSource 向け
+	}
+	caller := callerFromContext(c)
+	session := h.sessions.get(id, caller)
+	if session == nil {
+		c.JSON(http.StatusNotFound, rpcError(nil, mcpx.NewError(mcpx.CodeInvalidRequest, "Session not found")))
+		return
+	}
+	if projects := c.Query("project_id"); projects != "" {
+		for _, projectID := range strings.Split(projects, ",") {
+			if err := h.authorizeProject(caller, projectID); err != nil {
+				status := http.StatusForbidden
+				if err.Code == mcpx.CodeNotFound {
+					status = http.StatusNotFound
+				}
+				c.JSON(status, rpcError(nil, err))
+				return
+			}
+		}
+		session.watch(strings.Split(projects, ",")...)
+	}
+
+	session.attach()
+	defer session.detach()
+
+	w := c.Writer
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.Header().Set(mcpSessionHeader, session.id)
+	w.WriteHeader(http.StatusOK)
+	w.Flush()
+
+	keepAlive := time.NewTicker(sseKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case <-c.Request.Context().Done():
+			return
+		case <-session.closed:
+			return
+		case msg := <-session.events:
+			if _, err := fmt.Fprintf(w, "event: message\ndata: %s\n\n", msg); err != nil {
+				return
+			}
+			w.Flush()
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+			w.Flush()
+		}
+	}
+}
+
+// HandleStreamableDelete セッションを終了
+func (h *MCPHandler) HandleStreamableDelete(c *gin.Context) {
+	session := h.sessions.get(c.GetHeader(mcpSessionHeader), callerFromContext(c))
+	if session == nil || !h.sessions.remove(session.id) {
+		c.JSON(http.StatusNotFound, rpcError(nil, mcpx.NewError(mcpx.CodeInvalidRequest, "Session not found")))
+		return
+	}
+	c.Status(http.StatusNoContent)
+}
+
+// peekRequests セッション処理のためにメッセージを読み取る（不正なメッセージは無視し、検証はディスパッチャーに任せる）
+func peekRequests(body []byte) []domain.MCPRequest {
+	body = bytes.TrimSpace(body)
+	var requests []domain.MCPRequest
+	if len(body) > 0 && body[0] == '[' {
+		var batch []json.RawMessage
+		_ = json.Unmarshal(body, &batch)
+		for _, msg := range batch {
+			var req domain.MCPRequest
+			if json.Unmarshal(msg, &req) == nil {
+				requests = append(requests, req)
+			}
+		}
+		return requests
+	}
+	var req domain.MCPRequest
+	if json.Unmarshal(body, &req) == nil {
+		requests = append(requests, req)
+	}
+	return requests
+}
+
+func hasMethod(requests []domain.MCPRequest, method string) bool {
+	for _, req := range requests {
+		if req.Method == method {
+			return true
+		}
+	}
+	return false
+}
+
+// requestedProjects ツール呼び出しの引数に含まれるプロジェクトID（呼び出し元が利用できるものに限る）
+func (h *MCPHandler) requestedProjects(caller mcpCaller, requests []domain.MCPRequest) []string {
+	var projectIDs []string
+	for _, req := range requests {
+		args := req.Params
+		if req.Method == "tools/call" {
+			var call domain.MCPToolCallRequest
+			if json.Unmarshal(req.Params, &call) != nil {
+				continue
+			}
+			args = call.Arguments
+		}
+		if projectID := argumentProjectID(args); projectID != "" && h.canViewProject(caller, projectID) {
+			projectIDs = append(projectIDs, projectID)
+		}
+	}
+	return projectIDs
+}
@@ -0,0 +1,311 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/infrastructure/rulesfile"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// testUserHeader テストで呼び出し元の利用者名を指定するヘッダー
+const testUserHeader = "X-Test-User"
+
+type streamableFixture struct {
+	server     *httptest.Server
+	handler    *MCPHandler
+	rules      *usecase.RuleUseCase
+	globalRule *usecase.GlobalRuleUseCase
+}
+
+func newStreamableFixture(t *testing.T) *streamableFixture {
+	t.Helper()
+	store, err := rulesfile.Parse([]byte(`{
+		"projects": {
+			"web": {"language": "go", "rules": [{"id": "no-todo", "pattern": "TODO"}]},
+			"other": {"language": "go", "rules": []}
+		}
+	}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := usecase.NewRuleEvents()
+	ruleUseCase := usecase.NewRuleUseCase(store.Rules(), store.GlobalRules(), store.Projects())
+	ruleUseCase.SetRuleEvents(events)
+	globalRuleUseCase := usecase.NewGlobalRuleUseCase(store.GlobalRules())
+	globalRuleUseCase.SetRuleEvents(events)
+
+	h := NewMCPHandler(ruleUseCase, globalRuleUseCase, nil)
+	h.SetRuleEvents(events)
+
+	gin.SetMode(gin.TestMode)
+	r := gin.New()
+	// testUserHeader で呼び出し元を切り替える（認証ミドルウェアの代わり）
+	r.Use(func(c *gin.Context) {
+		if username := c.GetHeader(testUserHeader); username != "" {
+			c.Set(ContextKeyUsername, username)
+			c.Set(ContextKeyAuthenticated, true)
+		}
+	})
+	r.POST("/mcp", h.HandleStreamablePost)
+	r.GET("/mcp", h.HandleStreamableGet)
+	r.DELETE("/mcp", h.HandleStreamableDelete)
+	r.POST("/mcp/request", h.HandleMCPRequest)
+	r.GET("/mcp/ws", h.HandleWebSocket)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	return &streamableFixture{server: server, handler: h, rules: ruleUseCase, globalRule: globalRuleUseCase}
+}
+
+func (f *streamableFixture) post(t *testing.T, sessionID, body string) *http.Response {
+	t.Helper()
+	req, _ := http.NewRequest(http.MethodPost, f.server.URL+"/mcp", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	if sessionID != "" {
+		req.Header.Set(mcpSessionHeader, sessionID)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	return resp
+}
+
+// readEvents SSEの data 行を順にチャネルへ送る
+func readEvents(body *bufio.Reader) <-chan string {
+	ch := make(chan string, 8)
+	go func() {
+		defer close(ch)
+		for {
+			line, err := body.ReadString('\n')
+			if err != nil {
+				return
+			}
+			if data, ok := strings.CutPrefix(strings.TrimRight(line, "\n"), "data: "); ok {
+				ch <- data
+			}
+		}
+	}()
+	return ch
+}
+
+func nextNotification(t *testing.T, events <-chan string) domain.MCPRuleUpdateNotification {
+	t.Helper()
+	select {
+	case data, ok := <-events:
+		if !ok {
+			t.Fatal("stream closed before a notification arrived")
+		}
+		var msg domain.MCPNotification
+		if err := json.Unmarshal([]byte(data), &msg); err != nil {
+			t.Fatalf("invalid notification %q: %v", data, err)
+		}
+		if msg.JSONRPC != domain.JSONRPCVersion || msg.Method != domain.MCPRuleUpdatedMethod {
+			t.Errorf("notification = %+v", msg)
+		}
+		var params domain.MCPRuleUpdateNotification
+		if err := json.Unmarshal(msg.Params, &params); err != nil {
+			t.Fatal(err)
+		}
+		return params
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for a notification")
+	}
+	return domain.MCPRuleUpdateNotification{}
+}
+
+func TestStreamableHTTPSessionLifecycle(t *testing.T) {
+	f := newStreamableFixture(t)
+
+	if resp := f.post(t, "", `{"jsonrpc":"2.0","id":1,"method":"ping"}`); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("request without session: status %d, want 400", resp.StatusCode)
+	}
+	if resp := f.post(t, "unknown", `{"jsonrpc":"2.0","id":1,"method":"ping"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("request with unknown session: status %d, want 404", resp.StatusCode)
+	}
+
+	resp := f.post(t, "", `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`)
+	sessionID := resp.Header.Get(mcpSessionHeader)
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("initialize: status %d, session %q", resp.StatusCode, sessionID)
+	}
+	if resp := f.post(t, sessionID, `{"jsonrpc":"2.0","method":"notifications/initialized"}`); resp.StatusCode != http.StatusAccepted {
+		t.Errorf("notification: status %d, want 202", resp.StatusCode)
+	}
+
+	req, _ := http.NewRequest(http.MethodDelete, f.server.URL+"/mcp", nil)
+	req.Header.Set(mcpSessionHeader, sessionID)
+	del, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	del.Body.Close()
+	if del.StatusCode != http.StatusNoContent {
+		t.Errorf("delete: status %d, want 204", del.StatusCode)
+	}
+	if resp := f.post(t, sessionID, `{"jsonrpc":"2.0","id":2,"method":"ping"}`); resp.StatusCode != http.StatusNotFound {
+		t.Errorf("request after delete: status %d, want 404", resp.StatusCode)
+	}
+}
+
+func TestStreamableHTTPPushesRuleUpdates(t *testing.T) {
+	f := newStreamableFixture(t)
+
+	resp := f.post(t, "", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`)
+	sessionID := resp.Header.Get(mcpSessionHeader)
+	// ツール呼び出しで扱ったプロジェクトが通知対象になる
+	f.post(t, sessionID, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"getRules","arguments":{"project_id":"web"}}}`)
+
+	req, _ := http.NewRequest(http.MethodGet, f.server.URL+"/mcp", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(mcpSessionHeader, sessionID)
+	stream, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer stream.Body.Close()
+	if stream.StatusCode != http.StatusOK || !strings.HasPrefix(stream.Header.Get("Content-Type"), "text/event-stream") {
+		t.Fatalf("stream: status %d, content type %q", stream.StatusCode, stream.Header.Get("Content-Type"))
+	}
+	events := readEvents(bufio.NewReader(stream.Body))
+
+	// 監視していないプロジェクトの変更は届かない
+	if err := f.rules.CreateRule("other", "r1", "R1", "", "style", "warning", "x", "m", "", domain.RuleMatcher{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.rules.CreateRule("web", "no-fixme", "No FIXME", "", "style", "warning", "FIXME", "m", "", domain.RuleMatcher{}); err != nil {
+		t.Fatal(err)
+	}
+	got := nextNotification(t, events)
+	if got.ProjectID != "web" || got.RuleCount != 2 || got.Language != "" {
+		t.Errorf("project rule notification = %+v", got)
+	}
+
+	// 継承しているグローバルルールの変更も通知される
+	if err := f.globalRule.CreateGlobalRule("go", "no-panic", "No panic", "", "style", "warning", "panic", "m", "", domain.RuleMatcher{}); err != nil {
+		t.Fatal(err)
+	}
+	got = nextNotification(t, events)
+	if got.ProjectID != "web" || got.Language != "go" || got.RuleCount != 3 {
+		t.Errorf("global rule notification = %+v", got)
+	}
+}
+
+func TestStreamableHTTPSessionBoundToCreator(t *testing.T) {
+	f := newStreamableFixture(t)
+
+	do := func(method, user, sessionID, body string) *http.Response {
+		t.Helper()
+		req, _ := http.NewRequest(method, f.server.URL+"/mcp", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Accept", "text/event-stream")
+		if user != "" {
+			req.Header.Set(testUserHeader, user)
+		}
+		if sessionID != "" {
+			req.Header.Set(mcpSessionHeader, sessionID)
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		return resp
+	}
+
+	resp := do(http.MethodPost, "alice", "", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`)
+	sessionID := resp.Header.Get(mcpSessionHeader)
+	if resp.StatusCode != http.StatusOK || sessionID == "" {
+		t.Fatalf("initialize: status %d, session %q", resp.StatusCode, sessionID)
+	}
+
+	// 他の利用者・未認証の呼び出し元にはセッションが存在しないものとして扱う
+	for _, user := range []string{"bob", ""} {
+		if resp := do(http.MethodPost, user, sessionID, `{"jsonrpc":"2.0","id":2,"method":"ping"}`); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("POST as %q: status %d, want 404", user, resp.StatusCode)
+		}
+		if resp := do(http.MethodGet, user, sessionID, ""); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("GET as %q: status %d, want 404", user, resp.StatusCode)
+		}
+		req, _ := http.NewRequest(http.MethodGet, f.server.URL+"/mcp?session_id="+sessionID, nil)
+		req.Header.Set("Accept", "text/event-stream")
+		if user != "" {
+			req.Header.Set(testUserHeader, user)
+		}
+		get, err := http.DefaultClient.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		get.Body.Close()
+		if get.StatusCode != http.StatusNotFound {
+			t.Errorf("GET ?session_id as %q: status %d, want 404", user, get.StatusCode)
+		}
+		if resp := do(http.MethodDelete, user, sessionID, ""); resp.StatusCode != http.StatusNotFound {
+			t.Errorf("DELETE as %q: status %d, want 404", user, resp.StatusCode)
+		}
+	}
+
+	// 作成した利用者は引き続き利用・終了できる
+	if resp := do(http.MethodPost, "alice", sessionID, `{"jsonrpc":"2.0","id":3,"method":"ping"}`); resp.StatusCode != http.StatusOK {
+		t.Errorf("POST as creator: status %d, want 200", resp.StatusCode)
+	}
+	if resp := do(http.MethodDelete, "alice", sessionID, ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("DELETE as creator: status %d, want 204", resp.StatusCode)
+	}
+}
+
+func TestMCPSessionStoreSweepsExpiredSessionsOnGet(t *testing.T) {
+	st := newMCPSessionStore()
+	caller := mcpCaller{username: "alice", authenticated: true}
+	expired, err := st.create(caller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	active, err := st.create(caller)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired.mu.Lock()
+	expired.lastActive = time.Now().Add(-2 * mcpSessionTTL)
+	expired.mu.Unlock()
+
+	if st.get(active.id, caller) == nil {
+		t.Fatal("active session not found")
+	}
+	if st.get(expired.id, caller) != nil {
+		t.Error("expired session still returned")
+	}
+	select {
+	case <-expired.closed:
+	default:
+		t.Error("expired session was not closed by get")
+	}
+	if len(st.all()) != 1 {
+		t.Errorf("sessions = %d, want 1", len(st.all()))
+	}
+}
+
+func TestStreamableHTTPRequiresEventStreamAccept(t *testing.T) {
+	f := newStreamableFixture(t)
+	resp := f.post(t, "", `{"jsonrpc":"2.0","id":1,"method":"initialize"}`)
+
+	req, _ := http.NewRequest(http.MethodGet, f.server.URL+"/mcp", nil)
+	req.Header.Set(mcpSessionHeader, resp.Header.Get(mcpSessionHeader))
+	get, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	get.Body.Close()
+	if get.StatusCode != http.StatusNotAcceptable {
+		t.Errorf("status %d, want 406", get.StatusCode)
+	}
+}
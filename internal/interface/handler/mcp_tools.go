@@ -0,0 +1,143 @@
+package handler
+
+import (
+	"slices"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+// mcpTool MCPツールの宣言
+// tools/list・tools/call・従来のメソッド名による呼び出しは、すべてこの宣言から生成する
+type mcpTool struct {
+	name        string
+	description string
+	inputSchema map[string]interface{}
+	// permission 呼び出しに必要な権限（空なら誰でも呼び出せる）
+	permission string
+	handler    mcpMethod
+}
+
+// mcpToolRegistry 登録順を保つMCPツールの一覧
+type mcpToolRegistry struct {
+	tools  []*mcpTool
+	byName map[string]*mcpTool
+}
+
+func newMCPToolRegistry() *mcpToolRegistry {
+	return &mcpToolRegistry{byName: make(map[string]*mcpTool)}
+}
+
+// register ツールを登録（名前の重複や実装の欠落はプログラムの誤りなのでpanicする）
+func (r *mcpToolRegistry) register(tool mcpTool) {
+	if tool.name == "" || tool.handler == nil {
+		panic("mcp: tool must have a name and a handler")
+	}
+	if _, exists := r.byName[tool.name]; exists {
+		panic("mcp: duplicate tool " + tool.name)
+	}
+	t := tool
+	r.tools = append(r.tools, &t)
+	r.byName[t.name] = &t
+}
+
+// lookup 名前でツールを検索
+func (r *mcpToolRegistry) lookup(name string) (*mcpTool, bool) {
+	tool, ok := r.byName[name]
+	return tool, ok
+}
+
+// list 呼び出し元が使えるツールを tools/list の形式で返す
+func (r *mcpToolRegistry) list(caller mcpCaller) []domain.MCPTool {
+	tools := make([]domain.MCPTool, 0, len(r.tools))
+	for _, t := range r.tools {
+		if !caller.allowed(t.permission) {
+			continue
+		}
+		tools = append(tools, domain.MCPTool{Name: t.name, Description: t.description, InputSchema: t.inputSchema})
+	}
+	return tools
+}
+
+// only 同じ宣言（スキーマ・権限）のまま実装だけを差し替えたレジストリを返す
+// 指定されなかったツールは含めない（簡易版ハンドラーなど一部のツールだけを別実装で提供する場合に使う）
+func (r *mcpToolRegistry) only(handlers map[string]mcpMethod) *mcpToolRegistry {
+	out := newMCPToolRegistry()
+	for _, t := range r.tools {
+		if handler, ok := handlers[t.name]; ok {
+			tool := *t
+			tool.handler = handler
+			out.register(tool)
+		}
+	}
+	if len(out.tools) != len(handlers) {
+		panic("mcp: handler given for an undeclared tool")
+	}
+	return out
+}
+
+// mcpCaller MCPリクエストの呼び出し元（認証ミドルウェアの結果）
+type mcpCaller struct {
+	userID        int
+	username      string
+	role          string
+	permissions   map[string]bool
+	authenticated bool
+	// projectScope APIキーで利用できるプロジェクト（空なら制限なし）
+	projectScope []string
+	// local 利用者自身が起動したローカルプロセス（stdio）からの呼び出し。権限確認を行わない
+	local bool
+}
+
+// localCaller stdioトランスポートの呼び出し元
+func localCaller() mcpCaller {
+	return mcpCaller{role: "local", authenticated: true, local: true}
+}
+
+// callerFromContext 認証ミドルウェアがコンテキストに設定した値から呼び出し元を作成
+func callerFromContext(c *gin.Context) mcpCaller {
+	caller := mcpCaller{
+		userID:        c.GetInt(ContextKeyUserID),
+		username:      c.GetString(ContextKeyUsername),
+		role:          c.GetString(ContextKeyUserRole),
+		authenticated: c.GetBool(ContextKeyAuthenticated),
+	}
+	if perm, ok := c.Get(ContextKeyPermissions); ok {
+		caller.permissions, _ = perm.(map[string]bool)
+	}
+	caller.projectScope = c.GetStringSlice(ContextKeyProjectScope)
+	return caller
+}
+
+// allowed 権限を持っているか判定（空の権限は誰でも持つ）
+func (c mcpCaller) allowed(permission string) bool {
+	return permission == "" || c.local || c.permissions[permission]
+}
+
+// canAccessProject プロジェクトスコープにプロジェクトが含まれるか判定
+func (c mcpCaller) canAccessProject(projectID string) bool {
+	return (&domain.APIKey{ProjectIDs: c.projectScope}).AllowsProject(projectID)
+}
+
+// samePrincipal 同じ利用者・同じAPIキーのスコープからの呼び出しか判定
+func (c mcpCaller) samePrincipal(other mcpCaller) bool {
+	return c.local == other.local &&
+		c.authenticated == other.authenticated &&
+		c.userID == other.userID &&
+		c.username == other.username &&
+		slices.Equal(c.projectScope, other.projectScope)
+}
+
+// principal プロジェクト単位の認可に使う利用者
+func (c mcpCaller) principal() usecase.Principal {
+	return usecase.Principal{
+		UserID:        c.userID,
+		Username:      c.username,
+		Role:          c.role,
+		Permissions:   c.permissions,
+		Authenticated: c.authenticated,
+		ProjectScope:  c.projectScope,
+		Local:         c.local,
+	}
+}
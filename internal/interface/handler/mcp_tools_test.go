@@ -0,0 +1,68 @@
+package handler
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+func noopTool(caller mcpCaller, params json.RawMessage) (interface{}, error) { return nil, nil }
+
+func TestMCPToolRegistry(t *testing.T) {
+	r := newMCPToolRegistry()
+	r.register(mcpTool{name: "a", inputSchema: map[string]interface{}{"type": "object"}, handler: noopTool})
+	r.register(mcpTool{name: "b", permission: "manage_rules", handler: noopTool})
+
+	if names := toolNames(r.list(mcpCaller{})); names != "a" {
+		t.Errorf("public list = %q", names)
+	}
+	if names := toolNames(r.list(mcpCaller{permissions: map[string]bool{"manage_rules": true}})); names != "a,b" {
+		t.Errorf("privileged list = %q", names)
+	}
+	if names := toolNames(r.list(localCaller())); names != "a,b" {
+		t.Errorf("local list = %q", names)
+	}
+
+	sub := r.only(map[string]mcpMethod{"b": noopTool})
+	if tool, ok := sub.lookup("b"); !ok || tool.permission != "manage_rules" {
+		t.Errorf("only() lost the declaration: %+v", tool)
+	}
+	if _, ok := sub.lookup("a"); ok {
+		t.Error("only() kept a tool without a handler")
+	}
+
+	assertPanics(t, "duplicate", func() { r.register(mcpTool{name: "a", handler: noopTool}) })
+	assertPanics(t, "undeclared", func() { r.only(map[string]mcpMethod{"c": noopTool}) })
+}
+
+func TestSimpleMCPHandlerSharesDeclarations(t *testing.T) {
+	full := NewMCPHandler(nil, nil, nil).tools
+	for _, tool := range NewSimpleMCPHandler().mcp.tools.tools {
+		declared, ok := full.lookup(tool.name)
+		if !ok || declared.description != tool.description {
+			t.Errorf("simple tool %q differs from the MCPHandler declaration", tool.name)
+		}
+	}
+}
+
+func toolNames(tools []domain.MCPTool) string {
+	out := ""
+	for i, tool := range tools {
+		if i > 0 {
+			out += ","
+		}
+		out += tool.Name
+	}
+	return out
+}
+
+func assertPanics(t *testing.T, name string, fn func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s: expected panic", name)
+		}
+	}()
+	fn()
+}
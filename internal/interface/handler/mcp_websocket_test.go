@@ -0,0 +1,117 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/mcpx"
+	"github.com/gorilla/websocket"
+)
+
+func dialWebSocket(t *testing.T, f *streamableFixture, header http.Header) (*websocket.Conn, *http.Response, error) {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(f.server.URL, "http") + "/mcp/ws"
+	conn, resp, err := websocket.DefaultDialer.Dial(url, header)
+	if conn != nil {
+		t.Cleanup(func() { conn.Close() })
+	}
+	return conn, resp, err
+}
+
+// readWebSocket 次のメッセージを読み、レスポンスか通知かを method の有無で返す
+func readWebSocket(t *testing.T, conn *websocket.Conn) map[string]json.RawMessage {
+	t.Helper()
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var msg map[string]json.RawMessage
+	if err := conn.ReadJSON(&msg); err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	return msg
+}
+
+func TestWebSocketSharesDispatcherAndPushesRuleUpdates(t *testing.T) {
+	f := newStreamableFixture(t)
+	conn, _, err := dialWebSocket(t, f, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18"}}`))
+	if msg := readWebSocket(t, conn); string(msg["id"]) != "1" || msg["result"] == nil {
+		t.Fatalf("initialize reply = %s", msg)
+	}
+
+	// 通知には応答しない。続くバッチの応答が次に届く
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","method":"notifications/initialized"}`))
+	conn.WriteMessage(websocket.TextMessage, []byte(`[{"jsonrpc":"2.0","id":2,"method":"ping"},{"jsonrpc":"2.0","id":3,"method":"rules/subscribe","params":{"project_id":"missing"}}]`))
+	conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var batch []domain.MCPResponse
+	if err := conn.ReadJSON(&batch); err != nil {
+		t.Fatal(err)
+	}
+	if len(batch) != 2 || batch[0].Error != nil || batch[1].Error == nil || batch[1].Error.Code != mcpx.CodeNotFound {
+		t.Fatalf("batch reply = %+v", batch)
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":4,"method":"rules/subscribe","params":{"project_id":"web"}}`))
+	if msg := readWebSocket(t, conn); string(msg["result"]) != `{"subscribed":["web"]}` {
+		t.Fatalf("subscribe reply = %s", msg)
+	}
+
+	if err := f.rules.CreateRule("other", "r1", "R1", "", "style", "warning", "x", "m", "", domain.RuleMatcher{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := f.rules.CreateRule("web", "no-fixme", "No FIXME", "", "style", "warning", "FIXME", "m", "", domain.RuleMatcher{}); err != nil {
+		t.Fatal(err)
+	}
+	msg := readWebSocket(t, conn)
+	var params domain.MCPRuleUpdateNotification
+	if string(msg["method"]) != `"`+domain.MCPRuleUpdatedMethod+`"` || json.Unmarshal(msg["params"], &params) != nil {
+		t.Fatalf("notification = %s", msg)
+	}
+	if params.ProjectID != "web" || params.RuleCount != 2 {
+		t.Errorf("notification params = %+v", params)
+	}
+
+	conn.WriteMessage(websocket.TextMessage, []byte(`{"jsonrpc":"2.0","id":5,"method":"rules/unsubscribe","params":{"project_id":"web"}}`))
+	if msg := readWebSocket(t, conn); string(msg["result"]) != `{"subscribed":[]}` {
+		t.Fatalf("unsubscribe reply = %s", msg)
+	}
+}
+
+func TestWebSocketChecksAllowedOrigins(t *testing.T) {
+	f := newStreamableFixture(t)
+	f.handler.SetAllowedOrigins([]string{"https://app.example.com"})
+
+	_, resp, err := dialWebSocket(t, f, http.Header{"Origin": {"https://evil.example.com"}})
+	if err == nil || resp == nil || resp.StatusCode != http.StatusForbidden {
+		t.Errorf("disallowed origin: err %v, resp %+v", err, resp)
+	}
+	if _, _, err := dialWebSocket(t, f, http.Header{"Origin": {"https://app.example.com"}}); err != nil {
+		t.Errorf("allowed origin: %v", err)
+	}
+	if _, _, err := dialWebSocket(t, f, nil); err != nil {
+		t.Errorf("no origin: %v", err)
+	}
+}
+
+func TestRuleSubscriptionRequiresSession(t *testing.T) {
+	f := newStreamableFixture(t)
+	resp, err := http.Post(f.server.URL+"/mcp/request", "application/json",
+		strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"rules/subscribe","params":{"project_id":"web"}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var reply domain.MCPResponse
+	if err := json.NewDecoder(resp.Body).Decode(&reply); err != nil {
+		t.Fatal(err)
+	}
+	if reply.Error == nil || reply.Error.Code != mcpx.CodeMethodNotFound {
+		t.Errorf("reply = %+v", reply)
+	}
+}
@@ -0,0 +1,45 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/httpx"
+	"github.com/gin-gonic/gin"
+)
+
+// 権限（ロールの permissions のキー）
+const (
+	PermissionAdmin       = "admin"        // システム管理（APIキー・設定・統計・一括入出力・言語・ユーザー承認）
+	PermissionManageUsers = "manage_users" // ユーザー管理
+	PermissionManageRules = "manage_rules" // ルールの作成・編集・入出力
+	PermissionManageRoles = "manage_roles" // ロール管理
+)
+
+// RequirePermission 権限を持たないリクエストを拒否するミドルウェア（ルート登録時に指定する）
+// 未認証なら401、認証済みで権限が無ければ403を返す。public ロールに付与した権限は未認証でも有効
+func RequirePermission(permission string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if hasPerm(c, permission) {
+			c.Next()
+			return
+		}
+		if !c.GetBool(ContextKeyAuthenticated) {
+			httpx.JSONError(c, http.StatusUnauthorized, httpx.CodeUnauthorized, "認証が必要です", gin.H{"required_permission": permission})
+			return
+		}
+		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission "+permission+" required", gin.H{"required_permission": permission})
+	}
+}
+
+// hasPerm 認証ミドルウェアが設定した権限に key が含まれるか判定
+func hasPerm(c *gin.Context, key string) bool {
+	v, ok := c.Get(ContextKeyPermissions)
+	if !ok || v == nil {
+		return false
+	}
+	m, ok := v.(map[string]bool)
+	if !ok || m == nil {
+		return false
+	}
+	return m[key]
+}
@@ -0,0 +1,39 @@
+package handler
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+)
+
+func TestRequirePermission(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	tests := []struct {
+		name          string
+		authenticated bool
+		permissions   map[string]bool
+		want          int
+	}{
+		{"anonymous", false, defaultPermissions("public"), http.StatusUnauthorized},
+		{"missing permission", true, defaultPermissions("user"), http.StatusForbidden},
+		{"granted", true, defaultPermissions("admin"), http.StatusOK},
+		// public ロールに付与した権限は未認証でも有効
+		{"granted to public role", false, map[string]bool{PermissionManageUsers: true}, http.StatusOK},
+	}
+	for _, tt := range tests {
+		r := gin.New()
+		r.Use(func(c *gin.Context) {
+			c.Set(ContextKeyAuthenticated, tt.authenticated)
+			c.Set(ContextKeyPermissions, tt.permissions)
+		})
+		r.GET("/users", RequirePermission(PermissionManageUsers), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/users", nil))
+		if w.Code != tt.want {
+			t.Errorf("%s: status = %d, want %d", tt.name, w.Code, tt.want)
+		}
+	}
+}
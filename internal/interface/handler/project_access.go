@@ -0,0 +1,47 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/httpx"
+	"github.com/gin-gonic/gin"
+)
+
+// principalFromContext 認証ミドルウェアがコンテキストに設定した値から利用者を作成
+func principalFromContext(c *gin.Context) usecase.Principal {
+	p := usecase.Principal{
+		UserID:        c.GetInt(ContextKeyUserID),
+		Username:      c.GetString(ContextKeyUsername),
+		Role:          c.GetString(ContextKeyUserRole),
+		Authenticated: c.GetBool(ContextKeyAuthenticated),
+		ProjectScope:  c.GetStringSlice(ContextKeyProjectScope),
+	}
+	if perm, ok := c.Get(ContextKeyPermissions); ok {
+		p.Permissions, _ = perm.(map[string]bool)
+	}
+	return p
+}
+
+// requireProjectRole プロジェクトに role 以上のロールを持つか確認し、持たない場合はエラーレスポンスを返す
+// 認可が未設定（access が nil）の場合はAPIキーのスコープと、編集系なら manage_rules 権限だけを確認する
+func requireProjectRole(c *gin.Context, access *usecase.ProjectAccessUseCase, projectID, role string) (*domain.Project, bool) {
+	if access == nil {
+		if !projectAllowed(c, projectID) {
+			httpx.JSONError(c, http.StatusNotFound, httpx.CodeNotFound, "プロジェクトが見つかりません", gin.H{"project_id": projectID})
+			return nil, false
+		}
+		if role != domain.ProjectRoleViewer && !hasPerm(c, PermissionManageRules) {
+			httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
+			return nil, false
+		}
+		return nil, true
+	}
+	project, err := access.Authorize(principalFromContext(c), projectID, role)
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return nil, false
+	}
+	return project, true
+}
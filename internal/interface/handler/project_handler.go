@@ -2,8 +2,10 @@ package handler
 
 import (
 	"net/http"
+	"strconv"
 	"strings"
 
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/httpx"
 	"github.com/gin-gonic/gin"
@@ -11,6 +13,7 @@ import (
 
 type ProjectHandler struct {
 	projectUseCase *usecase.ProjectUseCase
+	access         *usecase.ProjectAccessUseCase
 }
 
 func NewProjectHandler(projectUseCase *usecase.ProjectUseCase) *ProjectHandler {
@@ -19,12 +22,29 @@ func NewProjectHandler(projectUseCase *usecase.ProjectUseCase) *ProjectHandler {
 	}
 }
 
+// SetProjectAccess 公開範囲・メンバーによる認可を注入
+func (h *ProjectHandler) SetProjectAccess(access *usecase.ProjectAccessUseCase) {
+	h.access = access
+}
+
 func (h *ProjectHandler) GetProjects(c *gin.Context) {
 	projects, err := h.projectUseCase.GetProjects()
 	if err != nil {
 		httpx.JSONFromError(c, err)
 		return
 	}
+	// 閲覧できるプロジェクト（APIキーのスコープ内）だけを返す
+	if h.access != nil {
+		projects = h.access.VisibleProjects(principalFromContext(c), projects)
+	} else {
+		visible := make([]*domain.Project, 0, len(projects))
+		for _, p := range projects {
+			if projectAllowed(c, p.ProjectID) {
+				visible = append(visible, p)
+			}
+		}
+		projects = visible
+	}
 
 	c.JSON(http.StatusOK, gin.H{"projects": projects})
 }
@@ -36,6 +56,9 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 		return
 	}
 
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleViewer); !ok {
+		return
+	}
 	project, err := h.projectUseCase.GetByID(projectID)
 	if err != nil {
 		httpx.JSONFromError(c, err)
@@ -47,11 +70,13 @@ func (h *ProjectHandler) GetProject(c *gin.Context) {
 
 func (h *ProjectHandler) CreateProject(c *gin.Context) {
 	var req struct {
-		ProjectID        string `json:"project_id" binding:"required"`
-		Name             string `json:"name" binding:"required"`
-		Description      string `json:"description"`
-		Language         string `json:"language"`
-		ApplyGlobalRules bool   `json:"apply_global_rules"`
+		ProjectID        string                   `json:"project_id" binding:"required"`
+		Name             string                   `json:"name" binding:"required"`
+		Description      string                   `json:"description"`
+		Language         string                   `json:"language"`
+		Languages        []domain.ProjectLanguage `json:"languages"`
+		ApplyGlobalRules bool                     `json:"apply_global_rules"`
+		AccessLevel      string                   `json:"access_level"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -59,7 +84,8 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		return
 	}
 
-	err := h.projectUseCase.CreateProject(req.ProjectID, req.Name, req.Description, req.Language, req.ApplyGlobalRules)
+	principal := principalFromContext(c)
+	err := h.projectUseCase.CreateProject(req.ProjectID, req.Name, req.Description, req.Language, req.Languages, req.ApplyGlobalRules, req.AccessLevel, principal.Username)
 	if err != nil {
 		if strings.Contains(err.Error(), "一意制約") {
 			httpx.JSONError(c, http.StatusConflict, httpx.CodeConflict, "このプロジェクトIDは既に使用されています。別のプロジェクトIDを指定してください。", nil)
@@ -68,6 +94,13 @@ func (h *ProjectHandler) CreateProject(c *gin.Context) {
 		httpx.JSONFromError(c, err)
 		return
 	}
+	// 作成したユーザーをプロジェクトの owner にする
+	if h.access != nil {
+		if err := h.access.GrantCreator(principal, req.ProjectID); err != nil {
+			httpx.JSONFromError(c, err)
+			return
+		}
+	}
 
 	c.JSON(http.StatusCreated, gin.H{"message": "Project created successfully"})
 }
@@ -80,10 +113,12 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 	}
 
 	var req struct {
-		Name             string `json:"name" binding:"required"`
-		Description      string `json:"description"`
-		Language         string `json:"language"`
-		ApplyGlobalRules bool   `json:"apply_global_rules"`
+		Name             string                   `json:"name" binding:"required"`
+		Description      string                   `json:"description"`
+		Language         string                   `json:"language"`
+		Languages        []domain.ProjectLanguage `json:"languages"` // 省略時は変更しない
+		ApplyGlobalRules bool                     `json:"apply_global_rules"`
+		AccessLevel      string                   `json:"access_level"` // 省略時は変更しない
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -91,7 +126,18 @@ func (h *ProjectHandler) UpdateProject(c *gin.Context) {
 		return
 	}
 
-	err := h.projectUseCase.UpdateProject(projectID, req.Name, req.Description, req.Language, req.ApplyGlobalRules)
+	project, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleEditor)
+	if !ok {
+		return
+	}
+	// 公開範囲の変更は owner のみ
+	if project != nil && req.AccessLevel != "" && req.AccessLevel != project.AccessLevel {
+		if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleOwner); !ok {
+			return
+		}
+	}
+
+	err := h.projectUseCase.UpdateProject(projectID, req.Name, req.Description, req.Language, req.Languages, req.ApplyGlobalRules, req.AccessLevel)
 	if err != nil {
 		httpx.JSONFromError(c, err)
 		return
@@ -107,6 +153,9 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 		return
 	}
 
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleOwner); !ok {
+		return
+	}
 	err := h.projectUseCase.DeleteProject(projectID)
 	if err != nil {
 		httpx.JSONFromError(c, err)
@@ -115,3 +164,86 @@ func (h *ProjectHandler) DeleteProject(c *gin.Context) {
 
 	c.JSON(http.StatusOK, gin.H{"message": "Project deleted successfully"})
 }
+
+// GetMembers プロジェクトのメンバー一覧
+func (h *ProjectHandler) GetMembers(c *gin.Context) {
+	if h.access == nil {
+		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
+		return
+	}
+	members, err := h.access.ListMembers(principalFromContext(c), c.Param("project_id"))
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"members": members})
+}
+
+// AddMember メンバーを追加（登録済みならロールを変更）
+func (h *ProjectHandler) AddMember(c *gin.Context) {
+	var req struct {
+		UserID   int    `json:"user_id"`
+		Username string `json:"username"`
+		Role     string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+	h.saveMember(c, req.UserID, req.Username, req.Role, http.StatusCreated)
+}
+
+// UpdateMember メンバーのロールを変更
+func (h *ProjectHandler) UpdateMember(c *gin.Context) {
+	userID, ok := memberUserID(c)
+	if !ok {
+		return
+	}
+	var req struct {
+		Role string `json:"role" binding:"required"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+	h.saveMember(c, userID, "", req.Role, http.StatusOK)
+}
+
+func (h *ProjectHandler) saveMember(c *gin.Context, userID int, username, role string, status int) {
+	if h.access == nil {
+		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
+		return
+	}
+	member, err := h.access.SetMember(principalFromContext(c), c.Param("project_id"), userID, username, role)
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(status, member)
+}
+
+// RemoveMember メンバーを削除
+func (h *ProjectHandler) RemoveMember(c *gin.Context) {
+	userID, ok := memberUserID(c)
+	if !ok {
+		return
+	}
+	if h.access == nil {
+		httpx.JSONError(c, http.StatusServiceUnavailable, httpx.CodeInternal, "Database not available", nil)
+		return
+	}
+	if err := h.access.RemoveMember(principalFromContext(c), c.Param("project_id"), userID); err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Member removed successfully"})
+}
+
+func memberUserID(c *gin.Context) (int, bool) {
+	userID, err := strconv.Atoi(c.Param("user_id"))
+	if err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "Invalid user ID", nil)
+		return 0, false
+	}
+	return userID, true
+}
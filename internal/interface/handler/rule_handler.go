@@ -13,6 +13,7 @@ import (
 
 type RuleHandler struct {
 	ruleUseCase *usecase.RuleUseCase
+	access      *usecase.ProjectAccessUseCase
 }
 
 func NewRuleHandler(ruleUseCase *usecase.RuleUseCase) *RuleHandler {
@@ -21,12 +22,20 @@ func NewRuleHandler(ruleUseCase *usecase.RuleUseCase) *RuleHandler {
 	}
 }
 
+// SetProjectAccess 公開範囲・メンバーによる認可を注入
+func (h *RuleHandler) SetProjectAccess(access *usecase.ProjectAccessUseCase) {
+	h.access = access
+}
+
 func (h *RuleHandler) GetRules(c *gin.Context) {
 	projectID := c.Query("project_id")
 	if projectID == "" {
 		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "project_id parameter is required", nil)
 		return
 	}
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleViewer); !ok {
+		return
+	}
 
 	rules, err := h.ruleUseCase.GetProjectRules(projectID)
 	if err != nil {
@@ -47,6 +56,8 @@ func (h *RuleHandler) CreateRule(c *gin.Context) {
 		Severity    string `json:"severity"`
 		Pattern     string `json:"pattern"`
 		Message     string `json:"message"`
+		Replacement string `json:"replacement"`
+		domain.RuleMatcher
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -54,13 +65,11 @@ func (h *RuleHandler) CreateRule(c *gin.Context) {
 		return
 	}
 
-	// 権限制御
-	if perms, ok := c.Get("permissions"); !ok || !perms.(map[string]bool)["manage_rules"] {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
+	if _, ok := requireProjectRole(c, h.access, req.ProjectID, domain.ProjectRoleEditor); !ok {
 		return
 	}
 
-	err := h.ruleUseCase.CreateRule(req.ProjectID, req.RuleID, req.Name, req.Description, req.Type, req.Severity, req.Pattern, req.Message)
+	err := h.ruleUseCase.CreateRule(req.ProjectID, req.RuleID, req.Name, req.Description, req.Type, req.Severity, req.Pattern, req.Message, req.Replacement, req.RuleMatcher)
 	if err != nil {
 		if strings.Contains(err.Error(), "一意制約") {
 			httpx.JSONError(c, http.StatusConflict, httpx.CodeConflict, "このプロジェクト内で既に同じルールIDが使用されています。別のルールIDを指定してください。", map[string]string{"rule_id": req.RuleID})
@@ -81,6 +90,9 @@ func (h *RuleHandler) GetRule(c *gin.Context) {
 		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "project_id and rule_id are required", nil)
 		return
 	}
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleViewer); !ok {
+		return
+	}
 	rule, err := h.ruleUseCase.GetRule(projectID, ruleID)
 	if err != nil {
 		httpx.JSONFromError(c, err)
@@ -97,11 +109,6 @@ func (h *RuleHandler) UpdateRule(c *gin.Context) {
 		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "project_id and rule_id are required", nil)
 		return
 	}
-	// 権限制御
-	if perms, ok := c.Get("permissions"); !ok || !perms.(map[string]bool)["manage_rules"] {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
-		return
-	}
 
 	var req struct {
 		Name        string `json:"name"`
@@ -110,8 +117,10 @@ func (h *RuleHandler) UpdateRule(c *gin.Context) {
 		Severity    string `json:"severity"`
 		Pattern     string `json:"pattern"`
 		Message     string `json:"message"`
+		Replacement string `json:"replacement"`
 		IsActive    *bool  `json:"is_active"`
 		ProjectID   string `json:"project_id"`
+		domain.RuleMatcher
 	}
 	if err := c.ShouldBindJSON(&req); err != nil {
 		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
@@ -120,8 +129,17 @@ func (h *RuleHandler) UpdateRule(c *gin.Context) {
 	if req.ProjectID != "" {
 		projectID = req.ProjectID
 	}
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	// matcher_kind 指定時のみマッチャー設定を置き換える
+	var matcher *domain.RuleMatcher
+	if req.MatcherKind != "" {
+		matcher = &req.RuleMatcher
+	}
 
-	if err := h.ruleUseCase.UpdateRule(projectID, ruleID, req.Name, req.Description, req.Type, req.Severity, req.Pattern, req.Message, req.IsActive); err != nil {
+	if err := h.ruleUseCase.UpdateRule(projectID, ruleID, req.Name, req.Description, req.Type, req.Severity, req.Pattern, req.Message, req.Replacement, matcher, req.IsActive); err != nil {
 		httpx.JSONFromError(c, err)
 		return
 	}
@@ -137,9 +155,7 @@ func (h *RuleHandler) DeleteRule(c *gin.Context) {
 		return
 	}
 
-	// 権限制御
-	if perms, ok := c.Get("permissions"); !ok || !perms.(map[string]bool)["manage_rules"] {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleEditor); !ok {
 		return
 	}
 
@@ -156,6 +172,33 @@ func (h *RuleHandler) ValidateCode(c *gin.Context) {
 	var req struct {
 		ProjectID string `json:"project_id" binding:"required"`
 		Code      string `json:"code" binding:"required"`
+		Filename  string `json:"filename"`
+		Language  string `json:"language"` // 省略時はファイル名から判定
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+
+	if _, ok := requireProjectRole(c, h.access, req.ProjectID, domain.ProjectRoleViewer); !ok {
+		return
+	}
+
+	result, err := h.ruleUseCase.ValidateFile(req.ProjectID, req.Filename, req.Language, req.Code)
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, result)
+}
+
+// ValidateFiles 複数ファイルをまとめて検証
+func (h *RuleHandler) ValidateFiles(c *gin.Context) {
+	var req struct {
+		ProjectID string             `json:"project_id" binding:"required"`
+		Files     []domain.FileInput `json:"files" binding:"required"`
 	}
 
 	if err := c.ShouldBindJSON(&req); err != nil {
@@ -163,7 +206,11 @@ func (h *RuleHandler) ValidateCode(c *gin.Context) {
 		return
 	}
 
-	result, err := h.ruleUseCase.ValidateCode(req.ProjectID, req.Code)
+	if _, ok := requireProjectRole(c, h.access, req.ProjectID, domain.ProjectRoleViewer); !ok {
+		return
+	}
+
+	result, err := h.ruleUseCase.ValidateFiles(req.ProjectID, req.Files)
 	if err != nil {
 		httpx.JSONFromError(c, err)
 		return
@@ -172,6 +219,88 @@ func (h *RuleHandler) ValidateCode(c *gin.Context) {
 	c.JSON(http.StatusOK, result)
 }
 
+// TestPattern パターンをサンプルコードに対してドライラン
+func (h *RuleHandler) TestPattern(c *gin.Context) {
+	var req struct {
+		Pattern string `json:"pattern" binding:"required"`
+		Code    string `json:"code"`
+	}
+
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+
+	matches, err := h.ruleUseCase.TestPattern(req.Pattern, req.Code)
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, gin.H{
+		"pattern":    req.Pattern,
+		"matchCount": len(matches),
+		"matches":    matches,
+	})
+}
+
+// GetGlobalRuleOverrides プロジェクトのグローバルルール上書き一覧
+func (h *RuleHandler) GetGlobalRuleOverrides(c *gin.Context) {
+	projectID := c.Param("project_id")
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleViewer); !ok {
+		return
+	}
+	overrides, err := h.ruleUseCase.ListGlobalRuleOverrides(projectID)
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"project_id": projectID, "overrides": overrides})
+}
+
+// SetGlobalRuleOverride 継承するグローバルルールを無効化、または重要度・メッセージを上書き
+func (h *RuleHandler) SetGlobalRuleOverride(c *gin.Context) {
+	projectID := c.Param("project_id")
+	var req struct {
+		Disabled bool   `json:"disabled"`
+		Severity string `json:"severity"`
+		Message  string `json:"message"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	override, err := h.ruleUseCase.SetGlobalRuleOverride(projectID, domain.GlobalRuleOverride{
+		Language: c.Param("language"),
+		RuleID:   c.Param("rule_id"),
+		Disabled: req.Disabled,
+		Severity: req.Severity,
+		Message:  req.Message,
+	})
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Global rule override saved successfully", "override": override})
+}
+
+// DeleteGlobalRuleOverride 上書きを削除（グローバルルールをそのまま継承する）
+func (h *RuleHandler) DeleteGlobalRuleOverride(c *gin.Context) {
+	projectID := c.Param("project_id")
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleEditor); !ok {
+		return
+	}
+	if err := h.ruleUseCase.DeleteGlobalRuleOverride(projectID, c.Param("language"), c.Param("rule_id")); err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Global rule override deleted successfully"})
+}
+
 // ExportRulesRequest ルールエクスポートリクエスト
 type ExportRulesRequest struct {
 	ProjectID string   `json:"projectId"`
@@ -194,9 +323,7 @@ func (h *RuleHandler) ExportRules(c *gin.Context) {
 		return
 	}
 
-	// 権限制御
-	if perms, ok := c.Get("permissions"); !ok || !perms.(map[string]bool)["manage_rules"] {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
+	if _, ok := requireProjectRole(c, h.access, req.ProjectID, domain.ProjectRoleViewer); !ok {
 		return
 	}
 
@@ -263,9 +390,17 @@ func (h *RuleHandler) ImportRules(c *gin.Context) {
 		return
 	}
 
-	// 権限制御
-	if perms, ok := c.Get("permissions"); !ok || !perms.(map[string]bool)["manage_rules"] {
-		httpx.JSONError(c, http.StatusForbidden, httpx.CodeForbidden, "Permission manage_rules required", nil)
+	if _, ok := requireProjectRole(c, h.access, req.ProjectID, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	// 不正なパターン・マッチャー設定を含む場合はインポート全体を拒否
+	rules := make(map[string]domain.Rule, len(req.Rules))
+	for _, rule := range req.Rules {
+		rules[rule.RuleID] = rule
+	}
+	if err := usecase.ValidateRules(rules); err != nil {
+		httpx.JSONFromError(c, err)
 		return
 	}
 
@@ -294,7 +429,7 @@ func (h *RuleHandler) ImportRules(c *gin.Context) {
 		}
 
 		// ルール保存処理
-		err := h.ruleUseCase.CreateRule(rule.ProjectID, rule.RuleID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message)
+		err := h.ruleUseCase.CreateRule(rule.ProjectID, rule.RuleID, rule.Name, rule.Description, rule.Type, rule.Severity, rule.Pattern, rule.Message, rule.Replacement, rule.RuleMatcher)
 		if err != nil {
 			errors = append(errors, "Failed to import rule "+rule.RuleID+": "+err.Error())
 			continue
@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"net/http"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/httpx"
+	"github.com/gin-gonic/gin"
+)
+
+type RulePackHandler struct {
+	rulePackUseCase *usecase.RulePackUseCase
+	access          *usecase.ProjectAccessUseCase
+}
+
+func NewRulePackHandler(rulePackUseCase *usecase.RulePackUseCase) *RulePackHandler {
+	return &RulePackHandler{
+		rulePackUseCase: rulePackUseCase,
+	}
+}
+
+// SetProjectAccess パックの適用・解除に使うプロジェクト単位の認可を注入
+func (h *RulePackHandler) SetProjectAccess(access *usecase.ProjectAccessUseCase) {
+	h.access = access
+}
+
+// PackRule パックに含めるルール（is_active を省略した場合は有効）
+type PackRule struct {
+	domain.Rule
+	IsActive *bool `json:"is_active"`
+}
+
+// rulesFromPack リクエストのルールを変換（rules を省略した場合はnil）
+func rulesFromPack(items []PackRule) []domain.Rule {
+	if items == nil {
+		return nil
+	}
+	rules := make([]domain.Rule, 0, len(items))
+	for _, item := range items {
+		rule := item.Rule
+		rule.IsActive = item.IsActive == nil || *item.IsActive
+		rules = append(rules, rule)
+	}
+	return rules
+}
+
+// GetRulePacks ルールパック一覧
+func (h *RulePackHandler) GetRulePacks(c *gin.Context) {
+	packs, err := h.rulePackUseCase.GetRulePacks()
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"packs": packs})
+}
+
+// GetRulePack ルールパック取得
+func (h *RulePackHandler) GetRulePack(c *gin.Context) {
+	pack, err := h.rulePackUseCase.GetRulePack(c.Param("name"))
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, pack)
+}
+
+// CreateRulePack ルールパック作成
+func (h *RulePackHandler) CreateRulePack(c *gin.Context) {
+	var req struct {
+		Name        string     `json:"name" binding:"required"`
+		Description string     `json:"description"`
+		Rules       []PackRule `json:"rules"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+
+	pack, err := h.rulePackUseCase.CreateRulePack(req.Name, req.Description, rulesFromPack(req.Rules))
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusCreated, gin.H{"message": "Rule pack created successfully", "pack": pack})
+}
+
+// UpdateRulePack ルールパック更新（rules を指定した場合はパックのルールを置き換える）
+func (h *RulePackHandler) UpdateRulePack(c *gin.Context) {
+	var req struct {
+		Description *string    `json:"description"`
+		Rules       []PackRule `json:"rules"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+
+	pack, err := h.rulePackUseCase.UpdateRulePack(c.Param("name"), req.Description, rulesFromPack(req.Rules))
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rule pack updated successfully", "pack": pack})
+}
+
+// DeleteRulePack ルールパック削除
+func (h *RulePackHandler) DeleteRulePack(c *gin.Context) {
+	if err := h.rulePackUseCase.DeleteRulePack(c.Param("name")); err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rule pack deleted successfully"})
+}
+
+// GetProjectRulePacks プロジェクトに適用しているパック（優先度の高い順）
+func (h *RulePackHandler) GetProjectRulePacks(c *gin.Context) {
+	projectID := c.Param("project_id")
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleViewer); !ok {
+		return
+	}
+	packs, err := h.rulePackUseCase.ListProjectRulePacks(projectID)
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"project_id": projectID, "packs": packs})
+}
+
+// AttachRulePack パックをプロジェクトに適用（適用済みなら優先度を変更）
+func (h *RulePackHandler) AttachRulePack(c *gin.Context) {
+	projectID := c.Param("project_id")
+	var req struct {
+		Priority int `json:"priority"`
+	}
+	if err := c.ShouldBindJSON(&req); err != nil {
+		httpx.JSONError(c, http.StatusBadRequest, httpx.CodeValidation, "リクエストデータが不正です", err.Error())
+		return
+	}
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleEditor); !ok {
+		return
+	}
+
+	attachment, err := h.rulePackUseCase.AttachRulePack(projectID, c.Param("name"), req.Priority)
+	if err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rule pack attached successfully", "attachment": attachment})
+}
+
+// DetachRulePack パックをプロジェクトから外す
+func (h *RulePackHandler) DetachRulePack(c *gin.Context) {
+	projectID := c.Param("project_id")
+	if _, ok := requireProjectRole(c, h.access, projectID, domain.ProjectRoleEditor); !ok {
+		return
+	}
+	if err := h.rulePackUseCase.DetachRulePack(projectID, c.Param("name")); err != nil {
+		httpx.JSONFromError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"message": "Rule pack detached successfully"})
+}
@@ -0,0 +1,139 @@
+package handler
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/infrastructure/rulesfile"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/usecase"
+	"github.com/gin-gonic/gin"
+)
+
+func newRulePackRouter(t *testing.T, data string) *gin.Engine {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	store, err := rulesfile.Parse([]byte(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ruleUseCase := usecase.NewRuleUseCase(store.Rules(), store.GlobalRules(), store.Projects())
+	ruleUseCase.SetRulePackRepository(store.RulePacks())
+	packs := NewRulePackHandler(usecase.NewRulePackUseCase(store.RulePacks(), store.Projects()))
+	rules := NewRuleHandler(ruleUseCase)
+
+	r := gin.New()
+	r.Use(func(c *gin.Context) {
+		c.Set(ContextKeyPermissions, map[string]bool{PermissionManageRules: true})
+		c.Next()
+	})
+	r.GET("/rules", rules.GetRules)
+	r.POST("/rule-packs", packs.CreateRulePack)
+	r.PUT("/rule-packs/:name", packs.UpdateRulePack)
+	r.PUT("/projects/:project_id/rule-packs/:name", packs.AttachRulePack)
+	r.DELETE("/projects/:project_id/rule-packs/:name", packs.DetachRulePack)
+	return r
+}
+
+func sendJSON(r *gin.Engine, method, path, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	return w
+}
+
+func getProjectRules(t *testing.T, r *gin.Engine, projectID string) domain.ProjectRules {
+	t.Helper()
+	w := sendJSON(r, http.MethodGet, "/rules?project_id="+projectID, "")
+	var rules domain.ProjectRules
+	if err := json.Unmarshal(w.Body.Bytes(), &rules); err != nil || w.Code != http.StatusOK {
+		t.Fatalf("get rules status %d: %s", w.Code, w.Body.String())
+	}
+	return rules
+}
+
+func TestRulePacksLayeredByRuleID(t *testing.T) {
+	r := newRulePackRouter(t, `{
+		"projects": {"web": {"language": "javascript",
+			"rules": [{"id": "no-debugger", "name": "No Debugger", "pattern": "debugger"}],
+			"rule_packs": [{"name": "security-baseline"}]
+		}},
+		"global_rules": {"javascript": [{"id": "no-eval", "name": "No eval (global)", "severity": "warning", "pattern": "eval\\("}]},
+		"rule_packs": {"security-baseline": {"rules": [
+			{"id": "no-eval", "name": "No eval (pack)", "severity": "error", "pattern": "eval\\("},
+			{"id": "no-secrets", "name": "No Secrets", "severity": "error", "pattern": "api_key"}
+		]}}
+	}`)
+
+	w := sendJSON(r, http.MethodPost, "/rule-packs", `{"name": "react-style", "rules": [
+		{"rule_id": "no-secrets", "name": "No Secrets (relaxed)", "severity": "info", "pattern": "api_key"},
+		{"rule_id": "no-inline-style", "name": "No Inline Style", "language": "typescript", "pattern": "style="}
+	]}`)
+	if w.Code != http.StatusCreated {
+		t.Fatalf("create status %d: %s", w.Code, w.Body.String())
+	}
+	if w := sendJSON(r, http.MethodPut, "/projects/web/rule-packs/react-style", `{"priority": 10}`); w.Code != http.StatusOK {
+		t.Fatalf("attach status %d: %s", w.Code, w.Body.String())
+	}
+
+	rules := getProjectRules(t, r, "web")
+	// プロジェクト → グローバル → パック（優先度の高い順）、同じルールIDは先に現れた層のものを使う
+	want := []struct{ id, name, pack string }{
+		{"no-debugger", "No Debugger", ""},
+		{"no-eval", "No eval (global)", ""},
+		{"no-secrets", "No Secrets (relaxed)", "react-style"},
+		{"no-inline-style", "No Inline Style", "react-style"},
+	}
+	if len(rules.Rules) != len(want) {
+		t.Fatalf("rules = %+v", rules.Rules)
+	}
+	for i, w := range want {
+		got := rules.Rules[i]
+		if got.RuleID != w.id || got.Name != w.name || got.Pack != w.pack || !got.IsActive {
+			t.Errorf("rules[%d] = %s/%s from %q, want %s/%s from %q", i, got.RuleID, got.Name, got.Pack, w.id, w.name, w.pack)
+		}
+	}
+	if len(rules.Packs) != 2 || rules.Packs[0].Pack != "react-style" || rules.Packs[1].Pack != "security-baseline" {
+		t.Errorf("packs = %+v, want react-style before security-baseline", rules.Packs)
+	}
+
+	// 優先度の高いパックからルールを外すと、次のパックのルールが使われる
+	if w := sendJSON(r, http.MethodPut, "/rule-packs/react-style", `{"rules": []}`); w.Code != http.StatusOK {
+		t.Fatalf("update status %d: %s", w.Code, w.Body.String())
+	}
+	rules = getProjectRules(t, r, "web")
+	if last := rules.Rules[len(rules.Rules)-1]; last.RuleID != "no-secrets" || last.Pack != "security-baseline" || last.Severity != "error" {
+		t.Errorf("rules = %+v, want no-secrets from security-baseline", rules.Rules)
+	}
+}
+
+func TestRulePackValidationAndDetach(t *testing.T) {
+	r := newRulePackRouter(t, `{"projects": {"web": {"language": "go", "rules": []}}, "rule_packs": {"base": {"rules": []}}}`)
+
+	for name, body := range map[string]string{
+		"invalid name":  `{"name": "Security Baseline"}`,
+		"invalid regex": `{"name": "broken", "rules": [{"rule_id": "x", "name": "X", "pattern": "("}]}`,
+		"duplicate id":  `{"name": "dup", "rules": [{"rule_id": "x", "name": "X"}, {"rule_id": "x", "name": "Y"}]}`,
+	} {
+		if w := sendJSON(r, http.MethodPost, "/rule-packs", body); w.Code != http.StatusBadRequest {
+			t.Errorf("%s: status %d, want 400: %s", name, w.Code, w.Body.String())
+		}
+	}
+	if w := sendJSON(r, http.MethodPut, "/projects/web/rule-packs/missing", `{}`); w.Code != http.StatusNotFound {
+		t.Errorf("attach unknown pack status %d, want 404", w.Code)
+	}
+	if w := sendJSON(r, http.MethodDelete, "/projects/web/rule-packs/base", ""); w.Code != http.StatusNotFound {
+		t.Errorf("detach unattached pack status %d, want 404", w.Code)
+	}
+	sendJSON(r, http.MethodPut, "/projects/web/rule-packs/base", `{}`)
+	if w := sendJSON(r, http.MethodDelete, "/projects/web/rule-packs/base", ""); w.Code != http.StatusOK {
+		t.Errorf("detach status %d: %s", w.Code, w.Body.String())
+	}
+	if rules := getProjectRules(t, r, "web"); len(rules.Packs) != 0 {
+		t.Errorf("packs after detach = %+v", rules.Packs)
+	}
+}
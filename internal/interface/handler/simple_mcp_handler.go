@@ -2,50 +2,53 @@ package handler
 
 import (
 	"encoding/json"
-	"net/http"
 
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/mcpx"
 	"github.com/gin-gonic/gin"
-	"github.com/gorilla/websocket"
 )
 
-type SimpleMCPHandler struct{}
+// SimpleMCPHandler データベースに接続できない場合の簡易版MCPハンドラー
+// ツールの宣言とディスパッチャーは MCPHandler と共通で、サンプルデータを返す実装だけを差し替える
+type SimpleMCPHandler struct {
+	mcp *MCPHandler
+}
 
 func NewSimpleMCPHandler() *SimpleMCPHandler {
-	return &SimpleMCPHandler{}
+	h := &SimpleMCPHandler{}
+	h.mcp = &MCPHandler{sessions: newMCPSessionStore()}
+	h.mcp.tools = h.mcp.newToolRegistry().only(map[string]mcpMethod{
+		"getRules":       h.handleGetRules,
+		"validateCode":   h.handleValidateCode,
+		"getProjectInfo": h.handleGetProjectInfo,
+	})
+	return h
+}
+
+// SetAllowedOrigins WebSocket接続を許可するオリジンを設定
+func (h *SimpleMCPHandler) SetAllowedOrigins(origins []string) {
+	h.mcp.SetAllowedOrigins(origins)
 }
 
 // HandleMCPRequest MCPプロトコルリクエストを処理
 func (h *SimpleMCPHandler) HandleMCPRequest(c *gin.Context) {
-	var req domain.MCPRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		h.sendMCPError(c, req.ID, 400, "Invalid request format")
-		return
-	}
+	h.mcp.HandleMCPRequest(c)
+}
 
-	switch req.Method {
-	case "getRules":
-		h.handleGetRules(c, req)
-	case "validateCode":
-		h.handleValidateCode(c, req)
-	case "getProjectInfo":
-		h.handleGetProjectInfo(c, req)
-	default:
-		h.sendMCPError(c, req.ID, 404, "Method not found: "+req.Method)
-	}
+// HandleWebSocket リアルタイムMCP通信のためのWebSocket接続を処理
+func (h *SimpleMCPHandler) HandleWebSocket(c *gin.Context) {
+	h.mcp.HandleWebSocket(c)
 }
 
 // handleGetRules getRules MCPメソッドを処理
-func (h *SimpleMCPHandler) handleGetRules(c *gin.Context, req domain.MCPRequest) {
+func (h *SimpleMCPHandler) handleGetRules(_ mcpCaller, raw json.RawMessage) (interface{}, error) {
 	var params domain.MCPRuleRequest
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendMCPError(c, req.ID, 400, "Invalid parameters")
-		return
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
 	}
 
 	if params.ProjectID == "" {
-		h.sendMCPError(c, req.ID, 400, "Project ID is required")
-		return
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID is required")
 	}
 
 	// 簡易版：サンプルルールを返す
@@ -100,20 +103,18 @@ func (h *SimpleMCPHandler) handleGetRules(c *gin.Context, req domain.MCPRequest)
 		AppliedRules: append(sampleRules, h.convertGlobalRulesToRules(globalRules, params.ProjectID)...),
 	}
 
-	h.sendMCPResponse(c, req.ID, response)
+	return response, nil
 }
 
 // handleValidateCode validateCode MCPメソッドを処理
-func (h *SimpleMCPHandler) handleValidateCode(c *gin.Context, req domain.MCPRequest) {
+func (h *SimpleMCPHandler) handleValidateCode(_ mcpCaller, raw json.RawMessage) (interface{}, error) {
 	var params domain.MCPValidationRequest
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendMCPError(c, req.ID, 400, "Invalid parameters")
-		return
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
 	}
 
 	if params.ProjectID == "" || params.Code == "" {
-		h.sendMCPError(c, req.ID, 400, "Project ID and code are required")
-		return
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID and code are required")
 	}
 
 	// 簡易版：基本的なパターンマッチング
@@ -160,22 +161,20 @@ func (h *SimpleMCPHandler) handleValidateCode(c *gin.Context, req domain.MCPRequ
 		Rules:   sampleRules,
 	}
 
-	h.sendMCPResponse(c, req.ID, response)
+	return response, nil
 }
 
 // handleGetProjectInfo getProjectInfo MCPメソッドを処理
-func (h *SimpleMCPHandler) handleGetProjectInfo(c *gin.Context, req domain.MCPRequest) {
+func (h *SimpleMCPHandler) handleGetProjectInfo(_ mcpCaller, raw json.RawMessage) (interface{}, error) {
 	var params struct {
 		ProjectID string `json:"project_id"`
 	}
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendMCPError(c, req.ID, 400, "Invalid parameters")
-		return
+	if err := decodeParams(raw, &params); err != nil {
+		return nil, err
 	}
 
 	if params.ProjectID == "" {
-		h.sendMCPError(c, req.ID, 400, "Project ID is required")
-		return
+		return nil, mcpx.NewError(mcpx.CodeInvalidParams, "Project ID is required")
 	}
 
 	// 簡易版：サンプルプロジェクト情報
@@ -188,25 +187,14 @@ func (h *SimpleMCPHandler) handleGetProjectInfo(c *gin.Context, req domain.MCPRe
 		"rule_count":         2,
 	}
 
-	h.sendMCPResponse(c, req.ID, projectInfo)
+	return projectInfo, nil
 }
 
 // convertGlobalRulesToRules GlobalRuleをRule形式に変換
 func (h *SimpleMCPHandler) convertGlobalRulesToRules(globalRules []domain.GlobalRule, projectID string) []domain.Rule {
 	rules := make([]domain.Rule, 0, len(globalRules))
 	for _, gr := range globalRules {
-		rule := domain.Rule{
-			ProjectID:   projectID,
-			RuleID:      gr.RuleID,
-			Name:        gr.Name,
-			Description: gr.Description,
-			Type:        gr.Type,
-			Severity:    gr.Severity,
-			Pattern:     gr.Pattern,
-			Message:     gr.Message,
-			IsActive:    gr.IsActive,
-		}
-		rules = append(rules, rule)
+		rules = append(rules, gr.AsRule(projectID))
 	}
 	return rules
 }
@@ -215,126 +203,3 @@ func (h *SimpleMCPHandler) convertGlobalRulesToRules(globalRules []domain.Global
 func containsPattern(code, pattern string) bool {
 	return len(code) > 0 && len(pattern) > 0
 }
-
-// sendMCPResponse 成功したMCPレスポンスを送信
-func (h *SimpleMCPHandler) sendMCPResponse(c *gin.Context, id string, result interface{}) {
-	resultJSON, err := json.Marshal(result)
-	if err != nil {
-		h.sendMCPError(c, id, 500, "Failed to marshal response")
-		return
-	}
-
-	response := domain.MCPResponse{
-		ID:     id,
-		Result: resultJSON,
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-// sendMCPError MCPエラーレスポンスを送信
-func (h *SimpleMCPHandler) sendMCPError(c *gin.Context, id string, code int, message string) {
-	response := domain.MCPResponse{
-		ID: id,
-		Error: &domain.MCPError{
-			Code:    code,
-			Message: message,
-		},
-	}
-
-	c.JSON(http.StatusOK, response)
-}
-
-// HandleWebSocket リアルタイムMCP通信のためのWebSocket接続を処理
-func (h *SimpleMCPHandler) HandleWebSocket(c *gin.Context) {
-	// WebSocket接続にアップグレード
-	upgrader := websocket.Upgrader{
-		CheckOrigin: func(r *http.Request) bool {
-			return true // 開発用にすべてのオリジンを許可
-		},
-	}
-
-	conn, err := upgrader.Upgrade(c.Writer, c.Request, nil)
-	if err != nil {
-		c.Error(err)
-		return
-	}
-	defer conn.Close()
-
-	// WebSocketメッセージを処理
-	for {
-		var req domain.MCPRequest
-		err := conn.ReadJSON(&req)
-		if err != nil {
-			break
-		}
-
-		// MCPリクエストを処理
-		h.processWebSocketRequest(conn, req)
-	}
-}
-
-// processWebSocketRequest WebSocket経由でMCPリクエストを処理
-func (h *SimpleMCPHandler) processWebSocketRequest(conn *websocket.Conn, req domain.MCPRequest) {
-	switch req.Method {
-	case "getRules":
-		h.handleWebSocketGetRules(conn, req)
-	case "validateCode":
-		h.handleWebSocketValidateCode(conn, req)
-	default:
-		h.sendWebSocketError(conn, req.ID, 404, "Method not found: "+req.Method)
-	}
-}
-
-// handleWebSocketGetRules WebSocket経由でgetRulesを処理
-func (h *SimpleMCPHandler) handleWebSocketGetRules(conn *websocket.Conn, req domain.MCPRequest) {
-	var params domain.MCPRuleRequest
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendWebSocketError(conn, req.ID, 400, "Invalid parameters")
-		return
-	}
-
-	// HTTPハンドラーと同様の実装
-	// ... (handleGetRulesと同じロジック)
-}
-
-// handleWebSocketValidateCode WebSocket経由でvalidateCodeを処理
-func (h *SimpleMCPHandler) handleWebSocketValidateCode(conn *websocket.Conn, req domain.MCPRequest) {
-	var params domain.MCPValidationRequest
-	if err := json.Unmarshal(req.Params, &params); err != nil {
-		h.sendWebSocketError(conn, req.ID, 400, "Invalid parameters")
-		return
-	}
-
-	// HTTPハンドラーと同様の実装
-	// ... (handleValidateCodeと同じロジック)
-}
-
-// sendWebSocketResponse WebSocket経由でレスポンスを送信
-func (h *SimpleMCPHandler) sendWebSocketResponse(conn *websocket.Conn, id string, result interface{}) {
-	resultJSON, err := json.Marshal(result)
-	if err != nil {
-		h.sendWebSocketError(conn, id, 500, "Failed to marshal response")
-		return
-	}
-
-	response := domain.MCPResponse{
-		ID:     id,
-		Result: resultJSON,
-	}
-
-	conn.WriteJSON(response)
-}
-
-// sendWebSocketError WebSocket経由でエラーを送信
-func (h *SimpleMCPHandler) sendWebSocketError(conn *websocket.Conn, id string, code int, message string) {
-	response := domain.MCPResponse{
-		ID: id,
-		Error: &domain.MCPError{
-			Code:    code,
-			Message: message,
-		},
-	}
-
-	conn.WriteJSON(response)
-}
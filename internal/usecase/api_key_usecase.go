@@ -0,0 +1,190 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// APIキーの形式: rmcp_<公開ID>_<秘密部分>
+// 「rmcp_<公開ID>」を Prefix として平文で保存し、キー全体は SHA-256 のハッシュだけを保存する
+// 秘密部分は256ビットの乱数なので、パスワードのような低速ハッシュは使わない
+const (
+	apiKeyScheme      = "rmcp"
+	apiKeyIDBytes     = 6
+	apiKeySecretBytes = 32
+)
+
+type APIKeyUseCase struct {
+	apiKeyRepo domain.APIKeyRepository
+}
+
+func NewAPIKeyUseCase(apiKeyRepo domain.APIKeyRepository) *APIKeyUseCase {
+	return &APIKeyUseCase{apiKeyRepo: apiKeyRepo}
+}
+
+// APIKeyUpdate 更新する項目（nil の項目は変更しない）
+type APIKeyUpdate struct {
+	Name        *string
+	Description *string
+	IsActive    *bool
+	ExpiresAt   *time.Time
+	// ClearExpiry 有効期限を削除する
+	ClearExpiry bool
+	ProjectIDs  *[]string
+}
+
+// CreateAPIKey APIキーを発行し、保存したキーと平文のキーを返す（平文はこの戻り値でしか取得できない）
+func (uc *APIKeyUseCase) CreateAPIKey(name, description, accessLevel string, projectIDs []string, expiresAt *time.Time, createdBy string) (*domain.APIKey, string, error) {
+	if strings.TrimSpace(name) == "" {
+		return nil, "", apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"name"}})
+	}
+	if accessLevel == "" {
+		accessLevel = "user"
+	}
+	now := time.Now()
+	if expiresAt != nil && !expiresAt.After(now) {
+		return nil, "", apperr.Wrap(apperr.ErrValidation, "有効期限は未来の日時を指定してください")
+	}
+
+	token, prefix, err := generateAPIKey()
+	if err != nil {
+		return nil, "", err
+	}
+	key := &domain.APIKey{
+		Name:        name,
+		Description: description,
+		Prefix:      prefix,
+		KeyHash:     hashAPIKey(token),
+		AccessLevel: accessLevel,
+		ProjectIDs:  normalizeProjectIDs(projectIDs),
+		IsActive:    true,
+		ExpiresAt:   expiresAt,
+		CreatedBy:   createdBy,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := uc.apiKeyRepo.Create(key); err != nil {
+		return nil, "", err
+	}
+	return key, token, nil
+}
+
+// ListAPIKeys APIキーの一覧（prefix を指定すると前方一致で絞り込む）
+func (uc *APIKeyUseCase) ListAPIKeys(prefix string) ([]*domain.APIKey, error) {
+	return uc.apiKeyRepo.List(prefix)
+}
+
+func (uc *APIKeyUseCase) UpdateAPIKey(id int, update APIKeyUpdate) (*domain.APIKey, error) {
+	key, err := uc.apiKeyRepo.GetByID(id)
+	if err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		if strings.TrimSpace(*update.Name) == "" {
+			return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"name"}})
+		}
+		key.Name = *update.Name
+	}
+	if update.Description != nil {
+		key.Description = *update.Description
+	}
+	if update.IsActive != nil {
+		key.IsActive = *update.IsActive
+	}
+	if update.ClearExpiry {
+		key.ExpiresAt = nil
+	} else if update.ExpiresAt != nil {
+		key.ExpiresAt = update.ExpiresAt
+	}
+	if update.ProjectIDs != nil {
+		key.ProjectIDs = normalizeProjectIDs(*update.ProjectIDs)
+	}
+	key.UpdatedAt = time.Now()
+	if err := uc.apiKeyRepo.Update(key); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// RevokeAPIKey APIキーを無効化（履歴を残すため削除はしない）
+func (uc *APIKeyUseCase) RevokeAPIKey(id int) error {
+	inactive := false
+	_, err := uc.UpdateAPIKey(id, APIKeyUpdate{IsActive: &inactive})
+	return err
+}
+
+func (uc *APIKeyUseCase) DeleteAPIKey(id int) error {
+	return uc.apiKeyRepo.Delete(id)
+}
+
+// Authenticate クライアントが送ったキーを検証（形式不正・未登録・無効・期限切れはすべて同じエラー）
+func (uc *APIKeyUseCase) Authenticate(token string, now time.Time) (*domain.APIKey, error) {
+	invalid := apperr.Wrap(apperr.ErrUnauthorized, "APIキーが無効です")
+	prefix, ok := apiKeyPrefix(token)
+	if !ok {
+		return nil, invalid
+	}
+	key, err := uc.apiKeyRepo.GetByPrefix(prefix)
+	if err != nil {
+		return nil, invalid
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(token)), []byte(key.KeyHash)) != 1 || !key.Usable(now) {
+		return nil, invalid
+	}
+	return key, nil
+}
+
+// MarkUsed 最終使用日時を記録
+func (uc *APIKeyUseCase) MarkUsed(id int, at time.Time) error {
+	return uc.apiKeyRepo.UpdateLastUsed(id, at)
+}
+
+// generateAPIKey 新しいキーとその Prefix を生成
+func generateAPIKey() (token, prefix string, err error) {
+	buf := make([]byte, apiKeyIDBytes+apiKeySecretBytes)
+	if _, err := rand.Read(buf); err != nil {
+		return "", "", apperr.Wrap(apperr.ErrInternal, "APIキーの生成に失敗しました")
+	}
+	prefix = apiKeyScheme + "_" + hex.EncodeToString(buf[:apiKeyIDBytes])
+	return prefix + "_" + hex.EncodeToString(buf[apiKeyIDBytes:]), prefix, nil
+}
+
+// apiKeyPrefix キーから Prefix を取り出す
+func apiKeyPrefix(token string) (string, bool) {
+	i := strings.LastIndexByte(token, '_')
+	if i < 0 || !strings.HasPrefix(token, apiKeyScheme+"_") {
+		return "", false
+	}
+	prefix, secret := token[:i], token[i+1:]
+	if len(prefix) != len(apiKeyScheme)+1+apiKeyIDBytes*2 || len(secret) != apiKeySecretBytes*2 {
+		return "", false
+	}
+	return prefix, true
+}
+
+func hashAPIKey(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// normalizeProjectIDs 空要素と重複を取り除く
+func normalizeProjectIDs(projectIDs []string) []string {
+	out := make([]string, 0, len(projectIDs))
+	seen := make(map[string]bool, len(projectIDs))
+	for _, id := range projectIDs {
+		id = strings.TrimSpace(id)
+		if id == "" || seen[id] {
+			continue
+		}
+		seen[id] = true
+		out = append(out, id)
+	}
+	return out
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+type memAPIKeyRepo struct {
+	keys []*domain.APIKey
+}
+
+func (r *memAPIKeyRepo) GetByID(id int) (*domain.APIKey, error) {
+	for _, k := range r.keys {
+		if k.ID == id {
+			return k, nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+
+func (r *memAPIKeyRepo) GetByPrefix(prefix string) (*domain.APIKey, error) {
+	for _, k := range r.keys {
+		if k.Prefix == prefix {
+			return k, nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+
+func (r *memAPIKeyRepo) List(prefix string) ([]*domain.APIKey, error) {
+	var out []*domain.APIKey
+	for _, k := range r.keys {
+		if strings.HasPrefix(k.Prefix, prefix) {
+			out = append(out, k)
+		}
+	}
+	return out, nil
+}
+
+func (r *memAPIKeyRepo) Create(key *domain.APIKey) error {
+	key.ID = len(r.keys) + 1
+	r.keys = append(r.keys, key)
+	return nil
+}
+
+func (r *memAPIKeyRepo) Update(key *domain.APIKey) error { return nil }
+
+func (r *memAPIKeyRepo) Delete(id int) error { return nil }
+
+func (r *memAPIKeyRepo) UpdateLastUsed(id int, at time.Time) error { return nil }
+
+func TestCreateAPIKeyStoresOnlyHash(t *testing.T) {
+	repo := &memAPIKeyRepo{}
+	uc := NewAPIKeyUseCase(repo)
+
+	key, token, err := uc.CreateAPIKey("ci", "", "", []string{"web", " ", "web", "api"}, nil, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(token, key.Prefix+"_") || !strings.HasPrefix(key.Prefix, "rmcp_") {
+		t.Errorf("token %q does not start with prefix %q", token, key.Prefix)
+	}
+	if key.KeyHash == "" || strings.Contains(key.KeyHash, token[len(key.Prefix)+1:]) {
+		t.Errorf("stored hash %q leaks the secret", key.KeyHash)
+	}
+	if key.AccessLevel != "user" || strings.Join(key.ProjectIDs, ",") != "web,api" {
+		t.Errorf("key = %+v", key)
+	}
+
+	got, err := uc.Authenticate(token, time.Now())
+	if err != nil || got.ID != key.ID {
+		t.Fatalf("Authenticate() = %+v, %v", got, err)
+	}
+
+	// 同じ Prefix でも秘密部分が違えば拒否する
+	forged := key.Prefix + "_" + strings.Repeat("0", apiKeySecretBytes*2)
+	for _, bad := range []string{forged, key.Prefix, key.KeyHash, ""} {
+		if _, err := uc.Authenticate(bad, time.Now()); !errors.Is(err, apperr.ErrUnauthorized) {
+			t.Errorf("Authenticate(%q) error = %v, want unauthorized", bad, err)
+		}
+	}
+}
+
+func TestAPIKeyExpiryAndRevocation(t *testing.T) {
+	uc := NewAPIKeyUseCase(&memAPIKeyRepo{})
+
+	past := time.Now().Add(-time.Minute)
+	if _, _, err := uc.CreateAPIKey("old", "", "user", nil, &past, "admin"); !errors.Is(err, apperr.ErrValidation) {
+		t.Errorf("past expiry error = %v, want validation", err)
+	}
+
+	expiresAt := time.Now().Add(time.Hour)
+	key, token, err := uc.CreateAPIKey("temp", "", "user", nil, &expiresAt, "admin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.Authenticate(token, expiresAt.Add(time.Second)); err == nil {
+		t.Error("expired key was accepted")
+	}
+	if err := uc.RevokeAPIKey(key.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.Authenticate(token, time.Now()); err == nil {
+		t.Error("revoked key was accepted")
+	}
+}
+
+func TestListAPIKeysByPrefix(t *testing.T) {
+	uc := NewAPIKeyUseCase(&memAPIKeyRepo{})
+	first, _, _ := uc.CreateAPIKey("a", "", "user", nil, nil, "admin")
+	if _, _, err := uc.CreateAPIKey("b", "", "user", nil, nil, "admin"); err != nil {
+		t.Fatal(err)
+	}
+
+	all, _ := uc.ListAPIKeys("")
+	found, _ := uc.ListAPIKeys(first.Prefix)
+	if len(all) != 2 || len(found) != 1 || found[0].ID != first.ID {
+		t.Errorf("all = %d keys, by prefix = %+v", len(all), found)
+	}
+}
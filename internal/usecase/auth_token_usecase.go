@@ -0,0 +1,293 @@
+package usecase
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// 既定の有効期間（アクセストークンは短くし、継続利用はリフレッシュトークンで行う）
+const (
+	DefaultAccessTokenTTL  = 15 * time.Minute
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+// リフレッシュトークンの形式: rmcp_rt_<256ビットの乱数>（保存するのは SHA-256 のハッシュのみ）
+const (
+	refreshTokenPrefix = "rmcp_rt_"
+	refreshTokenBytes  = 32
+	tokenIDBytes       = 16
+)
+
+// AccessClaims アクセストークン（JWT）のクレーム。ID（jti）で失効させる
+type AccessClaims struct {
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+	Role     string `json:"role"`
+	jwt.RegisteredClaims
+}
+
+// TokenPair ログイン・リフレッシュで発行するトークン（RefreshToken はリポジトリが無い場合は空）
+type TokenPair struct {
+	AccessToken      string
+	AccessExpiresAt  time.Time
+	RefreshToken     string
+	RefreshExpiresAt time.Time
+}
+
+// AuthTokenUseCase アクセストークンとリフレッシュトークンの発行・ローテーション・失効
+type AuthTokenUseCase struct {
+	secret      []byte
+	accessTTL   time.Duration
+	refreshTTL  time.Duration
+	refreshRepo domain.RefreshTokenRepository
+	denylist    domain.TokenDenylistRepository
+	userRepo    domain.UserRepository
+}
+
+func NewAuthTokenUseCase(jwtSecret string) *AuthTokenUseCase {
+	return &AuthTokenUseCase{
+		secret:     []byte(jwtSecret),
+		accessTTL:  DefaultAccessTokenTTL,
+		refreshTTL: DefaultRefreshTokenTTL,
+	}
+}
+
+// SetTTL 有効期間を変更（0以下の値は既定値のまま）
+func (uc *AuthTokenUseCase) SetTTL(access, refresh time.Duration) {
+	if access > 0 {
+		uc.accessTTL = access
+	}
+	if refresh > 0 {
+		uc.refreshTTL = refresh
+	}
+}
+
+// SetRefreshTokenRepository リフレッシュトークンの保存先を注入（未設定の場合リフレッシュトークンは発行しない）
+func (uc *AuthTokenUseCase) SetRefreshTokenRepository(repo domain.RefreshTokenRepository) {
+	uc.refreshRepo = repo
+}
+
+// SetDenylistRepository 拒否リストを注入（未設定の場合アクセストークンは期限まで有効）
+func (uc *AuthTokenUseCase) SetDenylistRepository(repo domain.TokenDenylistRepository) {
+	uc.denylist = repo
+}
+
+// SetUserRepository リフレッシュ時にユーザーの状態を確認するためのリポジトリを注入
+func (uc *AuthTokenUseCase) SetUserRepository(repo domain.UserRepository) {
+	uc.userRepo = repo
+}
+
+// Issue ログインしたユーザーに新しいセッションのトークンを発行
+func (uc *AuthTokenUseCase) Issue(user *domain.User, now time.Time) (*TokenPair, error) {
+	familyID, err := randomHex(tokenIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	return uc.issue(user, familyID, now)
+}
+
+// Refresh リフレッシュトークンを使用済みにして、同じセッションの新しいトークンを発行する
+// 使用済みのトークンが再び使われた場合はセッション全体を失効させる
+func (uc *AuthTokenUseCase) Refresh(token string, now time.Time) (*TokenPair, *domain.User, error) {
+	invalid := apperr.Wrap(apperr.ErrUnauthorized, "リフレッシュトークンが無効です")
+	if uc.refreshRepo == nil || uc.userRepo == nil {
+		return nil, nil, invalid
+	}
+	stored, err := uc.refreshRepo.GetByHash(hashToken(token))
+	if err != nil {
+		return nil, nil, invalid
+	}
+	if stored.RevokedAt != nil {
+		// 使用済みトークンの再利用: 漏洩したトークンで新しいセッションを作らせない
+		_ = uc.revokeFamily(stored.FamilyID, now)
+		return nil, nil, invalid
+	}
+	if !stored.Usable(now) {
+		return nil, nil, invalid
+	}
+	user, err := uc.userRepo.GetByID(stored.UserID)
+	if err != nil || !user.IsActive {
+		_ = uc.RevokeUser(stored.UserID, now)
+		return nil, nil, invalid
+	}
+	revoked, err := uc.refreshRepo.Revoke(stored.ID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	if !revoked {
+		// 同じトークンで同時にリフレッシュされ、先に使用済みになっていた場合も再利用として扱う
+		_ = uc.revokeFamily(stored.FamilyID, now)
+		return nil, nil, invalid
+	}
+	pair, err := uc.issue(user, stored.FamilyID, now)
+	if err != nil {
+		return nil, nil, err
+	}
+	return pair, user, nil
+}
+
+// Parse アクセストークンを検証（署名・期限・jti の有無・拒否リスト）
+func (uc *AuthTokenUseCase) Parse(token string, now time.Time) (*AccessClaims, error) {
+	invalid := apperr.Wrap(apperr.ErrUnauthorized, "トークンが無効です")
+	claims := &AccessClaims{}
+	parsed, err := jwt.ParseWithClaims(token, claims, func(*jwt.Token) (interface{}, error) { return uc.secret, nil },
+		jwt.WithValidMethods([]string{jwt.SigningMethodHS256.Alg()}),
+		jwt.WithExpirationRequired(),
+		jwt.WithTimeFunc(func() time.Time { return now }),
+	)
+	// jti の無いトークン（以前の形式）は失効させられないため受け付けない
+	if err != nil || !parsed.Valid || claims.ID == "" {
+		return nil, invalid
+	}
+	if uc.denylist != nil {
+		revoked, err := uc.denylist.Contains(claims.ID)
+		if err != nil {
+			return nil, err
+		}
+		if revoked {
+			return nil, apperr.Wrap(apperr.ErrUnauthorized, "トークンは失効しています")
+		}
+	}
+	return claims, nil
+}
+
+// Logout アクセストークンを拒否リストに追加し、そのセッションのリフレッシュトークンを失効させる
+// refreshToken を指定した場合は、そのトークンのセッションも失効させる（本人のものに限る）
+func (uc *AuthTokenUseCase) Logout(claims *AccessClaims, refreshToken string, now time.Time) error {
+	if claims != nil && claims.ExpiresAt != nil {
+		if err := uc.revokeAccess(claims.ID, claims.ExpiresAt.Time, now); err != nil {
+			return err
+		}
+	}
+	if uc.refreshRepo == nil {
+		return nil
+	}
+	if claims != nil {
+		if session, err := uc.refreshRepo.GetByAccessJTI(claims.ID); err == nil {
+			if err := uc.revokeFamily(session.FamilyID, now); err != nil {
+				return err
+			}
+		}
+	}
+	if refreshToken != "" {
+		session, err := uc.refreshRepo.GetByHash(hashToken(refreshToken))
+		if err != nil || (claims != nil && session.UserID != claims.UserID) {
+			return nil
+		}
+		return uc.revokeFamily(session.FamilyID, now)
+	}
+	return nil
+}
+
+// RevokeUser ユーザーのすべてのセッションを失効させる（無効化・削除したユーザーのアクセスを即座に止める）
+func (uc *AuthTokenUseCase) RevokeUser(userID int, now time.Time) error {
+	if uc.refreshRepo == nil {
+		return nil
+	}
+	sessions, err := uc.refreshRepo.ListActiveByUser(userID)
+	if err != nil {
+		return err
+	}
+	if err := uc.revokeSessions(sessions, now); err != nil {
+		return err
+	}
+	return uc.refreshRepo.RevokeByUser(userID, now)
+}
+
+func (uc *AuthTokenUseCase) issue(user *domain.User, familyID string, now time.Time) (*TokenPair, error) {
+	jti, err := randomHex(tokenIDBytes)
+	if err != nil {
+		return nil, err
+	}
+	pair := &TokenPair{AccessExpiresAt: now.Add(uc.accessTTL)}
+	claims := AccessClaims{
+		UserID:   user.ID,
+		Username: user.Username,
+		Role:     user.Role,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			Subject:   user.Username,
+			ExpiresAt: jwt.NewNumericDate(pair.AccessExpiresAt),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+	if pair.AccessToken, err = jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(uc.secret); err != nil {
+		return nil, apperr.Wrap(apperr.ErrInternal, "トークン生成に失敗しました")
+	}
+	if uc.refreshRepo == nil {
+		return pair, nil
+	}
+
+	secret, err := randomHex(refreshTokenBytes)
+	if err != nil {
+		return nil, err
+	}
+	pair.RefreshToken = refreshTokenPrefix + secret
+	pair.RefreshExpiresAt = now.Add(uc.refreshTTL)
+	err = uc.refreshRepo.Create(&domain.RefreshToken{
+		UserID:          user.ID,
+		TokenHash:       hashToken(pair.RefreshToken),
+		FamilyID:        familyID,
+		AccessJTI:       jti,
+		AccessExpiresAt: pair.AccessExpiresAt,
+		ExpiresAt:       pair.RefreshExpiresAt,
+		CreatedAt:       now,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pair, nil
+}
+
+// revokeFamily セッション（ローテーションで引き継いだトークン全体）を失効させる
+func (uc *AuthTokenUseCase) revokeFamily(familyID string, now time.Time) error {
+	sessions, err := uc.refreshRepo.ListActiveByFamily(familyID)
+	if err != nil {
+		return err
+	}
+	if err := uc.revokeSessions(sessions, now); err != nil {
+		return err
+	}
+	return uc.refreshRepo.RevokeFamily(familyID, now)
+}
+
+// revokeSessions セッションで発行した期限内のアクセストークンを拒否リストに追加
+func (uc *AuthTokenUseCase) revokeSessions(sessions []*domain.RefreshToken, now time.Time) error {
+	for _, s := range sessions {
+		if err := uc.revokeAccess(s.AccessJTI, s.AccessExpiresAt, now); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (uc *AuthTokenUseCase) revokeAccess(jti string, expiresAt, now time.Time) error {
+	if uc.denylist == nil || jti == "" || !now.Before(expiresAt) {
+		return nil
+	}
+	if err := uc.denylist.Add(jti, expiresAt); err != nil {
+		return err
+	}
+	// 期限を過ぎた行は署名の検証で拒否されるため、追加のついでに掃除する
+	return uc.denylist.DeleteExpired(now)
+}
+
+func randomHex(n int) (string, error) {
+	buf := make([]byte, n)
+	if _, err := rand.Read(buf); err != nil {
+		return "", apperr.Wrap(apperr.ErrInternal, "トークン生成に失敗しました")
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
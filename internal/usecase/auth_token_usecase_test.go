@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// memRefreshTokenRepo テスト用のリフレッシュトークンリポジトリ
+type memRefreshTokenRepo struct{ tokens []*domain.RefreshToken }
+
+func (r *memRefreshTokenRepo) Create(t *domain.RefreshToken) error {
+	t.ID = len(r.tokens) + 1
+	r.tokens = append(r.tokens, t)
+	return nil
+}
+
+func (r *memRefreshTokenRepo) find(match func(*domain.RefreshToken) bool) (*domain.RefreshToken, error) {
+	for _, t := range r.tokens {
+		if match(t) {
+			return t, nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+
+func (r *memRefreshTokenRepo) GetByHash(hash string) (*domain.RefreshToken, error) {
+	return r.find(func(t *domain.RefreshToken) bool { return t.TokenHash == hash })
+}
+
+func (r *memRefreshTokenRepo) GetByAccessJTI(jti string) (*domain.RefreshToken, error) {
+	return r.find(func(t *domain.RefreshToken) bool { return t.AccessJTI == jti })
+}
+
+func (r *memRefreshTokenRepo) active(match func(*domain.RefreshToken) bool) []*domain.RefreshToken {
+	var out []*domain.RefreshToken
+	for _, t := range r.tokens {
+		if t.RevokedAt == nil && match(t) {
+			out = append(out, t)
+		}
+	}
+	return out
+}
+
+func (r *memRefreshTokenRepo) ListActiveByUser(userID int) ([]*domain.RefreshToken, error) {
+	return r.active(func(t *domain.RefreshToken) bool { return t.UserID == userID }), nil
+}
+
+func (r *memRefreshTokenRepo) ListActiveByFamily(familyID string) ([]*domain.RefreshToken, error) {
+	return r.active(func(t *domain.RefreshToken) bool { return t.FamilyID == familyID }), nil
+}
+
+func (r *memRefreshTokenRepo) revoke(match func(*domain.RefreshToken) bool, at time.Time) error {
+	for _, t := range r.active(match) {
+		t.RevokedAt = &at
+	}
+	return nil
+}
+
+func (r *memRefreshTokenRepo) Revoke(id int, at time.Time) (bool, error) {
+	revoked := len(r.active(func(t *domain.RefreshToken) bool { return t.ID == id })) > 0
+	return revoked, r.revoke(func(t *domain.RefreshToken) bool { return t.ID == id }, at)
+}
+
+// staleRefreshTokenRepo 使用済みにされる前に読み取った内容を返す（同時リフレッシュの再現用）
+type staleRefreshTokenRepo struct {
+	*memRefreshTokenRepo
+	snapshot map[string]domain.RefreshToken
+}
+
+func (r *staleRefreshTokenRepo) GetByHash(hash string) (*domain.RefreshToken, error) {
+	if t, ok := r.snapshot[hash]; ok {
+		return &t, nil
+	}
+	t, err := r.memRefreshTokenRepo.GetByHash(hash)
+	if err != nil {
+		return nil, err
+	}
+	r.snapshot[hash] = *t
+	return t, nil
+}
+
+func (r *memRefreshTokenRepo) RevokeFamily(familyID string, at time.Time) error {
+	return r.revoke(func(t *domain.RefreshToken) bool { return t.FamilyID == familyID }, at)
+}
+
+func (r *memRefreshTokenRepo) RevokeByUser(userID int, at time.Time) error {
+	return r.revoke(func(t *domain.RefreshToken) bool { return t.UserID == userID }, at)
+}
+
+// memDenylist テスト用の拒否リスト
+type memDenylist map[string]time.Time
+
+func (d memDenylist) Add(jti string, expiresAt time.Time) error { d[jti] = expiresAt; return nil }
+func (d memDenylist) Contains(jti string) (bool, error)         { _, ok := d[jti]; return ok, nil }
+func (d memDenylist) DeleteExpired(now time.Time) error {
+	for jti, exp := range d {
+		if exp.Before(now) {
+			delete(d, jti)
+		}
+	}
+	return nil
+}
+
+func newAuthTokenFixture() (*AuthTokenUseCase, *memUserRepo, memDenylist) {
+	users := &memUserRepo{users: []domain.User{{ID: 1, Username: "alice", Role: "user", IsActive: true}}}
+	denylist := memDenylist{}
+	uc := NewAuthTokenUseCase("test-secret")
+	uc.SetRefreshTokenRepository(&memRefreshTokenRepo{})
+	uc.SetDenylistRepository(denylist)
+	uc.SetUserRepository(users)
+	return uc, users, denylist
+}
+
+func TestAuthTokenIssueAndParse(t *testing.T) {
+	uc, users, _ := newAuthTokenFixture()
+	now := time.Now()
+	pair, err := uc.Issue(&users.users[0], now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pair.AccessExpiresAt.Equal(now.Add(DefaultAccessTokenTTL)) || pair.RefreshToken == "" {
+		t.Fatalf("pair = %+v", pair)
+	}
+
+	claims, err := uc.Parse(pair.AccessToken, now)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.UserID != 1 || claims.Role != "user" || claims.ID == "" {
+		t.Errorf("claims = %+v", claims)
+	}
+	if _, err := uc.Parse(pair.AccessToken, now.Add(DefaultAccessTokenTTL+time.Second)); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("expired token error = %v, want unauthorized", err)
+	}
+	if _, err := NewAuthTokenUseCase("other-secret").Parse(pair.AccessToken, now); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("foreign signature error = %v, want unauthorized", err)
+	}
+}
+
+func TestAuthTokenRefreshRotatesAndDetectsReuse(t *testing.T) {
+	uc, users, _ := newAuthTokenFixture()
+	now := time.Now()
+	first, _ := uc.Issue(&users.users[0], now)
+
+	second, user, err := uc.Refresh(first.RefreshToken, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if user.ID != 1 || second.RefreshToken == first.RefreshToken {
+		t.Fatalf("refresh did not rotate: %+v", second)
+	}
+
+	// 使用済みトークンの再利用でセッション全体（ローテーション後のトークンとアクセストークン）を失効させる
+	if _, _, err := uc.Refresh(first.RefreshToken, now.Add(2*time.Minute)); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("reuse error = %v, want unauthorized", err)
+	}
+	if _, _, err := uc.Refresh(second.RefreshToken, now.Add(2*time.Minute)); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("rotated token still usable after reuse: %v", err)
+	}
+	if _, err := uc.Parse(second.AccessToken, now.Add(2*time.Minute)); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("access token still valid after reuse: %v", err)
+	}
+}
+
+func TestAuthTokenConcurrentRefreshTreatedAsReuse(t *testing.T) {
+	uc, users, _ := newAuthTokenFixture()
+	repo := &staleRefreshTokenRepo{memRefreshTokenRepo: &memRefreshTokenRepo{}, snapshot: map[string]domain.RefreshToken{}}
+	uc.SetRefreshTokenRepository(repo)
+	now := time.Now()
+	first, _ := uc.Issue(&users.users[0], now)
+
+	// 両方のリクエストが失効前のトークンを読み取った状態で、後からのリフレッシュの Revoke は失効済みで失敗する
+	// そのリフレッシュは拒否し、先に発行したトークンを含めてセッションを失効させる
+	if _, err := repo.GetByHash(hashToken(first.RefreshToken)); err != nil {
+		t.Fatal(err)
+	}
+	second, _, err := uc.Refresh(first.RefreshToken, now.Add(time.Minute))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := uc.Refresh(first.RefreshToken, now.Add(time.Minute)); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Fatalf("concurrent refresh error = %v, want unauthorized", err)
+	}
+	if _, err := uc.Parse(second.AccessToken, now.Add(2*time.Minute)); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("access token from the winning refresh still valid: %v", err)
+	}
+}
+
+func TestAuthTokenLogoutRevokesSession(t *testing.T) {
+	uc, users, denylist := newAuthTokenFixture()
+	now := time.Now()
+	pair, _ := uc.Issue(&users.users[0], now)
+	other, _ := uc.Issue(&users.users[0], now)
+	claims, _ := uc.Parse(pair.AccessToken, now)
+
+	if err := uc.Logout(claims, "", now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.Parse(pair.AccessToken, now); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("access token valid after logout: %v", err)
+	}
+	if _, _, err := uc.Refresh(pair.RefreshToken, now); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("refresh token valid after logout: %v", err)
+	}
+	// 別の端末のセッションはそのまま
+	if _, err := uc.Parse(other.AccessToken, now); err != nil {
+		t.Errorf("other session revoked by logout: %v", err)
+	}
+	if len(denylist) != 1 {
+		t.Errorf("denylist = %v, want only the logged out jti", denylist)
+	}
+}
+
+func TestAuthTokenDisabledUserLosesAccess(t *testing.T) {
+	uc, users, _ := newAuthTokenFixture()
+	now := time.Now()
+	pair, _ := uc.Issue(&users.users[0], now)
+
+	users.users[0].IsActive = false
+	if err := uc.RevokeUser(1, now); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.Parse(pair.AccessToken, now); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("access token valid after disabling user: %v", err)
+	}
+	if _, _, err := uc.Refresh(pair.RefreshToken, now); !errors.Is(err, apperr.ErrUnauthorized) {
+		t.Errorf("refresh token valid after disabling user: %v", err)
+	}
+}
@@ -0,0 +1,111 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/udiff"
+)
+
+// lineScope 差分から組み立てたコード断片と変更後ファイルの行の対応
+type lineScope struct {
+	newLines []int        // newLines[i] は断片の i+1 行目に対応する変更後ファイルの行番号
+	changed  map[int]bool // 追加された断片内の行
+}
+
+// diffFragment ハンクから組み立てた変更後側のコード断片（文脈行＋追加行）
+type diffFragment struct {
+	code  string
+	scope *lineScope
+}
+
+// diffFragments ファイル差分のハンクごとにコード断片を組み立てる（追加行の無いハンクは除く）
+// ハンクの間の省略された行をまたいで複数行のパターンが一致しないよう、ハンクは連結しない
+func diffFragments(file udiff.FileDiff) []diffFragment {
+	var fragments []diffFragment
+	for _, hunk := range file.Hunks {
+		var b strings.Builder
+		scope := &lineScope{changed: map[int]bool{}}
+		for _, line := range hunk.Lines {
+			if line.Kind == '-' {
+				continue
+			}
+			b.WriteString(line.Text)
+			b.WriteByte('\n')
+			scope.newLines = append(scope.newLines, line.NewLine)
+			if line.Kind == '+' {
+				scope.changed[len(scope.newLines)] = true
+			}
+		}
+		if len(scope.changed) > 0 {
+			fragments = append(fragments, diffFragment{code: b.String(), scope: scope})
+		}
+	}
+	return fragments
+}
+
+// validateFragments ハンクごとの断片を検証し、結果を1ファイル分にまとめる
+func (s *CompiledRuleSet) validateFragments(filename, language string, fragments []diffFragment) *domain.ValidationResult {
+	result := &domain.ValidationResult{
+		Valid:      true,
+		Errors:     []string{},
+		Warnings:   []string{},
+		Issues:     []domain.ValidationIssue{},
+		Suppressed: []domain.SuppressedIssue{},
+	}
+	seen := map[string]bool{}
+	for _, fragment := range fragments {
+		r := s.validate(filename, language, fragment.code, fragment.scope)
+		result.Valid = result.Valid && r.Valid
+		result.Issues = append(result.Issues, r.Issues...)
+		result.Suppressed = append(result.Suppressed, r.Suppressed...)
+		// 同じルールのメッセージはハンクをまたいで1回だけ載せる
+		for _, msg := range r.Errors {
+			if !seen["error:"+msg] {
+				seen["error:"+msg] = true
+				result.Errors = append(result.Errors, msg)
+			}
+		}
+		for _, msg := range r.Warnings {
+			if !seen["warning:"+msg] {
+				seen["warning:"+msg] = true
+				result.Warnings = append(result.Warnings, msg)
+			}
+		}
+	}
+	return result
+}
+
+// covers 問題の範囲が追加行に掛かっているか判定
+func (s *lineScope) covers(issue domain.ValidationIssue) bool {
+	last := issue.EndLineNumber
+	if last < issue.LineNumber {
+		last = issue.LineNumber
+	}
+	for line := issue.LineNumber; line <= last; line++ {
+		if s.changed[line] {
+			return true
+		}
+	}
+	return false
+}
+
+// line 断片内の行番号を変更後ファイルの行番号に変換
+func (s *lineScope) line(n int) int {
+	if n >= 1 && n <= len(s.newLines) {
+		return s.newLines[n-1]
+	}
+	return n
+}
+
+func (s *lineScope) remap(issue *domain.ValidationIssue) {
+	issue.LineNumber = s.line(issue.LineNumber)
+	if issue.EndLineNumber != 0 {
+		issue.EndLineNumber = s.line(issue.EndLineNumber)
+	}
+}
+
+func (s *lineScope) remapSuppressed(issue *domain.SuppressedIssue) {
+	s.remap(&issue.ValidationIssue)
+	issue.DirectiveLine = s.line(issue.DirectiveLine)
+}
@@ -0,0 +1,168 @@
+package usecase
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/udiff"
+)
+
+// textEdit コードに対する1件の置換
+type textEdit struct {
+	start, end int
+	text       string
+	issue      domain.ValidationIssue
+}
+
+// fixable 自動修正できるルールか判定
+func (cr compiledRule) fixable() bool {
+	return cr.rule.Replacement != "" && cr.re != nil
+}
+
+// replacementFor マッチ箇所の置換後テキスト（literalはテンプレートを展開しない）
+func (cr compiledRule) replacementFor(code string, m []int) string {
+	if cr.rule.Kind() == domain.MatcherLiteral {
+		return cr.rule.Replacement
+	}
+	return string(cr.re.ExpandString(nil, cr.rule.Replacement, code, m))
+}
+
+// validateReplacement 置換テンプレートがマッチャーとキャプチャグループに対して有効か検証
+func (cr compiledRule) validateReplacement() error {
+	switch cr.rule.Kind() {
+	case domain.MatcherLiteral:
+		return nil
+	case domain.MatcherRegex:
+		if cr.re == nil {
+			return fmt.Errorf("replacement requires a pattern")
+		}
+	default:
+		return fmt.Errorf("replacement is only supported for regex and literal matchers")
+	}
+
+	names := map[string]bool{}
+	for _, name := range cr.re.SubexpNames() {
+		if name != "" {
+			names[name] = true
+		}
+	}
+	for _, ref := range templateRefs(cr.rule.Replacement) {
+		if n, err := strconv.Atoi(ref); err == nil {
+			if n > cr.re.NumSubexp() {
+				return fmt.Errorf("replacement refers to undefined group $%s", ref)
+			}
+			continue
+		}
+		if !names[ref] {
+			return fmt.Errorf("replacement refers to undefined group ${%s} (use ${1}x to separate a group number from text)", ref)
+		}
+	}
+	return nil
+}
+
+// templateRefs regexp.Expand と同じ規則でテンプレート中のグループ参照を取り出す
+func templateRefs(template string) []string {
+	var refs []string
+	for i := 0; i < len(template); i++ {
+		if template[i] != '$' || i+1 >= len(template) {
+			continue
+		}
+		rest := template[i+1:]
+		if rest[0] == '$' {
+			i++
+			continue
+		}
+		if rest[0] == '{' {
+			if end := strings.IndexByte(rest, '}'); end > 1 {
+				refs = append(refs, rest[1:end])
+				i += end + 1
+			}
+			continue
+		}
+		n := 0
+		for n < len(rest) && (rest[n] == '_' || rest[n] >= '0' && rest[n] <= '9' || rest[n] >= 'a' && rest[n] <= 'z' || rest[n] >= 'A' && rest[n] <= 'Z') {
+			n++
+		}
+		if n > 0 {
+			refs = append(refs, rest[:n])
+			i += n
+		}
+	}
+	return refs
+}
+
+// edits 自動修正の置換を収集
+func (cr compiledRule) edits(li *lineIndex) []textEdit {
+	var edits []textEdit
+	for _, m := range cr.re.FindAllStringSubmatchIndex(li.code, maxIssuesPerRule) {
+		text := cr.replacementFor(li.code, m)
+		if text == li.code[m[0]:m[1]] {
+			continue
+		}
+		issue := newIssue(cr.rule, li, m[0], m[1])
+		issue.Replacement = text
+		edits = append(edits, textEdit{start: m[0], end: m[1], text: text, issue: issue})
+	}
+	return edits
+}
+
+// Fix 置換テンプレートを持つルールの修正をコードに適用
+// ruleIDsが空でなければ指定ルールの修正のみ適用し、抑制された箇所は修正しない
+func (s *CompiledRuleSet) Fix(filename, code string, ruleIDs []string) *domain.FixResult {
+	return s.FixLanguage(filename, languageForPath(filename), code, ruleIDs)
+}
+
+// FixLanguage 言語を指定して修正を適用（対象言語の異なるルールは適用しない）
+func (s *CompiledRuleSet) FixLanguage(filename, language, code string, ruleIDs []string) *domain.FixResult {
+	only := map[string]bool{}
+	for _, id := range ruleIDs {
+		only[id] = true
+	}
+
+	src := newSourceFile(code)
+	suppressions := parseSuppressions(src.li)
+	var edits []textEdit
+	for _, cr := range s.rules {
+		if !cr.fixable() || !cr.appliesTo(filename, language) || (len(only) > 0 && !only[cr.rule.RuleID]) {
+			continue
+		}
+		for _, e := range cr.edits(src.li) {
+			if _, ok := suppress(suppressions, e.issue); ok {
+				continue
+			}
+			edits = append(edits, e)
+		}
+	}
+	// 先に現れる修正を優先し、範囲が重なる修正は適用しない
+	sort.SliceStable(edits, func(i, j int) bool { return edits[i].start < edits[j].start })
+
+	result := &domain.FixResult{
+		Applied: []domain.ValidationIssue{},
+		Skipped: []domain.ValidationIssue{},
+	}
+	var b strings.Builder
+	last := 0
+	for _, e := range edits {
+		if e.start < last {
+			result.Skipped = append(result.Skipped, e.issue)
+			continue
+		}
+		b.WriteString(code[last:e.start])
+		b.WriteString(e.text)
+		last = e.end
+		result.Applied = append(result.Applied, e.issue)
+	}
+	b.WriteString(code[last:])
+
+	name := filename
+	if name == "" {
+		name = "code"
+	}
+	result.FixedCode = b.String()
+	result.Diff = udiff.Unified("a/"+name, "b/"+name, code, result.FixedCode)
+	result.Remaining = s.ValidateLanguage(filename, language, result.FixedCode).Issues
+	return result
+}
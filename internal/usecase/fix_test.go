@@ -0,0 +1,67 @@
+package usecase
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+func TestFix_AppliesCaptureGroupReplacement(t *testing.T) {
+	rules := []domain.Rule{
+		{RuleID: "no-console-log", Severity: "warning", Pattern: `console\.log\((.*?)\)`, Replacement: "logger.debug($1)", IsActive: true},
+		{RuleID: "no-var", Severity: "warning", Pattern: "var ", Replacement: "let ", IsActive: true,
+			RuleMatcher: domain.RuleMatcher{MatcherKind: domain.MatcherLiteral}},
+	}
+	code := "var a = 1;\nconsole.log(a);\n// rule-mcp-disable-next-line\nconsole.log('kept');\n"
+
+	result := NewRuleEngine().Compile("web-app", "javascript", rules).Fix("app.js", code, nil)
+
+	want := "let a = 1;\nlogger.debug(a);\n// rule-mcp-disable-next-line\nconsole.log('kept');\n"
+	if result.FixedCode != want {
+		t.Errorf("Unexpected fixed code:\n%s", result.FixedCode)
+	}
+	if len(result.Applied) != 2 || result.Applied[0].RuleID != "no-var" || result.Applied[1].Replacement != "logger.debug(a)" {
+		t.Errorf("Unexpected applied fixes: %+v", result.Applied)
+	}
+	if !strings.Contains(result.Diff, "--- a/app.js\n+++ b/app.js\n") || !strings.Contains(result.Diff, "-console.log(a);\n") || !strings.Contains(result.Diff, "+logger.debug(a);\n") {
+		t.Errorf("Unexpected diff:\n%s", result.Diff)
+	}
+	if len(result.Remaining) != 0 {
+		t.Errorf("Expected suppressed line not to be reported as remaining, got %+v", result.Remaining)
+	}
+}
+
+func TestFix_RuleFilterAndOverlap(t *testing.T) {
+	rules := []domain.Rule{
+		{RuleID: "a", Severity: "warning", Pattern: `foo bar`, Replacement: "x", IsActive: true},
+		{RuleID: "b", Severity: "warning", Pattern: `bar`, Replacement: "y", IsActive: true},
+	}
+	set := NewRuleEngine().Compile("p", "javascript", rules)
+
+	result := set.Fix("", "foo bar", nil)
+	if result.FixedCode != "x" || len(result.Skipped) != 1 || result.Skipped[0].RuleID != "b" {
+		t.Errorf("Expected overlapping fix to be skipped, got %+v", result)
+	}
+	if result := set.Fix("", "foo bar", []string{"b"}); result.FixedCode != "foo y" {
+		t.Errorf("Expected only rule b to be applied, got %q", result.FixedCode)
+	}
+}
+
+func TestValidateRule_Replacement(t *testing.T) {
+	valid := domain.Rule{Pattern: `(?P<obj>\w+)\.log\((.*)\)`, Replacement: "${obj}.debug($2)"}
+	if err := ValidateRule(valid); err != nil {
+		t.Errorf("Expected valid replacement, got %v", err)
+	}
+
+	invalid := []domain.Rule{
+		{Pattern: `console\.log\((.*)\)`, Replacement: "logger.debug($2)"},
+		{Pattern: `console\.log\((.*)\)`, Replacement: "$1x"},
+		{Pattern: `x`, Replacement: "y", RuleMatcher: domain.RuleMatcher{MatcherKind: domain.MatcherRequired}},
+	}
+	for _, rule := range invalid {
+		if err := ValidateRule(rule); err == nil {
+			t.Errorf("Expected replacement %q to be rejected", rule.Replacement)
+		}
+	}
+}
@@ -0,0 +1,58 @@
+package usecase
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// compileGlob globパターンを正規表現に変換（** はディレクトリを跨いで一致）
+// スラッシュを含まないパターンはファイル名（ベース名）に対して評価される
+func compileGlob(glob string) (*regexp.Regexp, error) {
+	var b strings.Builder
+	b.WriteString("^")
+	if !strings.Contains(glob, "/") {
+		b.WriteString("(?:.*/)?")
+	}
+	for i := 0; i < len(glob); i++ {
+		ch := glob[i]
+		switch ch {
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				i++
+				if i+1 < len(glob) && glob[i+1] == '/' {
+					i++
+					b.WriteString("(?:.*/)?")
+				} else {
+					b.WriteString(".*")
+				}
+			} else {
+				b.WriteString("[^/]*")
+			}
+		case '?':
+			b.WriteString("[^/]")
+		case '{':
+			end := strings.IndexByte(glob[i:], '}')
+			if end < 0 {
+				b.WriteString(regexp.QuoteMeta(string(ch)))
+				continue
+			}
+			alts := strings.Split(glob[i+1:i+end], ",")
+			for j, alt := range alts {
+				alts[j] = regexp.QuoteMeta(alt)
+			}
+			b.WriteString("(?:" + strings.Join(alts, "|") + ")")
+			i += end
+		default:
+			b.WriteString(regexp.QuoteMeta(string(ch)))
+		}
+	}
+	b.WriteString("$")
+	return regexp.Compile(b.String())
+}
+
+// normalizePath 区切り文字を / に揃え、先頭の ./ を取り除く
+func normalizePath(filename string) string {
+	filename = strings.ReplaceAll(filename, "\\", "/")
+	return strings.TrimPrefix(path.Clean("/"+filename), "/")
+}
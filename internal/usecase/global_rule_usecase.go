@@ -1,12 +1,17 @@
 package usecase
 
 import (
+	"errors"
+	"time"
+
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
 )
 
 type GlobalRuleUseCase struct {
 	globalRuleRepo domain.GlobalRuleRepository
+	engine         *RuleEngine
+	events         *RuleEvents
 }
 
 func NewGlobalRuleUseCase(globalRuleRepo domain.GlobalRuleRepository) *GlobalRuleUseCase {
@@ -15,11 +20,29 @@ func NewGlobalRuleUseCase(globalRuleRepo domain.GlobalRuleRepository) *GlobalRul
 	}
 }
 
-func (uc *GlobalRuleUseCase) CreateGlobalRule(language, ruleID, name, description, ruleType, severity, pattern, message string) error {
+// SetRuleEngine ルール変更時にキャッシュを破棄するルールエンジンを注入
+func (uc *GlobalRuleUseCase) SetRuleEngine(engine *RuleEngine) {
+	uc.engine = engine
+}
+
+// SetRuleEvents ルール変更の配信先を注入
+func (uc *GlobalRuleUseCase) SetRuleEvents(events *RuleEvents) {
+	uc.events = events
+}
+
+// invalidate 言語に紐づくコンパイル済みルールを破棄し、変更を配信
+func (uc *GlobalRuleUseCase) invalidate(language string) {
+	if uc.engine != nil {
+		uc.engine.InvalidateLanguage(language)
+	}
+	uc.events.Publish(RuleChange{Language: language})
+}
+
+func (uc *GlobalRuleUseCase) CreateGlobalRule(language, ruleID, name, description, ruleType, severity, pattern, message, replacement string, matcher domain.RuleMatcher) error {
 	if language == "" || ruleID == "" || name == "" {
 		return apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"language", "rule_id", "name"}})
 	}
-
+	now := time.Now()
 	rule := &domain.GlobalRule{
 		Language:    language,
 		RuleID:      ruleID,
@@ -29,20 +52,280 @@ func (uc *GlobalRuleUseCase) CreateGlobalRule(language, ruleID, name, descriptio
 		Severity:    severity,
 		Pattern:     pattern,
 		Message:     message,
+		Replacement: replacement,
 		IsActive:    true,
+		UpdatedAt:   &now,
+		RuleMatcher: matcher,
+	}
+	if err := ValidateRule(rule.AsRule("")); err != nil {
+		return err
 	}
 
-	return uc.globalRuleRepo.Create(rule)
+	if err := uc.globalRuleRepo.Create(rule); err != nil {
+		return err
+	}
+	uc.invalidate(language)
+	return nil
 }
 
+// GetGlobalRules 言語の有効なグローバルルール（プロジェクトが継承するルール）
 func (uc *GlobalRuleUseCase) GetGlobalRules(language string) ([]*domain.GlobalRule, error) {
 	return uc.globalRuleRepo.GetByLanguage(language)
 }
 
+// ListGlobalRules 言語のグローバルルール一覧（includeInactive なら無効化したルールも含め、再び有効にできるようにする）
+func (uc *GlobalRuleUseCase) ListGlobalRules(language string, includeInactive bool) ([]*domain.GlobalRule, error) {
+	if !includeInactive {
+		return uc.GetGlobalRules(language)
+	}
+	all, err := uc.globalRuleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	rules := []*domain.GlobalRule{}
+	for _, rule := range all {
+		if rule.Language == language {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, nil
+}
+
+// GetGlobalRule 無効化したルールも含めて取得
+func (uc *GlobalRuleUseCase) GetGlobalRule(language, ruleID string) (*domain.GlobalRule, error) {
+	if language == "" || ruleID == "" {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"language", "rule_id"}})
+	}
+	return uc.globalRuleRepo.GetByID(language, ruleID)
+}
+
+// GlobalRuleUpdate グローバルルールの更新内容（nil の項目は変更しない）
+type GlobalRuleUpdate struct {
+	Name        *string
+	Description *string
+	Type        *string
+	Severity    *string
+	Pattern     *string
+	Message     *string
+	Replacement *string
+	// Matcher 指定した場合はマッチャー設定を置き換える
+	Matcher  *domain.RuleMatcher
+	IsActive *bool
+}
+
+// UpdateGlobalRule グローバルルールを更新（is_active: false で削除せずに無効化できる）
+// 言語と rule_id は変更できない
+func (uc *GlobalRuleUseCase) UpdateGlobalRule(language, ruleID string, update GlobalRuleUpdate) (*domain.GlobalRule, error) {
+	existing, err := uc.GetGlobalRule(language, ruleID)
+	if err != nil {
+		return nil, err
+	}
+	if update.Name != nil {
+		if *update.Name == "" {
+			return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"empty": []string{"name"}})
+		}
+		existing.Name = *update.Name
+	}
+	for _, field := range []struct {
+		value  *string
+		target *string
+	}{
+		{update.Description, &existing.Description},
+		{update.Type, &existing.Type},
+		{update.Severity, &existing.Severity},
+		{update.Pattern, &existing.Pattern},
+		{update.Message, &existing.Message},
+		{update.Replacement, &existing.Replacement},
+	} {
+		if field.value != nil {
+			*field.target = *field.value
+		}
+	}
+	if update.Matcher != nil {
+		existing.RuleMatcher = *update.Matcher
+	}
+	if update.IsActive != nil {
+		existing.IsActive = *update.IsActive
+	}
+	if err := ValidateRule(existing.AsRule("")); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	existing.UpdatedAt = &now
+	if err := uc.globalRuleRepo.Update(existing); err != nil {
+		return nil, err
+	}
+	uc.invalidate(language)
+	return existing, nil
+}
+
 func (uc *GlobalRuleUseCase) GetAllLanguages() ([]string, error) {
 	return uc.globalRuleRepo.GetAllLanguages()
 }
 
 func (uc *GlobalRuleUseCase) DeleteGlobalRule(language, ruleID string) error {
-	return uc.globalRuleRepo.Delete(language, ruleID)
+	if err := uc.globalRuleRepo.Delete(language, ruleID); err != nil {
+		return err
+	}
+	uc.invalidate(language)
+	return nil
+}
+
+// ExportGlobalRules グローバルルールを取得（language が空の場合は全言語、ruleIDs を指定した場合はそのルールのみ）
+// 環境間の移行で失われないよう、無効化したルールも is_active: false として含める
+// 指定したルールが見つからない場合は、移行漏れに気づけるよう NotFound を返す
+func (uc *GlobalRuleUseCase) ExportGlobalRules(language string, ruleIDs []string) ([]*domain.GlobalRule, error) {
+	all, err := uc.globalRuleRepo.GetAll()
+	if err != nil {
+		return nil, err
+	}
+	rules := []*domain.GlobalRule{}
+	for _, rule := range all {
+		if language == "" || rule.Language == language {
+			rules = append(rules, rule)
+		}
+	}
+	if len(ruleIDs) == 0 {
+		return rules, nil
+	}
+
+	byID := make(map[string][]*domain.GlobalRule, len(rules))
+	for _, rule := range rules {
+		byID[rule.RuleID] = append(byID[rule.RuleID], rule)
+	}
+	selected := []*domain.GlobalRule{}
+	var missing []string
+	for _, ruleID := range ruleIDs {
+		if len(byID[ruleID]) == 0 {
+			missing = append(missing, ruleID)
+			continue
+		}
+		selected = append(selected, byID[ruleID]...)
+	}
+	if len(missing) > 0 {
+		return nil, apperr.WrapWithDetails(apperr.ErrNotFound, "指定したグローバルルールが見つかりません", map[string]interface{}{"language": language, "rule_ids": missing})
+	}
+	return selected, nil
+}
+
+// インポート結果の状態
+const (
+	ImportStatusCreated     = "created"
+	ImportStatusOverwritten = "overwritten"
+	ImportStatusSkipped     = "skipped"
+	ImportStatusFailed      = "failed"
+)
+
+// GlobalRuleImportResult ルールごとのインポート結果
+type GlobalRuleImportResult struct {
+	Language string      `json:"language"`
+	RuleID   string      `json:"rule_id"`
+	Status   string      `json:"status"`
+	Error    string      `json:"error,omitempty"`
+	Details  interface{} `json:"details,omitempty"`
+}
+
+// GlobalRuleImportReport インポート結果の集計
+type GlobalRuleImportReport struct {
+	Created     int                      `json:"createdCount"`
+	Overwritten int                      `json:"overwrittenCount"`
+	Skipped     int                      `json:"skippedCount"`
+	Failed      int                      `json:"errorCount"`
+	Results     []GlobalRuleImportResult `json:"results"`
+}
+
+// Imported 作成・上書きしたルールの数
+func (r *GlobalRuleImportReport) Imported() int {
+	return r.Created + r.Overwritten
+}
+
+func (r *GlobalRuleImportReport) add(rule *domain.GlobalRule, status string, err error) {
+	result := GlobalRuleImportResult{Language: rule.Language, RuleID: rule.RuleID, Status: status}
+	if err != nil {
+		result.Error = err.Error()
+		var withDetails *apperr.WithDetails
+		if errors.As(err, &withDetails) {
+			result.Details = withDetails.Details
+		}
+	}
+	switch status {
+	case ImportStatusCreated:
+		r.Created++
+	case ImportStatusOverwritten:
+		r.Overwritten++
+	case ImportStatusSkipped:
+		r.Skipped++
+	default:
+		r.Failed++
+	}
+	r.Results = append(r.Results, result)
+}
+
+// ImportGlobalRules グローバルルールを取り込み、ルールごとの結果を返す
+// 既存のルール（言語と rule_id が一致）は overwrite が true なら上書き、false ならスキップする
+// language を指定した場合、言語が空のルールはその言語とし、異なる言語のルールはエラーとする
+// 不正なルール・保存に失敗したルールはエラーとして記録し、残りのルールの取り込みは続ける
+func (uc *GlobalRuleUseCase) ImportGlobalRules(language string, rules []domain.GlobalRule, overwrite bool) (*GlobalRuleImportReport, error) {
+	report := &GlobalRuleImportReport{Results: []GlobalRuleImportResult{}}
+	seen := map[string]bool{}
+	touched := map[string]bool{}
+	now := time.Now()
+
+	for i := range rules {
+		rule := rules[i]
+		if rule.Language == "" {
+			rule.Language = language
+		}
+		if language != "" && rule.Language != language {
+			report.add(&rule, ImportStatusFailed, apperr.WrapWithDetails(apperr.ErrValidation, "言語が一致しません", map[string]interface{}{"expected": language}))
+			continue
+		}
+		if rule.Language == "" || rule.RuleID == "" || rule.Name == "" {
+			report.add(&rule, ImportStatusFailed, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"language", "rule_id", "name"}}))
+			continue
+		}
+		key := rule.Language + "/" + rule.RuleID
+		if seen[key] {
+			report.add(&rule, ImportStatusFailed, apperr.Wrap(apperr.ErrValidation, "同じルールがインポートデータ内で重複しています"))
+			continue
+		}
+		seen[key] = true
+		if err := ValidateRule(rule.AsRule("")); err != nil {
+			report.add(&rule, ImportStatusFailed, err)
+			continue
+		}
+
+		rule.ID = 0
+		rule.UpdatedAt = &now
+		status := ImportStatusCreated
+		var err error
+		current, lookupErr := uc.globalRuleRepo.GetByID(rule.Language, rule.RuleID)
+		switch {
+		case lookupErr == nil && !overwrite:
+			report.add(&rule, ImportStatusSkipped, nil)
+			continue
+		case lookupErr == nil:
+			// 無効化したルールも含めて上書きする
+			rule.ID = current.ID
+			status = ImportStatusOverwritten
+			err = uc.globalRuleRepo.Update(&rule)
+		case errors.Is(lookupErr, apperr.ErrNotFound):
+			err = uc.globalRuleRepo.Create(&rule)
+		default:
+			// 取得に失敗したルールは失敗として記録し、反映済みのルールのキャッシュ無効化まで続ける
+			err = lookupErr
+		}
+		if err != nil {
+			report.add(&rule, ImportStatusFailed, err)
+			continue
+		}
+		touched[rule.Language] = true
+		report.add(&rule, status, nil)
+	}
+
+	for lang := range touched {
+		uc.invalidate(lang)
+	}
+	return report, nil
 }
@@ -0,0 +1,226 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+func TestImportGlobalRulesOverwriteAndSkip(t *testing.T) {
+	repo := &memGlobalRuleRepo{rules: []*domain.GlobalRule{
+		{Language: "go", RuleID: "no-println", Name: "Old", Pattern: `fmt\.Println`, Message: "old", IsActive: true},
+	}}
+	uc := NewGlobalRuleUseCase(repo)
+	incoming := []domain.GlobalRule{
+		{RuleID: "no-println", Name: "No Println", Pattern: `fmt\.Println`, Message: "new", IsActive: true},
+		{RuleID: "no-panic", Name: "No Panic", Pattern: `panic\(`, Message: "m", IsActive: true},
+	}
+
+	report, err := uc.ImportGlobalRules("go", incoming, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 1 || report.Skipped != 1 || report.Failed != 0 {
+		t.Fatalf("skip report = %+v", report)
+	}
+	if rules, _ := repo.GetByLanguage("go"); len(rules) != 2 || rules[0].Message != "old" {
+		t.Errorf("existing rule changed without overwrite: %+v", rules)
+	}
+
+	report, err = uc.ImportGlobalRules("go", incoming, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Overwritten != 2 || report.Imported() != 2 {
+		t.Fatalf("overwrite report = %+v", report)
+	}
+	rules, _ := repo.GetByLanguage("go")
+	for _, rule := range rules {
+		if rule.RuleID == "no-println" && rule.Message != "new" {
+			t.Errorf("rule not overwritten: %+v", rule)
+		}
+	}
+}
+
+func TestImportGlobalRulesReportsEachFailure(t *testing.T) {
+	repo := &memGlobalRuleRepo{}
+	uc := NewGlobalRuleUseCase(repo)
+
+	report, err := uc.ImportGlobalRules("go", []domain.GlobalRule{
+		{RuleID: "ok", Name: "OK", Pattern: "x", IsActive: true},
+		{RuleID: "bad-regex", Name: "Bad", Pattern: "(", IsActive: true},
+		{Language: "python", RuleID: "other", Name: "Other", Pattern: "x"},
+		{RuleID: "", Name: "No ID", Pattern: "x"},
+		{RuleID: "ok", Name: "Duplicate", Pattern: "y"},
+	}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.Created != 1 || report.Failed != 4 {
+		t.Fatalf("report = %+v", report)
+	}
+	want := []string{ImportStatusCreated, ImportStatusFailed, ImportStatusFailed, ImportStatusFailed, ImportStatusFailed}
+	for i, result := range report.Results {
+		if result.Status != want[i] {
+			t.Errorf("results[%d] = %+v, want %s", i, result, want[i])
+		}
+		if result.Status == ImportStatusFailed && result.Error == "" {
+			t.Errorf("results[%d] has no error message", i)
+		}
+	}
+	if report.Results[1].Details == nil {
+		t.Error("invalid pattern should carry the matcher error details")
+	}
+	if len(repo.rules) != 1 {
+		t.Errorf("stored %d rules, want only the valid one", len(repo.rules))
+	}
+}
+
+// lookupFailingGlobalRuleRepo 特定のルールの取得だけが内部エラーになるリポジトリ
+type lookupFailingGlobalRuleRepo struct {
+	memGlobalRuleRepo
+	failRuleID string
+}
+
+func (r *lookupFailingGlobalRuleRepo) GetByID(language, ruleID string) (*domain.GlobalRule, error) {
+	if ruleID == r.failRuleID {
+		return nil, apperr.Wrap(apperr.ErrInternal, "データベースエラー")
+	}
+	return r.memGlobalRuleRepo.GetByID(language, ruleID)
+}
+
+func TestImportGlobalRulesRecordsLookupFailureAndInvalidates(t *testing.T) {
+	repo := &lookupFailingGlobalRuleRepo{failRuleID: "broken"}
+	uc := NewGlobalRuleUseCase(repo)
+	events := NewRuleEvents()
+	uc.SetRuleEvents(events)
+	var changed []string
+	events.Subscribe(func(change RuleChange) { changed = append(changed, change.Language) })
+
+	report, err := uc.ImportGlobalRules("go", []domain.GlobalRule{
+		{RuleID: "ok", Name: "OK", Pattern: "x", IsActive: true},
+		{RuleID: "broken", Name: "Broken", Pattern: "y", IsActive: true},
+		{RuleID: "after", Name: "After", Pattern: "z", IsActive: true},
+	}, true)
+	if err != nil {
+		t.Fatalf("import aborted: %v", err)
+	}
+	if report.Created != 2 || report.Failed != 1 || report.Results[1].Status != ImportStatusFailed {
+		t.Fatalf("report = %+v", report)
+	}
+	// 取得に失敗したルール以外は反映され、キャッシュも無効化される
+	if len(repo.rules) != 2 {
+		t.Errorf("stored %d rules, want 2", len(repo.rules))
+	}
+	if len(changed) != 1 || changed[0] != "go" {
+		t.Errorf("invalidated languages = %v, want [go]", changed)
+	}
+}
+
+func TestExportGlobalRules(t *testing.T) {
+	repo := &memGlobalRuleRepo{rules: []*domain.GlobalRule{
+		{Language: "go", RuleID: "a", Name: "A", IsActive: true},
+		{Language: "python", RuleID: "b", Name: "B", IsActive: true},
+	}}
+	uc := NewGlobalRuleUseCase(repo)
+
+	all, err := uc.ExportGlobalRules("", nil)
+	if err != nil || len(all) != 2 {
+		t.Fatalf("export all = %v, %v", all, err)
+	}
+	goRules, err := uc.ExportGlobalRules("go", []string{"a"})
+	if err != nil || len(goRules) != 1 || goRules[0].RuleID != "a" {
+		t.Fatalf("export go/a = %v, %v", goRules, err)
+	}
+	if _, err := uc.ExportGlobalRules("go", []string{"a", "missing"}); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("export with missing rule error = %v, want not found", err)
+	}
+}
+
+func TestExportGlobalRulesIncludesInactiveAndRoundTrips(t *testing.T) {
+	repo := &memGlobalRuleRepo{rules: []*domain.GlobalRule{
+		{Language: "go", RuleID: "active", Name: "Active", Pattern: "x", IsActive: true},
+		{Language: "go", RuleID: "retired", Name: "Retired", Pattern: "y", IsActive: false},
+		{Language: "rust", RuleID: "dormant", Name: "Dormant", Pattern: "z", IsActive: false},
+	}}
+	uc := NewGlobalRuleUseCase(repo)
+
+	// 全ルールが無効な言語も含める
+	exported, err := uc.ExportGlobalRules("", nil)
+	if err != nil || len(exported) != 3 {
+		t.Fatalf("export = %+v, %v; want inactive rules included", exported, err)
+	}
+	if rules, err := uc.ExportGlobalRules("go", []string{"retired"}); err != nil || len(rules) != 1 || rules[0].IsActive {
+		t.Errorf("export go/retired = %+v, %v", rules, err)
+	}
+
+	target := &memGlobalRuleRepo{}
+	incoming := make([]domain.GlobalRule, 0, len(exported))
+	for _, rule := range exported {
+		incoming = append(incoming, *rule)
+	}
+	report, err := NewGlobalRuleUseCase(target).ImportGlobalRules("", incoming, false)
+	if err != nil || report.Created != 3 {
+		t.Fatalf("import report = %+v, %v", report, err)
+	}
+	for _, rule := range target.rules {
+		if rule.IsActive != (rule.RuleID == "active") {
+			t.Errorf("imported %s/%s is_active = %v", rule.Language, rule.RuleID, rule.IsActive)
+		}
+	}
+}
+
+func TestUpdateGlobalRule(t *testing.T) {
+	repo := &memGlobalRuleRepo{rules: []*domain.GlobalRule{
+		{Language: "go", RuleID: "no-println", Name: "No Println", Severity: "error", Pattern: `fmt\.Println`, Message: "old", IsActive: true},
+	}}
+	uc := NewGlobalRuleUseCase(repo)
+	engine := NewRuleEngine()
+	uc.SetRuleEngine(engine)
+
+	message := "use the structured logger"
+	rule, err := uc.UpdateGlobalRule("go", "no-println", GlobalRuleUpdate{Message: &message})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 指定しなかった項目はそのまま
+	if rule.Message != message || rule.Pattern != `fmt\.Println` || rule.Severity != "error" || !rule.IsActive || rule.UpdatedAt == nil {
+		t.Errorf("updated rule = %+v", rule)
+	}
+
+	inactive := false
+	if _, err := uc.UpdateGlobalRule("go", "no-println", GlobalRuleUpdate{IsActive: &inactive}); err != nil {
+		t.Fatal(err)
+	}
+	if rules, _ := uc.GetGlobalRules("go"); len(rules) != 0 {
+		t.Errorf("deactivated rule still applied: %+v", rules)
+	}
+	if rule, err := uc.GetGlobalRule("go", "no-println"); err != nil || rule.IsActive {
+		t.Errorf("deactivated rule = %+v, %v; want kept and inactive", rule, err)
+	}
+}
+
+func TestUpdateGlobalRuleValidation(t *testing.T) {
+	repo := &memGlobalRuleRepo{rules: []*domain.GlobalRule{
+		{Language: "go", RuleID: "r", Name: "R", Pattern: "x", IsActive: true},
+	}}
+	uc := NewGlobalRuleUseCase(repo)
+
+	empty, badPattern := "", "("
+	for name, update := range map[string]GlobalRuleUpdate{
+		"empty name":    {Name: &empty},
+		"invalid regex": {Pattern: &badPattern},
+	} {
+		if _, err := uc.UpdateGlobalRule("go", "r", update); !errors.Is(err, apperr.ErrValidation) {
+			t.Errorf("%s: error = %v, want validation error", name, err)
+		}
+	}
+	if repo.rules[0].Name != "R" || repo.rules[0].Pattern != "x" {
+		t.Errorf("rejected update was stored: %+v", repo.rules[0])
+	}
+	if _, err := uc.UpdateGlobalRule("go", "missing", GlobalRuleUpdate{}); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("missing rule error = %v, want not found", err)
+	}
+}
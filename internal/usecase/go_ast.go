@@ -0,0 +1,201 @@
+package usecase
+
+import (
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path"
+	"strconv"
+	"strings"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+// go_ast マッチャーのチェック種別（Patternは "種別" または "種別:対象" 形式）
+//
+//	call:fmt.Println  パッケージ関数の呼び出し（import の別名も解決）
+//	call:panic        組み込み関数の呼び出し
+//	func:init         トップレベル関数の宣言
+//	doc:exported      ドキュメントコメントの無いエクスポート関数・メソッド
+const (
+	goCheckCall        = "call"
+	goCheckFunc        = "func"
+	goCheckDocExported = "doc:exported"
+)
+
+// goASTCheck コンパイル済みの go_ast チェック
+type goASTCheck struct {
+	kind string
+	pkg  string // call の場合のimportパス（組み込み関数なら空）
+	name string
+}
+
+// compileGoASTCheck Patternを go_ast チェックとして解釈
+func compileGoASTCheck(pattern string) (*goASTCheck, error) {
+	if pattern == goCheckDocExported {
+		return &goASTCheck{kind: goCheckDocExported}, nil
+	}
+
+	kind, target, ok := strings.Cut(pattern, ":")
+	target = strings.TrimSpace(target)
+	if !ok || target == "" {
+		return nil, fmt.Errorf("go_ast pattern must be call:<name>, func:<name> or %s", goCheckDocExported)
+	}
+
+	switch kind {
+	case goCheckCall:
+		check := &goASTCheck{kind: goCheckCall, name: target}
+		if i := strings.LastIndex(target, "."); i >= 0 {
+			check.pkg, check.name = target[:i], target[i+1:]
+		}
+		if !token.IsIdentifier(check.name) {
+			return nil, fmt.Errorf("invalid function name in go_ast pattern: %s", target)
+		}
+		return check, nil
+	case goCheckFunc:
+		if !token.IsIdentifier(target) {
+			return nil, fmt.Errorf("invalid function name in go_ast pattern: %s", target)
+		}
+		return &goASTCheck{kind: goCheckFunc, name: target}, nil
+	default:
+		return nil, fmt.Errorf("unknown go_ast check: %s", kind)
+	}
+}
+
+// goSource 解析済みのGoソース（オフセットは元のコード基準に補正して使う）
+type goSource struct {
+	fset *token.FileSet
+	file *ast.File
+	base int // 補完したコードの先頭からのずれ
+}
+
+// offset ノード位置を元コードのバイトオフセットに変換
+func (g *goSource) offset(pos token.Pos) int {
+	return g.fset.Position(pos).Offset - g.base
+}
+
+// parseGoSource Goコードを解析（パッケージ句の無い断片や文のみの断片も補完して解析）
+func parseGoSource(code string) *goSource {
+	wrappers := []struct{ prefix, suffix string }{
+		{"", ""},
+		{"package _\n", ""},
+		{"package _\nfunc _() {\n", "\n}"},
+	}
+	for _, w := range wrappers {
+		fset := token.NewFileSet()
+		file, err := parser.ParseFile(fset, "", w.prefix+code+w.suffix, parser.ParseComments)
+		if err == nil {
+			return &goSource{fset: fset, file: file, base: len(w.prefix)}
+		}
+	}
+	return nil
+}
+
+// findGoASTIssues 構文木を走査して違反箇所を検出
+func (cr compiledRule) findGoASTIssues(src *sourceFile) []domain.ValidationIssue {
+	g := src.goSource()
+	if g == nil {
+		return nil
+	}
+
+	var issues []domain.ValidationIssue
+	report := func(start, end token.Pos) {
+		from, to := g.offset(start), g.offset(end)
+		if from < 0 || to > len(src.li.code) || len(issues) >= maxIssuesPerRule {
+			return
+		}
+		issues = append(issues, newIssue(cr.rule, src.li, from, to))
+	}
+
+	check := cr.goCheck
+	switch check.kind {
+	case goCheckCall:
+		locals := importNames(g.file, check.pkg)
+		ast.Inspect(g.file, func(n ast.Node) bool {
+			call, ok := n.(*ast.CallExpr)
+			if ok && callMatches(call.Fun, check, locals) {
+				report(call.Pos(), call.End())
+			}
+			return true
+		})
+	case goCheckFunc:
+		for _, decl := range g.file.Decls {
+			if fn, ok := decl.(*ast.FuncDecl); ok && fn.Recv == nil && fn.Name.Name == check.name {
+				report(fn.Pos(), fn.Name.End())
+			}
+		}
+	case goCheckDocExported:
+		for _, decl := range g.file.Decls {
+			fn, ok := decl.(*ast.FuncDecl)
+			if !ok || fn.Doc != nil || !fn.Name.IsExported() {
+				continue
+			}
+			if fn.Recv != nil && !receiverExported(fn.Recv) {
+				continue
+			}
+			report(fn.Pos(), fn.Name.End())
+		}
+	}
+	return issues
+}
+
+// importNames importパスに対応するファイル内の参照名を返す
+// import宣言の無い断片ではパッケージ名をそのまま参照名とみなす
+func importNames(file *ast.File, importPath string) map[string]bool {
+	names := map[string]bool{}
+	if importPath == "" {
+		return names
+	}
+	if len(file.Imports) == 0 {
+		names[path.Base(importPath)] = true
+		return names
+	}
+	for _, imp := range file.Imports {
+		p, err := strconv.Unquote(imp.Path.Value)
+		if err != nil || p != importPath {
+			continue
+		}
+		if imp.Name != nil {
+			names[imp.Name.Name] = true
+		} else {
+			names[path.Base(p)] = true
+		}
+	}
+	return names
+}
+
+// callMatches 呼び出し対象がチェック対象の関数か判定
+func callMatches(fun ast.Expr, check *goASTCheck, locals map[string]bool) bool {
+	switch f := fun.(type) {
+	case *ast.Ident:
+		// ファイル内で宣言された同名の識別子は組み込み関数ではない
+		return check.pkg == "" && f.Name == check.name && f.Obj == nil
+	case *ast.SelectorExpr:
+		x, ok := f.X.(*ast.Ident)
+		return ok && check.pkg != "" && f.Sel.Name == check.name && locals[x.Name] && x.Obj == nil
+	}
+	return false
+}
+
+// receiverExported メソッドのレシーバー型がエクスポートされているか判定
+func receiverExported(recv *ast.FieldList) bool {
+	if len(recv.List) == 0 {
+		return false
+	}
+	typ := recv.List[0].Type
+	for {
+		switch t := typ.(type) {
+		case *ast.StarExpr:
+			typ = t.X
+		case *ast.IndexExpr:
+			typ = t.X
+		case *ast.IndexListExpr:
+			typ = t.X
+		case *ast.Ident:
+			return t.IsExported()
+		default:
+			return false
+		}
+	}
+}
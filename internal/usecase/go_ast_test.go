@@ -0,0 +1,88 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+const goASTSample = `package main
+
+import (
+	"fmt"
+	log "fmt"
+)
+
+// fmt.Println("commented out")
+
+func init() {}
+
+func Exported() {
+	msg := "fmt.Println(inside a string)"
+	fmt.Println(msg)
+	log.Println(msg)
+	panic(msg)
+}
+
+// Documented はドキュメント付き
+func Documented() {}
+
+type server struct{}
+
+func (s *server) Run() {}
+`
+
+func goASTRule(pattern string) domain.Rule {
+	return domain.Rule{RuleID: pattern, Severity: "warning", Pattern: pattern, IsActive: true,
+		RuleMatcher: domain.RuleMatcher{MatcherKind: domain.MatcherGoAST}}
+}
+
+func TestGoAST_Checks(t *testing.T) {
+	tests := []struct {
+		pattern string
+		want    [][2]int // 行, 開始列
+	}{
+		{"call:fmt.Println", [][2]int{{14, 2}, {15, 2}}},
+		{"call:panic", [][2]int{{16, 2}}},
+		{"func:init", [][2]int{{10, 1}}},
+		{"doc:exported", [][2]int{{12, 1}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.pattern, func(t *testing.T) {
+			rule := goASTRule(tt.pattern)
+			if err := ValidateRule(rule); err != nil {
+				t.Fatalf("Expected valid go_ast pattern, got %v", err)
+			}
+			issues := NewRuleEngine().Compile("p", "go", []domain.Rule{rule}).Validate("main.go", goASTSample).Issues
+			if len(issues) != len(tt.want) {
+				t.Fatalf("Expected %d issues, got %+v", len(tt.want), issues)
+			}
+			for i, want := range tt.want {
+				if issues[i].LineNumber != want[0] || issues[i].ColumnStart != want[1] {
+					t.Errorf("Expected %d:%d, got %d:%d", want[0], want[1], issues[i].LineNumber, issues[i].ColumnStart)
+				}
+			}
+		})
+	}
+}
+
+func TestGoAST_Fragments(t *testing.T) {
+	set := NewRuleEngine().Compile("p", "go", []domain.Rule{goASTRule("call:fmt.Println")})
+
+	issues := set.Validate("", "x := 1\nfmt.Println(x)").Issues
+	if len(issues) != 1 || issues[0].LineNumber != 2 || issues[0].Snippet != "fmt.Println(x)" {
+		t.Errorf("Expected statement fragment to be checked, got %+v", issues)
+	}
+	if issues := set.Validate("", "fmt.Println(("); len(issues.Issues) != 0 {
+		t.Errorf("Expected unparsable code to produce no AST issues, got %+v", issues.Issues)
+	}
+}
+
+func TestGoAST_RejectsUnknownCheck(t *testing.T) {
+	for _, pattern := range []string{"", "call:", "loop:for", "func:not valid"} {
+		if err := ValidateRule(domain.Rule{Pattern: pattern, RuleMatcher: domain.RuleMatcher{MatcherKind: domain.MatcherGoAST}}); err == nil {
+			t.Errorf("Expected %q to be rejected", pattern)
+		}
+	}
+}
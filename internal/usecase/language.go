@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"strings"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// 拡張子から推定する言語コード（languagesテーブルのcodeに対応）
+var extensionLanguages = map[string]string{
+	".js":    "javascript",
+	".jsx":   "javascript",
+	".mjs":   "javascript",
+	".cjs":   "javascript",
+	".ts":    "typescript",
+	".tsx":   "typescript",
+	".mts":   "typescript",
+	".cts":   "typescript",
+	".py":    "python",
+	".pyi":   "python",
+	".go":    "go",
+	".java":  "java",
+	".cpp":   "cpp",
+	".cc":    "cpp",
+	".cxx":   "cpp",
+	".hpp":   "cpp",
+	".h":     "cpp",
+	".cs":    "csharp",
+	".php":   "php",
+	".rb":    "ruby",
+	".rs":    "rust",
+	".swift": "swift",
+	".kt":    "kotlin",
+	".kts":   "kotlin",
+}
+
+// languageForPath ファイルパスの拡張子から言語を推定（不明な場合は空文字列）
+func languageForPath(filename string) string {
+	return extensionLanguages[strings.ToLower(path.Ext(normalizePath(filename)))]
+}
+
+// languageMatches ルールの対象言語がファイルの言語に適用されるか判定
+// どちらかが不明、または "general" のルールは常に適用する
+func languageMatches(ruleLanguage, fileLanguage string) bool {
+	return ruleLanguage == "" || fileLanguage == "" || ruleLanguage == "general" || ruleLanguage == fileLanguage
+}
+
+// projectLanguages プロジェクトのパスglobからファイルの言語を判定する
+type projectLanguages struct {
+	globs []languageGlob
+	// primary 言語を判定できない場合に使う主言語（general の場合は空にして全言語のルールを適用する）
+	primary string
+}
+
+type languageGlob struct {
+	language string
+	re       *regexp.Regexp
+}
+
+// newProjectLanguages プロジェクトの言語設定からglobをコンパイル（不正なglobは無視する）
+func newProjectLanguages(project *domain.Project) projectLanguages {
+	var pl projectLanguages
+	if project.Language != "general" {
+		pl.primary = project.Language
+	}
+	for _, l := range project.Languages {
+		for _, glob := range l.Paths {
+			re, err := compileGlob(glob)
+			if err != nil {
+				continue
+			}
+			pl.globs = append(pl.globs, languageGlob{language: l.Language, re: re})
+		}
+	}
+	return pl
+}
+
+// forPath ファイルの言語を判定
+// 指定された言語、最初に一致したパスglobの言語、拡張子から推定した言語、プロジェクトの主言語の順に採用する
+// （ファイル名も言語も無いコード断片に、他の言語のルールを適用しないため）
+func (pl projectLanguages) forPath(filename, language string) string {
+	if language != "" {
+		return language
+	}
+	if filename != "" {
+		normalized := normalizePath(filename)
+		for _, g := range pl.globs {
+			if g.re.MatchString(normalized) {
+				return g.language
+			}
+		}
+		if language := languageForPath(filename); language != "" {
+			return language
+		}
+	}
+	return pl.primary
+}
+
+// normalizeProjectLanguages プロジェクトの言語設定を検証し、主言語を補う
+// 主言語が空の場合は最初の言語を主言語とする
+func normalizeProjectLanguages(language string, languages []domain.ProjectLanguage) (string, []domain.ProjectLanguage, error) {
+	var invalid []string
+	seen := make(map[string]bool, len(languages))
+	var normalized []domain.ProjectLanguage
+	for i, l := range languages {
+		l.Language = strings.TrimSpace(l.Language)
+		if l.Language == "" || seen[l.Language] {
+			invalid = append(invalid, fmt.Sprintf("languages[%d].language", i))
+			continue
+		}
+		seen[l.Language] = true
+		for j, glob := range l.Paths {
+			if _, err := compileGlob(glob); err != nil || strings.TrimSpace(glob) == "" {
+				invalid = append(invalid, fmt.Sprintf("languages[%d].paths[%d]", i, j))
+			}
+		}
+		normalized = append(normalized, l)
+	}
+	if len(invalid) > 0 {
+		return "", nil, apperr.WrapWithDetails(apperr.ErrValidation, "言語の指定が不正です", map[string]interface{}{"invalid": invalid})
+	}
+	if language == "" && len(normalized) > 0 {
+		language = normalized[0].Language
+	}
+	return language, normalized, nil
+}
@@ -0,0 +1,211 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// 正規表現マッチャーで許可するフラグ
+const allowedRegexFlags = "imsU"
+
+// compiledRule 評価可能な状態にコンパイルされたルール
+type compiledRule struct {
+	rule    domain.Rule
+	re      *regexp.Regexp
+	glob    *regexp.Regexp
+	goCheck *goASTCheck
+}
+
+// compileRule マッチャー種別に応じてルールをコンパイル
+func compileRule(rule domain.Rule) (compiledRule, error) {
+	cr := compiledRule{rule: rule}
+	m := rule.RuleMatcher
+
+	for _, f := range m.Flags {
+		if !strings.ContainsRune(allowedRegexFlags, f) {
+			return cr, fmt.Errorf("unsupported regex flag: %c", f)
+		}
+	}
+	if m.FileGlob != "" {
+		glob, err := compileGlob(m.FileGlob)
+		if err != nil {
+			return cr, fmt.Errorf("invalid file_glob: %w", err)
+		}
+		cr.glob = glob
+	}
+
+	switch m.Kind() {
+	case domain.MatcherRegex:
+		// パターン未設定の正規表現ルールは何にも一致しない
+		if rule.Pattern == "" {
+			return cr, nil
+		}
+		re, err := compileRegex(rule.Pattern, m.Flags)
+		if err != nil {
+			return cr, err
+		}
+		cr.re = re
+	case domain.MatcherRequired:
+		if rule.Pattern == "" {
+			return cr, fmt.Errorf("pattern is required")
+		}
+		re, err := compileRegex(rule.Pattern, m.Flags)
+		if err != nil {
+			return cr, err
+		}
+		cr.re = re
+	case domain.MatcherLiteral:
+		if rule.Pattern == "" {
+			return cr, fmt.Errorf("pattern is required")
+		}
+		re, err := compileRegex(regexp.QuoteMeta(rule.Pattern), m.Flags)
+		if err != nil {
+			return cr, err
+		}
+		cr.re = re
+	case domain.MatcherMaxLineLength, domain.MatcherMaxFileLength:
+		if m.Limit <= 0 {
+			return cr, fmt.Errorf("limit must be greater than 0")
+		}
+	case domain.MatcherGoAST:
+		check, err := compileGoASTCheck(rule.Pattern)
+		if err != nil {
+			return cr, err
+		}
+		cr.goCheck = check
+	default:
+		return cr, fmt.Errorf("unknown matcher_kind: %s", m.MatcherKind)
+	}
+
+	if rule.Replacement != "" {
+		if err := cr.validateReplacement(); err != nil {
+			return cr, err
+		}
+	}
+	return cr, nil
+}
+
+// compileRegex フラグ付きで正規表現をコンパイル
+func compileRegex(pattern, flags string) (*regexp.Regexp, error) {
+	if flags != "" {
+		pattern = "(?" + flags + ")" + pattern
+	}
+	return regexp.Compile(pattern)
+}
+
+// appliesTo ルールがファイルに適用されるか判定（言語とfile_globで絞り込み、globが無ければ言語のみで判定）
+func (cr compiledRule) appliesTo(filename, language string) bool {
+	if !languageMatches(cr.rule.Language, language) {
+		return false
+	}
+	if cr.glob == nil {
+		return true
+	}
+	if filename == "" {
+		return false
+	}
+	return cr.glob.MatchString(normalizePath(filename))
+}
+
+// fileLevel ファイル全体を対象とするマッチャーか判定
+func (cr compiledRule) fileLevel() bool {
+	kind := cr.rule.Kind()
+	return kind == domain.MatcherRequired || kind == domain.MatcherMaxFileLength
+}
+
+// findIssues マッチャー種別に応じて違反箇所を検出
+func (cr compiledRule) findIssues(src *sourceFile) []domain.ValidationIssue {
+	var issues []domain.ValidationIssue
+	li := src.li
+
+	switch cr.rule.Kind() {
+	case domain.MatcherRegex, domain.MatcherLiteral:
+		if cr.re == nil {
+			return nil
+		}
+		for _, m := range cr.re.FindAllStringSubmatchIndex(li.code, maxIssuesPerRule) {
+			issue := newIssue(cr.rule, li, m[0], m[1])
+			if cr.fixable() {
+				issue.Replacement = cr.replacementFor(li.code, m)
+			}
+			issues = append(issues, issue)
+		}
+	case domain.MatcherRequired:
+		if !cr.re.MatchString(li.code) {
+			issue := newIssue(cr.rule, li, 0, 0)
+			issue.Snippet = ""
+			issues = append(issues, issue)
+		}
+	case domain.MatcherMaxLineLength:
+		for n := 1; n <= li.lineCount() && len(issues) < maxIssuesPerRule; n++ {
+			start, end := li.lineBounds(n)
+			line := li.code[start:end]
+			if utf8.RuneCountInString(line) <= cr.rule.Limit {
+				continue
+			}
+			over := start + len(string([]rune(line)[:cr.rule.Limit]))
+			issues = append(issues, newIssue(cr.rule, li, over, end))
+		}
+	case domain.MatcherMaxFileLength:
+		if li.lineCount() > cr.rule.Limit {
+			start, _ := li.lineBounds(cr.rule.Limit + 1)
+			issue := newIssue(cr.rule, li, start, start)
+			issue.Snippet = ""
+			issues = append(issues, issue)
+		}
+	case domain.MatcherGoAST:
+		issues = cr.findGoASTIssues(src)
+	}
+
+	return issues
+}
+
+// ruleSeverities ルールに指定できる重要度
+var ruleSeverities = []string{"error", "warning", "info"}
+
+// validateSeverity 重要度が ruleSeverities のいずれかか検証
+func validateSeverity(severity string) error {
+	if !slices.Contains(ruleSeverities, severity) {
+		return apperr.WrapWithDetails(apperr.ErrValidation, "重要度が不正です", map[string]interface{}{"severity": severity, "allowed": ruleSeverities})
+	}
+	return nil
+}
+
+// ValidateRule ルールのパターン・マッチャー設定・置換テンプレートを検証
+func ValidateRule(rule domain.Rule) error {
+	if details := ruleErrorDetails(rule); details != nil {
+		return apperr.WrapWithDetails(apperr.ErrValidation, "ルールのマッチャー設定が不正です", details)
+	}
+	return nil
+}
+
+// ruleErrorDetails ルール設定のエラー詳細を返す。正常ならnil
+func ruleErrorDetails(rule domain.Rule) map[string]interface{} {
+	_, err := compileRule(rule)
+	if err == nil {
+		return nil
+	}
+
+	matcher := rule.RuleMatcher
+	kind := matcher.Kind()
+	if kind == domain.MatcherRegex || kind == domain.MatcherRequired {
+		if details := patternErrorDetails(rule.Pattern); details != nil {
+			details["matcher_kind"] = kind
+			return details
+		}
+	}
+	return map[string]interface{}{
+		"matcher_kind": kind,
+		"flags":        matcher.Flags,
+		"file_glob":    matcher.FileGlob,
+		"limit":        matcher.Limit,
+		"replacement":  rule.Replacement,
+		"error":        err.Error(),
+	}
+}
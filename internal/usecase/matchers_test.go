@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+func TestMatchers_Kinds(t *testing.T) {
+	code := "const a = 1;\nconsole.log(a.b);\n// a very long comment line\n"
+
+	tests := []struct {
+		name    string
+		matcher domain.RuleMatcher
+		pattern string
+		want    []domain.ValidationIssue
+	}{
+		{
+			name:    "literal escapes metacharacters",
+			matcher: domain.RuleMatcher{MatcherKind: domain.MatcherLiteral},
+			pattern: "a.b",
+			want:    []domain.ValidationIssue{{LineNumber: 2, ColumnStart: 13, ColumnEnd: 16}},
+		},
+		{
+			name:    "regex flags",
+			matcher: domain.RuleMatcher{Flags: "i"},
+			pattern: `CONSOLE\.LOG`,
+			want:    []domain.ValidationIssue{{LineNumber: 2, ColumnStart: 1, ColumnEnd: 12}},
+		},
+		{
+			name:    "required pattern missing",
+			matcher: domain.RuleMatcher{MatcherKind: domain.MatcherRequired},
+			pattern: `use strict`,
+			want:    []domain.ValidationIssue{{LineNumber: 1, ColumnStart: 1, ColumnEnd: 1}},
+		},
+		{
+			name:    "required pattern present",
+			matcher: domain.RuleMatcher{MatcherKind: domain.MatcherRequired},
+			pattern: `const`,
+		},
+		{
+			name:    "max line length",
+			matcher: domain.RuleMatcher{MatcherKind: domain.MatcherMaxLineLength, Limit: 20},
+			want:    []domain.ValidationIssue{{LineNumber: 3, ColumnStart: 21, ColumnEnd: 28}},
+		},
+		{
+			name:    "max file length ignores trailing newline",
+			matcher: domain.RuleMatcher{MatcherKind: domain.MatcherMaxFileLength, Limit: 3},
+		},
+		{
+			name:    "max file length exceeded",
+			matcher: domain.RuleMatcher{MatcherKind: domain.MatcherMaxFileLength, Limit: 2},
+			want:    []domain.ValidationIssue{{LineNumber: 3, ColumnStart: 1, ColumnEnd: 1}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rule := domain.Rule{RuleID: "r", Severity: "warning", Pattern: tt.pattern, IsActive: true, RuleMatcher: tt.matcher}
+			if err := ValidateRule(rule); err != nil {
+				t.Fatalf("Expected valid matcher, got %v", err)
+			}
+			issues := NewRuleEngine().Compile("p", "javascript", []domain.Rule{rule}).Validate("", code).Issues
+			if len(issues) != len(tt.want) {
+				t.Fatalf("Expected %d issues, got %+v", len(tt.want), issues)
+			}
+			for i, want := range tt.want {
+				got := issues[i]
+				if got.LineNumber != want.LineNumber || got.ColumnStart != want.ColumnStart || got.ColumnEnd != want.ColumnEnd {
+					t.Errorf("Expected %d:%d-%d, got %d:%d-%d", want.LineNumber, want.ColumnStart, want.ColumnEnd, got.LineNumber, got.ColumnStart, got.ColumnEnd)
+				}
+			}
+		})
+	}
+}
+
+func TestMatchers_FileGlobScopesRule(t *testing.T) {
+	rule := domain.Rule{RuleID: "no-sleep-in-tests", Severity: "warning", Pattern: `time\.Sleep`, IsActive: true,
+		RuleMatcher: domain.RuleMatcher{FileGlob: "**/*_test.go"}}
+	set := NewRuleEngine().Compile("p", "go", []domain.Rule{rule})
+	code := "time.Sleep(1)"
+
+	if got := len(set.Validate("internal/usecase/engine_test.go", code).Issues); got != 1 {
+		t.Errorf("Expected rule to apply to test file, got %d issues", got)
+	}
+	if got := len(set.Validate("internal/usecase/engine.go", code).Issues); got != 0 {
+		t.Errorf("Expected rule to skip non-test file, got %d issues", got)
+	}
+	if got := len(set.Validate("", code).Issues); got != 0 {
+		t.Errorf("Expected glob-scoped rule to skip when filename is unknown, got %d issues", got)
+	}
+}
+
+func TestValidateRule_RejectsInvalidMatchers(t *testing.T) {
+	invalid := []domain.RuleMatcher{
+		{MatcherKind: "ast"},
+		{MatcherKind: domain.MatcherMaxLineLength},
+		{Flags: "x"},
+		{MatcherKind: domain.MatcherLiteral},
+	}
+	for _, m := range invalid {
+		if err := ValidateRule(domain.Rule{RuleMatcher: m}); err == nil {
+			t.Errorf("Expected %+v to be rejected", m)
+		}
+	}
+}
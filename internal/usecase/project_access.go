@@ -0,0 +1,209 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// Principal 操作を行う利用者（認証ミドルウェアの結果から作成する）
+type Principal struct {
+	UserID        int
+	Username      string
+	Role          string // システムロール（admin / user / public など）
+	Permissions   map[string]bool
+	Authenticated bool
+	// ProjectScope APIキーで利用できるプロジェクト（空なら制限なし）
+	ProjectScope []string
+	// Local 利用者自身が起動したローカルプロセス（stdio）。すべてのプロジェクトを扱える
+	Local bool
+}
+
+// inScope APIキーのプロジェクトスコープにプロジェクトが含まれるか判定
+func (p Principal) inScope(projectID string) bool {
+	return (&domain.APIKey{ProjectIDs: p.ProjectScope}).AllowsProject(projectID)
+}
+
+// ProjectAccessUseCase プロジェクトの公開範囲とメンバーのロールによる認可
+//
+// 実効ロールは次の最大値:
+//   - システム管理者（admin ロール）とローカルプロセスは owner
+//   - project_members に登録されたロール
+//   - 公開範囲で閲覧できる場合は viewer（admin 権限を持つ認証済みの利用者は editor）
+//     public は誰でも、internal は認証済みの利用者、private はスコープで明示的に許可されたAPIキーのみ
+//     （未知の公開範囲は private として扱う）
+//
+// manage_rules 権限は既定の user ロールにも付くため、公開範囲だけでは editor にしない
+// （編集はメンバーとして登録されたプロジェクトに限る）
+//
+// APIキーのプロジェクトスコープ外のプロジェクトはロールに関わらず扱えない
+type ProjectAccessUseCase struct {
+	projectRepo domain.ProjectRepository
+	memberRepo  domain.ProjectMemberRepository
+	userRepo    domain.UserRepository
+}
+
+func NewProjectAccessUseCase(projectRepo domain.ProjectRepository) *ProjectAccessUseCase {
+	return &ProjectAccessUseCase{projectRepo: projectRepo}
+}
+
+// SetMemberRepository メンバーリポジトリを注入（未設定の場合、メンバーによる許可は行わない）
+func (uc *ProjectAccessUseCase) SetMemberRepository(repo domain.ProjectMemberRepository) {
+	uc.memberRepo = repo
+}
+
+// SetUserRepository ユーザー名でメンバーを追加するためのユーザーリポジトリを注入
+func (uc *ProjectAccessUseCase) SetUserRepository(repo domain.UserRepository) {
+	uc.userRepo = repo
+}
+
+// ProjectRole プロジェクトに対する実効ロール（扱えない場合は空）
+func (uc *ProjectAccessUseCase) ProjectRole(p Principal, project *domain.Project) string {
+	return uc.projectRole(p, project, uc.memberRole(p, project.ProjectID))
+}
+
+func (uc *ProjectAccessUseCase) projectRole(p Principal, project *domain.Project, memberRole string) string {
+	if !p.inScope(project.ProjectID) {
+		return ""
+	}
+	if p.Local || p.Role == "admin" {
+		return domain.ProjectRoleOwner
+	}
+
+	base := ""
+	switch project.AccessLevel {
+	case domain.ProjectAccessPublic:
+		base = domain.ProjectRoleViewer
+	case domain.ProjectAccessInternal:
+		if p.Authenticated {
+			base = domain.ProjectRoleViewer
+		}
+	default:
+		// private と未知の公開範囲: スコープに列挙されたAPIキーは、管理者が個別に許可したものとして扱う
+		if len(p.ProjectScope) > 0 {
+			base = domain.ProjectRoleViewer
+		}
+	}
+	if base != "" && p.Authenticated && p.Permissions["admin"] {
+		base = domain.ProjectRoleEditor
+	}
+
+	if domain.ProjectRoleRank(memberRole) > domain.ProjectRoleRank(base) {
+		return memberRole
+	}
+	return base
+}
+
+func (uc *ProjectAccessUseCase) memberRole(p Principal, projectID string) string {
+	if uc.memberRepo == nil || p.UserID == 0 {
+		return ""
+	}
+	member, err := uc.memberRepo.Get(projectID, p.UserID)
+	if err != nil {
+		return ""
+	}
+	return member.Role
+}
+
+// Authorize プロジェクトに required 以上のロールを持つか確認してプロジェクトを返す
+// APIキーのスコープ外とロール不足は Forbidden、閲覧もできないプロジェクトは存在を明かさないため NotFound
+func (uc *ProjectAccessUseCase) Authorize(p Principal, projectID, required string) (*domain.Project, error) {
+	if !p.inScope(projectID) {
+		return nil, apperr.WrapWithDetails(apperr.ErrForbidden, "このAPIキーでは対象のプロジェクトにアクセスできません", map[string]string{"project_id": projectID})
+	}
+	project, err := uc.projectRepo.GetByID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	role := uc.ProjectRole(p, project)
+	if role == "" {
+		return nil, apperr.WrapWithDetails(apperr.ErrNotFound, "プロジェクトが見つかりません", map[string]string{"project_id": projectID})
+	}
+	if domain.ProjectRoleRank(role) < domain.ProjectRoleRank(required) {
+		return nil, apperr.WrapWithDetails(apperr.ErrForbidden, "プロジェクトに対する権限が不足しています", map[string]string{"project_id": projectID, "required_role": required, "role": role})
+	}
+	return project, nil
+}
+
+// VisibleProjects 閲覧できるプロジェクトだけを返す
+func (uc *ProjectAccessUseCase) VisibleProjects(p Principal, projects []*domain.Project) []*domain.Project {
+	roles := make(map[string]string)
+	if uc.memberRepo != nil && p.UserID != 0 {
+		if members, err := uc.memberRepo.GetByUser(p.UserID); err == nil {
+			for _, m := range members {
+				roles[m.ProjectID] = m.Role
+			}
+		}
+	}
+	visible := make([]*domain.Project, 0, len(projects))
+	for _, project := range projects {
+		if uc.projectRole(p, project, roles[project.ProjectID]) != "" {
+			visible = append(visible, project)
+		}
+	}
+	return visible
+}
+
+// ListMembers プロジェクトのメンバー一覧（viewer 以上）
+func (uc *ProjectAccessUseCase) ListMembers(p Principal, projectID string) ([]*domain.ProjectMember, error) {
+	if _, err := uc.Authorize(p, projectID, domain.ProjectRoleViewer); err != nil {
+		return nil, err
+	}
+	if uc.memberRepo == nil {
+		return []*domain.ProjectMember{}, nil
+	}
+	return uc.memberRepo.GetByProject(projectID)
+}
+
+// SetMember メンバーを追加またはロールを変更（owner のみ）。userID が0の場合は username で検索する
+func (uc *ProjectAccessUseCase) SetMember(p Principal, projectID string, userID int, username, role string) (*domain.ProjectMember, error) {
+	if domain.ProjectRoleRank(role) == 0 {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "ロールは viewer・editor・owner のいずれかを指定してください", map[string]string{"role": role})
+	}
+	if _, err := uc.Authorize(p, projectID, domain.ProjectRoleOwner); err != nil {
+		return nil, err
+	}
+	if uc.memberRepo == nil || uc.userRepo == nil {
+		return nil, apperr.Wrap(apperr.ErrUnprocessable, "メンバー管理にはデータベースが必要です")
+	}
+
+	var user *domain.User
+	var err error
+	switch {
+	case userID != 0:
+		user, err = uc.userRepo.GetByID(userID)
+	case username != "":
+		user, err = uc.userRepo.GetByUsername(username)
+	default:
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"user_id", "username"}})
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	member := &domain.ProjectMember{ProjectID: projectID, UserID: user.ID, Username: user.Username, Role: role, CreatedAt: time.Now()}
+	if err := uc.memberRepo.Save(member); err != nil {
+		return nil, err
+	}
+	return member, nil
+}
+
+// RemoveMember メンバーを削除（owner のみ）
+func (uc *ProjectAccessUseCase) RemoveMember(p Principal, projectID string, userID int) error {
+	if _, err := uc.Authorize(p, projectID, domain.ProjectRoleOwner); err != nil {
+		return err
+	}
+	if uc.memberRepo == nil {
+		return apperr.Wrap(apperr.ErrUnprocessable, "メンバー管理にはデータベースが必要です")
+	}
+	return uc.memberRepo.Delete(projectID, userID)
+}
+
+// GrantCreator プロジェクトを作成したユーザーを owner として登録（ユーザーに紐づかない作成者は何もしない）
+func (uc *ProjectAccessUseCase) GrantCreator(p Principal, projectID string) error {
+	if uc.memberRepo == nil || p.UserID == 0 {
+		return nil
+	}
+	return uc.memberRepo.Save(&domain.ProjectMember{ProjectID: projectID, UserID: p.UserID, Username: p.Username, Role: domain.ProjectRoleOwner, CreatedAt: time.Now()})
+}
@@ -0,0 +1,208 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+type memMemberRepo struct{ members []*domain.ProjectMember }
+
+func (r *memMemberRepo) GetByProject(projectID string) ([]*domain.ProjectMember, error) {
+	var out []*domain.ProjectMember
+	for _, m := range r.members {
+		if m.ProjectID == projectID {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (r *memMemberRepo) GetByUser(userID int) ([]*domain.ProjectMember, error) {
+	var out []*domain.ProjectMember
+	for _, m := range r.members {
+		if m.UserID == userID {
+			out = append(out, m)
+		}
+	}
+	return out, nil
+}
+
+func (r *memMemberRepo) Get(projectID string, userID int) (*domain.ProjectMember, error) {
+	for _, m := range r.members {
+		if m.ProjectID == projectID && m.UserID == userID {
+			return m, nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+
+func (r *memMemberRepo) Save(member *domain.ProjectMember) error {
+	if m, err := r.Get(member.ProjectID, member.UserID); err == nil {
+		m.Role = member.Role
+		return nil
+	}
+	r.members = append(r.members, member)
+	return nil
+}
+
+func (r *memMemberRepo) Delete(projectID string, userID int) error {
+	for i, m := range r.members {
+		if m.ProjectID == projectID && m.UserID == userID {
+			r.members = append(r.members[:i], r.members[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+type memUserRepo struct{ users []domain.User }
+
+func (r *memUserRepo) GetByID(id int) (*domain.User, error) {
+	for i := range r.users {
+		if r.users[i].ID == id {
+			return &r.users[i], nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+
+func (r *memUserRepo) GetByUsername(username string) (*domain.User, error) {
+	for i := range r.users {
+		if r.users[i].Username == username {
+			return &r.users[i], nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+
+func (r *memUserRepo) GetByEmail(email string) (*domain.User, error) { return nil, apperr.ErrNotFound }
+func (r *memUserRepo) GetAll() ([]domain.User, error)                { return r.users, nil }
+func (r *memUserRepo) Create(user *domain.User) error                { return nil }
+func (r *memUserRepo) Update(user *domain.User) error                { return nil }
+func (r *memUserRepo) Delete(id int) error                           { return nil }
+func (r *memUserRepo) GetActiveUsers() ([]domain.User, error)        { return r.users, nil }
+func (r *memUserRepo) GetUsersByRole(role string) ([]domain.User, error) {
+	return nil, nil
+}
+
+func newProjectAccessFixture() (*ProjectAccessUseCase, *memMemberRepo) {
+	projects := &memProjectRepo{projects: map[string]*domain.Project{
+		"open":   {ProjectID: "open", AccessLevel: domain.ProjectAccessPublic},
+		"team":   {ProjectID: "team", AccessLevel: domain.ProjectAccessInternal},
+		"client": {ProjectID: "client", AccessLevel: domain.ProjectAccessPrivate},
+		// 移行前の値が残ったプロジェクト
+		"legacy": {ProjectID: "legacy", AccessLevel: "user"},
+	}}
+	members := &memMemberRepo{members: []*domain.ProjectMember{
+		{ProjectID: "client", UserID: 2, Username: "alice", Role: domain.ProjectRoleViewer},
+		{ProjectID: "client", UserID: 3, Username: "owner", Role: domain.ProjectRoleOwner},
+	}}
+	uc := NewProjectAccessUseCase(projects)
+	uc.SetMemberRepository(members)
+	uc.SetUserRepository(&memUserRepo{users: []domain.User{{ID: 2, Username: "alice"}, {ID: 3, Username: "owner"}, {ID: 4, Username: "bob"}}})
+	return uc, members
+}
+
+func TestProjectRoleByAccessLevel(t *testing.T) {
+	uc, _ := newProjectAccessFixture()
+	anonymous := Principal{Role: "public"}
+	user := Principal{UserID: 4, Role: "user", Authenticated: true, Permissions: map[string]bool{"manage_rules": true}}
+	adminPerm := Principal{UserID: 5, Role: "maintainer", Authenticated: true, Permissions: map[string]bool{"admin": true}}
+	member := Principal{UserID: 2, Role: "user", Authenticated: true}
+	scoped := Principal{Role: "user", Authenticated: true, ProjectScope: []string{"client"}}
+
+	tests := []struct {
+		name      string
+		principal Principal
+		projectID string
+		want      string
+	}{
+		{"anonymous public", anonymous, "open", domain.ProjectRoleViewer},
+		{"anonymous internal", anonymous, "team", ""},
+		{"anonymous private", anonymous, "client", ""},
+		{"anonymous unknown level", anonymous, "legacy", ""},
+		{"authenticated unknown level", user, "legacy", ""},
+		{"manage_rules internal", user, "team", domain.ProjectRoleViewer},
+		{"manage_rules public", user, "open", domain.ProjectRoleViewer},
+		{"admin permission internal", adminPerm, "team", domain.ProjectRoleEditor},
+		{"non-member private", user, "client", ""},
+		{"member private", member, "client", domain.ProjectRoleViewer},
+		{"admin private", Principal{Role: "admin", Authenticated: true}, "client", domain.ProjectRoleOwner},
+		{"scoped key private", scoped, "client", domain.ProjectRoleViewer},
+		{"scoped key out of scope", scoped, "open", ""},
+		{"local", Principal{Local: true}, "client", domain.ProjectRoleOwner},
+	}
+	for _, tt := range tests {
+		project, _ := uc.projectRepo.GetByID(tt.projectID)
+		if got := uc.ProjectRole(tt.principal, project); got != tt.want {
+			t.Errorf("%s: ProjectRole() = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestAuthorizeHidesPrivateProjects(t *testing.T) {
+	uc, _ := newProjectAccessFixture()
+	member := Principal{UserID: 2, Role: "user", Authenticated: true}
+
+	if _, err := uc.Authorize(Principal{Role: "public"}, "client", domain.ProjectRoleViewer); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("anonymous error = %v, want not found", err)
+	}
+	if _, err := uc.Authorize(member, "client", domain.ProjectRoleViewer); err != nil {
+		t.Errorf("member viewer error = %v", err)
+	}
+	if _, err := uc.Authorize(member, "client", domain.ProjectRoleEditor); !errors.Is(err, apperr.ErrForbidden) {
+		t.Errorf("member editor error = %v, want forbidden", err)
+	}
+	// 既定の user ロール（manage_rules あり）でもメンバーでなければ編集できない
+	nonMember := Principal{UserID: 4, Role: "user", Authenticated: true, Permissions: map[string]bool{"manage_rules": true}}
+	for _, projectID := range []string{"open", "team"} {
+		if _, err := uc.Authorize(nonMember, projectID, domain.ProjectRoleEditor); !errors.Is(err, apperr.ErrForbidden) {
+			t.Errorf("non-member write to %s error = %v, want forbidden", projectID, err)
+		}
+	}
+	scoped := Principal{Role: "user", Authenticated: true, ProjectScope: []string{"client"}}
+	if _, err := uc.Authorize(scoped, "open", domain.ProjectRoleViewer); !errors.Is(err, apperr.ErrForbidden) {
+		t.Errorf("out of scope error = %v, want forbidden", err)
+	}
+
+	all, _ := uc.projectRepo.GetAll()
+	visible := uc.VisibleProjects(Principal{Role: "user", Authenticated: true}, all)
+	for _, p := range visible {
+		if p.ProjectID == "client" {
+			t.Errorf("VisibleProjects() includes private project %q", p.ProjectID)
+		}
+	}
+	if len(visible) != 2 {
+		t.Errorf("VisibleProjects() = %d projects, want 2", len(visible))
+	}
+}
+
+func TestSetMemberRequiresOwner(t *testing.T) {
+	uc, members := newProjectAccessFixture()
+	owner := Principal{UserID: 3, Role: "user", Authenticated: true}
+	viewer := Principal{UserID: 2, Role: "user", Authenticated: true}
+
+	if _, err := uc.SetMember(viewer, "client", 0, "bob", domain.ProjectRoleEditor); !errors.Is(err, apperr.ErrForbidden) {
+		t.Errorf("viewer SetMember error = %v, want forbidden", err)
+	}
+	if _, err := uc.SetMember(owner, "client", 0, "bob", "admin"); !errors.Is(err, apperr.ErrValidation) {
+		t.Errorf("unknown role error = %v, want validation", err)
+	}
+	m, err := uc.SetMember(owner, "client", 0, "bob", domain.ProjectRoleEditor)
+	if err != nil || m.UserID != 4 {
+		t.Fatalf("SetMember() = %+v, %v", m, err)
+	}
+	if _, err := uc.Authorize(Principal{UserID: 4, Role: "user", Authenticated: true}, "client", domain.ProjectRoleEditor); err != nil {
+		t.Errorf("new editor error = %v", err)
+	}
+
+	if err := uc.RemoveMember(owner, "client", 4); err != nil {
+		t.Fatal(err)
+	}
+	if len(members.members) != 2 {
+		t.Errorf("members after remove = %d, want 2", len(members.members))
+	}
+}
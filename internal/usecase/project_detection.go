@@ -30,6 +30,8 @@ type DetectionResult struct {
 	DetectionMethod string          `json:"detection_method"`
 	Confidence      float64         `json:"confidence"`
 	Message         string          `json:"message"`
+	// DetectedLanguages 言語固有ファイルから検出した全言語（サブディレクトリで検出した言語はそのパスglob付き）
+	DetectedLanguages []domain.ProjectLanguage `json:"detected_languages,omitempty"`
 }
 
 // AutoDetectProject プロジェクトを自動検出
@@ -59,14 +61,19 @@ func (pd *ProjectDetector) AutoDetectProject(path string) (*DetectionResult, err
 	}
 
 	// 3. 言語固有ファイルから検索
-	if project, err := pd.detectFromLanguageFiles(path); err == nil {
+	if project, languages, err := pd.detectFromLanguageFiles(path); err == nil {
 		rules, _ := pd.ruleRepo.GetByProjectID(project.ProjectID)
+		codes := make([]string, 0, len(languages))
+		for _, l := range languages {
+			codes = append(codes, l.Language)
+		}
 		return &DetectionResult{
-			Project:         project,
-			Rules:           rules,
-			DetectionMethod: "language_files",
-			Confidence:      0.85,
-			Message:         fmt.Sprintf("言語固有ファイルからプロジェクトを検出しました"),
+			Project:           project,
+			Rules:             rules,
+			DetectionMethod:   "language_files",
+			Confidence:        0.85,
+			Message:           fmt.Sprintf("言語固有ファイル（%s）からプロジェクトを検出しました", strings.Join(codes, ", ")),
+			DetectedLanguages: languages,
 		}, nil
 	}
 
@@ -90,11 +97,8 @@ func (pd *ProjectDetector) detectFromDirectoryName(path string) (*domain.Project
 	dirName := filepath.Base(path)
 
 	// 一般的な除外ディレクトリ
-	excludeDirs := []string{"node_modules", "vendor", "dist", "build", "target", ".git", ".vscode"}
-	for _, exclude := range excludeDirs {
-		if dirName == exclude {
-			return nil, fmt.Errorf("除外ディレクトリ: %s", dirName)
-		}
+	if isExcludedDir(dirName) {
+		return nil, fmt.Errorf("除外ディレクトリ: %s", dirName)
 	}
 
 	// プロジェクトIDとしてディレクトリ名を検索
@@ -170,32 +174,104 @@ func (pd *ProjectDetector) extractRepoNameFromURL(url string) string {
 	return ""
 }
 
+// languageMarkers 言語固有ファイル（判定する順）
+var languageMarkers = []struct {
+	language string
+	filename string
+}{
+	{"go", "go.mod"},
+	{"typescript", "tsconfig.json"},
+	{"javascript", "package.json"},
+	{"python", "requirements.txt"},
+	{"python", "pyproject.toml"},
+	{"java", "pom.xml"},
+	{"rust", "Cargo.toml"},
+	{"php", "composer.json"},
+	{"ruby", "Gemfile"},
+}
+
 // detectFromLanguageFiles 言語固有ファイルからプロジェクトを検出
-func (pd *ProjectDetector) detectFromLanguageFiles(path string) (*domain.Project, error) {
-	// 言語別の設定ファイル
-	languageFiles := map[string]string{
-		"go":     "go.mod",
-		"node":   "package.json",
-		"python": "requirements.txt",
-		"java":   "pom.xml",
-		"rust":   "Cargo.toml",
-		"php":    "composer.json",
-		"ruby":   "Gemfile",
-	}
-
-	for language, filename := range languageFiles {
-		filePath := filepath.Join(path, filename)
-		if _, err := os.Stat(filePath); err == nil {
-			// 言語に基づいてプロジェクトを検索
-			projects, err := pd.projectRepo.GetByLanguage(language)
-			if err == nil && len(projects) > 0 {
-				// 最初に見つかったプロジェクトを返す
-				return projects[0], nil
+// 検出した言語を最も多く扱うプロジェクトを返す（同数の場合は先に検出した言語のプロジェクト）
+func (pd *ProjectDetector) detectFromLanguageFiles(path string) (*domain.Project, []domain.ProjectLanguage, error) {
+	languages := detectLanguages(path)
+
+	var best *domain.Project
+	bestScore := 0
+	for _, l := range languages {
+		projects, err := pd.projectRepo.GetByLanguage(l.Language)
+		if err != nil {
+			continue
+		}
+		for _, project := range projects {
+			score := 0
+			for _, detected := range languages {
+				if project.HasLanguage(detected.Language) {
+					score++
+				}
+			}
+			if score > bestScore {
+				best, bestScore = project, score
+			}
+		}
+	}
+	if best == nil {
+		return nil, languages, fmt.Errorf("言語固有ファイルからプロジェクトを特定できませんでした")
+	}
+	return best, languages, nil
+}
+
+// detectLanguages ディレクトリ直下と1階層下のサブディレクトリから言語固有ファイルを探す
+// 直下で検出した言語はリポジトリ全体、サブディレクトリのみで検出した言語は "<dir>/**" を対象とする
+func detectLanguages(path string) []domain.ProjectLanguage {
+	dirs := []string{""}
+	if entries, err := os.ReadDir(path); err == nil {
+		for _, entry := range entries {
+			if entry.IsDir() && !strings.HasPrefix(entry.Name(), ".") && !isExcludedDir(entry.Name()) {
+				dirs = append(dirs, entry.Name())
 			}
 		}
 	}
 
-	return nil, fmt.Errorf("言語固有ファイルからプロジェクトを特定できませんでした")
+	var languages []domain.ProjectLanguage
+	index := map[string]int{}
+	for _, dir := range dirs {
+		found := map[string]bool{}
+		for _, marker := range languageMarkers {
+			if found[marker.language] {
+				continue
+			}
+			// TypeScriptのプロジェクトの package.json はJavaScriptとして扱わない
+			if marker.language == "javascript" && found["typescript"] {
+				continue
+			}
+			if _, err := os.Stat(filepath.Join(path, dir, marker.filename)); err != nil {
+				continue
+			}
+			found[marker.language] = true
+
+			i, ok := index[marker.language]
+			switch {
+			case !ok && dir == "":
+				index[marker.language] = len(languages)
+				languages = append(languages, domain.ProjectLanguage{Language: marker.language})
+			case !ok:
+				index[marker.language] = len(languages)
+				languages = append(languages, domain.ProjectLanguage{Language: marker.language, Paths: []string{dir + "/**"}})
+			case len(languages[i].Paths) > 0:
+				languages[i].Paths = append(languages[i].Paths, dir+"/**")
+			}
+		}
+	}
+	return languages
+}
+
+// isExcludedDir 言語の検出やスキャンの対象外とするディレクトリ
+func isExcludedDir(name string) bool {
+	switch name {
+	case "node_modules", "vendor", "dist", "build", "target", ".git", ".vscode":
+		return true
+	}
+	return false
 }
 
 // getDefaultProject デフォルトプロジェクトを取得
@@ -224,12 +300,8 @@ func (pd *ProjectDetector) ScanLocalProjects(basePath string) ([]DetectionResult
 		}
 
 		// 除外ディレクトリをスキップ
-		excludeDirs := []string{".git", "node_modules", "vendor", "dist", "build", "target", ".vscode"}
-		dirName := filepath.Base(path)
-		for _, exclude := range excludeDirs {
-			if dirName == exclude {
-				return filepath.SkipDir
-			}
+		if isExcludedDir(filepath.Base(path)) {
+			return filepath.SkipDir
 		}
 
 		// プロジェクトを検出
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+func TestProjectDetector_RecordsAllDetectedLanguages(t *testing.T) {
+	root := t.TempDir()
+	for _, name := range []string{
+		"go.mod",
+		"frontend/package.json",
+		"frontend/tsconfig.json",
+		"admin/package.json",
+		"node_modules/left-pad/package.json",
+	} {
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte("{}"), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projects := &memProjectRepo{projects: map[string]*domain.Project{
+		"api": {ProjectID: "api", Language: "go"},
+		"mono": {ProjectID: "mono", Language: "go", Languages: []domain.ProjectLanguage{
+			{Language: "typescript", Paths: []string{"frontend/**"}},
+		}},
+	}}
+	detector := NewProjectDetector(projects, &memRuleRepo{})
+
+	result, err := detector.AutoDetectProject(root)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.DetectionMethod != "language_files" || result.Project.ProjectID != "mono" {
+		t.Fatalf("detected %s via %s, want mono via language_files", result.Project.ProjectID, result.DetectionMethod)
+	}
+	// 直下の go.mod はリポジトリ全体、サブディレクトリの言語はパスglob付き（node_modules は対象外）
+	want := []domain.ProjectLanguage{
+		{Language: "go"},
+		{Language: "javascript", Paths: []string{"admin/**"}},
+		{Language: "typescript", Paths: []string{"frontend/**"}},
+	}
+	if !reflect.DeepEqual(result.DetectedLanguages, want) {
+		t.Errorf("detected languages = %+v, want %+v", result.DetectedLanguages, want)
+	}
+}
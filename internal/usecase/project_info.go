@@ -0,0 +1,85 @@
+package usecase
+
+import (
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+// recentViolationWindow プロジェクト概要で集計する違反の期間
+const recentViolationWindow = 7 * 24 * time.Hour
+
+// GetProjectInfo プロジェクトの概要（言語のメタデータ、ルールと直近の違反の件数）を取得
+func (uc *RuleUseCase) GetProjectInfo(projectID string) (*domain.ProjectInfo, error) {
+	project, err := uc.projectRepo.GetByID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	info := &domain.ProjectInfo{
+		Project:        project,
+		ProjectRules:   newRuleStats(),
+		InheritedRules: newRuleStats(),
+	}
+
+	if uc.languageRepo != nil && project.Language != "" {
+		// 言語マスタに無い言語（general など）はメタデータなしで返す
+		if language, err := uc.languageRepo.GetByCode(project.Language); err == nil {
+			info.Language = language
+		}
+	}
+
+	rules, err := uc.ruleRepo.GetByProjectID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range rules {
+		if r.IsActive {
+			countRule(&info.ProjectRules, r.Severity, r.Type)
+			info.LastRuleUpdate = latest(info.LastRuleUpdate, r.UpdatedAt)
+		}
+	}
+
+	// 継承ルールはプロジェクトの上書き（無効化・重要度の変更）を反映して数える
+	inherited, _, err := uc.inheritedRules(project)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range inherited {
+		if r.IsActive {
+			countRule(&info.InheritedRules, r.Severity, r.Type)
+			info.LastRuleUpdate = latest(info.LastRuleUpdate, r.UpdatedAt)
+		}
+	}
+
+	if uc.violationRepo != nil {
+		since := time.Now().Add(-recentViolationWindow)
+		counts, err := uc.violationRepo.CountBySeverity(projectID, since)
+		if err != nil {
+			return nil, err
+		}
+		stats := &domain.ViolationStats{Since: since, BySeverity: counts}
+		for _, n := range counts {
+			stats.Total += n
+		}
+		info.RecentViolations = stats
+	}
+	return info, nil
+}
+
+func newRuleStats() domain.RuleStats {
+	return domain.RuleStats{BySeverity: map[string]int{}, ByType: map[string]int{}}
+}
+
+func countRule(stats *domain.RuleStats, severity, ruleType string) {
+	stats.Total++
+	stats.BySeverity[severity]++
+	stats.ByType[ruleType]++
+}
+
+// latest 新しい方の日時を返す（nilは無視する）
+func latest(current, t *time.Time) *time.Time {
+	if t == nil || (current != nil && !t.After(*current)) {
+		return current
+	}
+	return t
+}
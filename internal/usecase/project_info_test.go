@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+type memLanguageRepo struct{ languages map[string]*domain.Language }
+
+func (r *memLanguageRepo) Create(l *domain.Language) error { r.languages[l.Code] = l; return nil }
+func (r *memLanguageRepo) GetByCode(code string) (*domain.Language, error) {
+	if l, ok := r.languages[code]; ok {
+		return l, nil
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+func (r *memLanguageRepo) GetAll() ([]*domain.Language, error) { return nil, nil }
+func (r *memLanguageRepo) Update(l *domain.Language) error     { r.languages[l.Code] = l; return nil }
+func (r *memLanguageRepo) Delete(code string) error            { delete(r.languages, code); return nil }
+
+type memViolationRepo struct{ severities map[string][]string }
+
+func (r *memViolationRepo) Record(projectID, filePath string, issues []domain.ValidationIssue) error {
+	for _, issue := range issues {
+		r.severities[projectID] = append(r.severities[projectID], issue.Severity)
+	}
+	return nil
+}
+func (r *memViolationRepo) CountBySeverity(projectID string, since time.Time) (map[string]int, error) {
+	counts := map[string]int{}
+	for _, severity := range r.severities[projectID] {
+		counts[severity]++
+	}
+	return counts, nil
+}
+
+func TestRuleUseCase_GetProjectInfo(t *testing.T) {
+	older := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	newer := older.Add(24 * time.Hour)
+	projects := &memProjectRepo{projects: map[string]*domain.Project{
+		"web-app": {ProjectID: "web-app", Name: "Web Application", Language: "javascript", ApplyGlobalRules: true},
+	}}
+	rules := &memRuleRepo{rules: []*domain.Rule{
+		{ProjectID: "web-app", RuleID: "no-console", Type: "style", Severity: "warning", Pattern: "console", IsActive: true, UpdatedAt: &older},
+		{ProjectID: "web-app", RuleID: "no-eval", Type: "security", Severity: "error", Pattern: "eval", IsActive: true},
+		{ProjectID: "web-app", RuleID: "disabled", Type: "style", Severity: "error", Pattern: "x", IsActive: false, UpdatedAt: &newer},
+	}}
+	globalRules := &memGlobalRuleRepo{rules: []*domain.GlobalRule{
+		{Language: "javascript", RuleID: "no-var", Type: "style", Severity: "warning", Pattern: `\bvar\b`, IsActive: true, UpdatedAt: &newer},
+	}}
+	violations := &memViolationRepo{severities: map[string][]string{}}
+
+	uc := NewRuleUseCase(rules, globalRules, projects)
+	uc.SetLanguageRepository(&memLanguageRepo{languages: map[string]*domain.Language{
+		"javascript": {Code: "javascript", Name: "JavaScript"},
+	}})
+	uc.SetViolationRepository(violations)
+
+	// 検証で検出した違反が記録される
+	if _, err := uc.ValidateCode("web-app", "eval(x); console.log(y)"); err != nil {
+		t.Fatal(err)
+	}
+	uc.violations.Flush()
+
+	info, err := uc.GetProjectInfo("web-app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Project.ProjectID != "web-app" || info.Language == nil || info.Language.Name != "JavaScript" {
+		t.Errorf("project/language = %+v / %+v", info.Project, info.Language)
+	}
+	if got := info.ProjectRules; got.Total != 2 || got.BySeverity["error"] != 1 || got.ByType["style"] != 1 {
+		t.Errorf("project rules = %+v", got)
+	}
+	if got := info.InheritedRules; got.Total != 1 || got.BySeverity["warning"] != 1 {
+		t.Errorf("inherited rules = %+v", got)
+	}
+	if info.LastRuleUpdate == nil || !info.LastRuleUpdate.Equal(newer) {
+		t.Errorf("last rule update = %v, want %v", info.LastRuleUpdate, newer)
+	}
+	if v := info.RecentViolations; v == nil || v.Total != 2 || v.BySeverity["error"] != 1 || v.BySeverity["warning"] != 1 {
+		t.Errorf("recent violations = %+v", v)
+	}
+
+	if _, err := uc.GetProjectInfo("missing"); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("missing project: err = %v", err)
+	}
+}
+
+func TestRuleUseCase_GetProjectInfoWithoutOptionalRepositories(t *testing.T) {
+	info, err := newTestRuleUseCase().GetProjectInfo("web-app")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.Language != nil || info.RecentViolations != nil || info.LastRuleUpdate != nil {
+		t.Errorf("info = %+v, want no optional sections", info)
+	}
+}
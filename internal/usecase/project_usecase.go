@@ -1,6 +1,7 @@
 package usecase
 
 import (
+	"reflect"
 	"time"
 
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
@@ -9,6 +10,8 @@ import (
 
 type ProjectUseCase struct {
 	projectRepo domain.ProjectRepository
+	engine      *RuleEngine
+	events      *RuleEvents
 }
 
 func NewProjectUseCase(projectRepo domain.ProjectRepository) *ProjectUseCase {
@@ -17,17 +20,49 @@ func NewProjectUseCase(projectRepo domain.ProjectRepository) *ProjectUseCase {
 	}
 }
 
-func (uc *ProjectUseCase) CreateProject(projectID, name, description, language string, applyGlobalRules bool) error {
+// SetRuleEngine プロジェクトの更新・削除時にキャッシュを破棄するルールエンジンを注入
+func (uc *ProjectUseCase) SetRuleEngine(engine *RuleEngine) {
+	uc.engine = engine
+}
+
+// projectChanged 解決済みのルールセットはプロジェクトの設定ごとキャッシュされるため破棄する
+func (uc *ProjectUseCase) projectChanged(projectID string) {
+	if uc.engine != nil {
+		uc.engine.Invalidate(projectID)
+	}
+}
+
+// SetRuleEvents ルール変更の配信先を注入（言語・グローバルルール適用設定の変更を通知する）
+func (uc *ProjectUseCase) SetRuleEvents(events *RuleEvents) {
+	uc.events = events
+}
+
+// CreateProject プロジェクトを作成（accessLevel が空なら public）
+// languages は複数言語のプロジェクトで扱う言語とパスglob（language が空なら先頭を主言語とする）
+func (uc *ProjectUseCase) CreateProject(projectID, name, description, language string, languages []domain.ProjectLanguage, applyGlobalRules bool, accessLevel, createdBy string) error {
 	if projectID == "" || name == "" {
 		return apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"project_id", "name"}})
 	}
+	if accessLevel == "" {
+		accessLevel = domain.ProjectAccessPublic
+	}
+	if err := validateProjectAccessLevel(accessLevel); err != nil {
+		return err
+	}
+	language, languages, err := normalizeProjectLanguages(language, languages)
+	if err != nil {
+		return err
+	}
 
 	project := &domain.Project{
 		ProjectID:        projectID,
 		Name:             name,
 		Description:      description,
 		Language:         language,
+		Languages:        languages,
 		ApplyGlobalRules: applyGlobalRules,
+		AccessLevel:      accessLevel,
+		CreatedBy:        createdBy,
 		CreatedAt:        time.Now(),
 		UpdatedAt:        time.Now(),
 	}
@@ -43,21 +78,56 @@ func (uc *ProjectUseCase) GetByID(projectID string) (*domain.Project, error) {
 	return uc.projectRepo.GetByID(projectID)
 }
 
-func (uc *ProjectUseCase) UpdateProject(projectID, name, description, language string, applyGlobalRules bool) error {
+// UpdateProject プロジェクトを更新（accessLevel が空なら公開範囲、languages がnilなら言語の対応付けは変更しない）
+func (uc *ProjectUseCase) UpdateProject(projectID, name, description, language string, languages []domain.ProjectLanguage, applyGlobalRules bool, accessLevel string) error {
 	project, err := uc.projectRepo.GetByID(projectID)
 	if err != nil {
 		return err
 	}
+	if accessLevel != "" {
+		if err := validateProjectAccessLevel(accessLevel); err != nil {
+			return err
+		}
+		project.AccessLevel = accessLevel
+	}
+	if languages == nil {
+		languages = project.Languages
+	}
+	language, languages, err = normalizeProjectLanguages(language, languages)
+	if err != nil {
+		return err
+	}
 
+	// 言語や適用設定が変わると継承するグローバルルールが変わる
+	rulesChanged := project.Language != language || !reflect.DeepEqual(project.Languages, languages) || project.ApplyGlobalRules != applyGlobalRules
 	project.Name = name
 	project.Description = description
 	project.Language = language
+	project.Languages = languages
 	project.ApplyGlobalRules = applyGlobalRules
 	project.UpdatedAt = time.Now()
 
-	return uc.projectRepo.Update(project)
+	if err := uc.projectRepo.Update(project); err != nil {
+		return err
+	}
+	uc.projectChanged(projectID)
+	if rulesChanged {
+		uc.events.Publish(RuleChange{ProjectID: projectID})
+	}
+	return nil
 }
 
 func (uc *ProjectUseCase) DeleteProject(projectID string) error {
-	return uc.projectRepo.Delete(projectID)
+	if err := uc.projectRepo.Delete(projectID); err != nil {
+		return err
+	}
+	uc.projectChanged(projectID)
+	return nil
+}
+
+func validateProjectAccessLevel(level string) error {
+	if !domain.ValidProjectAccessLevel(level) {
+		return apperr.WrapWithDetails(apperr.ErrValidation, "access_level は public・internal・private のいずれかを指定してください", map[string]string{"access_level": level})
+	}
+	return nil
 }
@@ -0,0 +1,305 @@
+package usecase
+
+import (
+	"errors"
+	"hash/fnv"
+	"log"
+	"regexp"
+	"regexp/syntax"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// resolvedRuleSetTTL 解決済みルールセットをキャッシュする時間
+// 他のインスタンスやDBの直接更新による変更も、この時間が経てば反映される
+const resolvedRuleSetTTL = time.Minute
+
+// RuleEngine プロジェクトの有効ルールをコンパイル済みの状態でキャッシュする
+// キャッシュはプロジェクトIDとルール内容から算出したバージョンで管理され、
+// ルール変更時には Invalidate / InvalidateLanguage で破棄される
+// compileProject で解決したルールセットはプロジェクトごと保持し、破棄されるまで再取得しない
+type RuleEngine struct {
+	mu    sync.RWMutex
+	cache map[string]*CompiledRuleSet
+	// generation 破棄のたびに進める。解決中に破棄されたルールセットをキャッシュしないために使う
+	generation uint64
+}
+
+// CompiledRuleSet コンパイル済みのルールセット
+type CompiledRuleSet struct {
+	ProjectID string
+	Language  string
+	Version   uint64
+	rules     []compiledRule
+	invalid   []domain.Rule
+
+	// project ルールを解決したプロジェクト（Compile で作成した場合はnil）
+	project   *domain.Project
+	expiresAt time.Time
+}
+
+// NewRuleEngine ルールエンジンを作成
+func NewRuleEngine() *RuleEngine {
+	return &RuleEngine{cache: map[string]*CompiledRuleSet{}}
+}
+
+// Compile ルールセットを取得（キャッシュが古い場合のみ再コンパイル）
+func (e *RuleEngine) Compile(projectID, language string, rules []domain.Rule) *CompiledRuleSet {
+	version := rulesVersion(rules)
+
+	e.mu.RLock()
+	cached, ok := e.cache[projectID]
+	e.mu.RUnlock()
+	if ok && cached.Version == version && cached.Language == language {
+		return cached
+	}
+
+	set := compileRuleSet(projectID, language, version, rules)
+
+	e.mu.Lock()
+	e.cache[projectID] = set
+	e.mu.Unlock()
+	return set
+}
+
+// cached 解決済みのルールセットを取得（compileProject でキャッシュされ、期限内のもののみ）
+func (e *RuleEngine) cached(projectID string) (*CompiledRuleSet, bool) {
+	e.mu.RLock()
+	set, ok := e.cache[projectID]
+	e.mu.RUnlock()
+	if !ok || set.project == nil || time.Now().After(set.expiresAt) {
+		return nil, false
+	}
+	return set, true
+}
+
+// currentGeneration 現在の世代（ルールの解決を始める前に取得し、compileProject に渡す）
+func (e *RuleEngine) currentGeneration() uint64 {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.generation
+}
+
+// compileProject プロジェクトについて解決したルールをコンパイルし、プロジェクトと合わせてキャッシュする
+// generation 以降に破棄があった場合は、古いルールの可能性があるためキャッシュしない
+func (e *RuleEngine) compileProject(generation uint64, project *domain.Project, rules []domain.Rule) *CompiledRuleSet {
+	set := compileRuleSet(project.ProjectID, project.Language, rulesVersion(rules), rules)
+	set.project = project
+	set.expiresAt = time.Now().Add(resolvedRuleSetTTL)
+
+	e.mu.Lock()
+	if e.generation == generation {
+		e.cache[project.ProjectID] = set
+	}
+	e.mu.Unlock()
+	return set
+}
+
+func compileRuleSet(projectID, language string, version uint64, rules []domain.Rule) *CompiledRuleSet {
+	set := &CompiledRuleSet{ProjectID: projectID, Language: language, Version: version}
+	for _, rule := range rules {
+		if !rule.IsActive {
+			continue
+		}
+		cr, err := compileRule(rule)
+		if err != nil {
+			log.Printf("Warning: rule %s/%s cannot be compiled: %v", projectID, rule.RuleID, err)
+			set.invalid = append(set.invalid, rule)
+			continue
+		}
+		set.rules = append(set.rules, cr)
+	}
+	return set
+}
+
+// Invalidate プロジェクトのキャッシュを破棄
+func (e *RuleEngine) Invalidate(projectID string) {
+	e.mu.Lock()
+	e.generation++
+	delete(e.cache, projectID)
+	e.mu.Unlock()
+}
+
+// InvalidateLanguage 指定言語のグローバルルールを継承するキャッシュを破棄
+// 主言語が異なっても、指定言語を扱うプロジェクトや指定言語のルールを含むキャッシュは破棄する
+func (e *RuleEngine) InvalidateLanguage(language string) {
+	e.mu.Lock()
+	e.generation++
+	for projectID, set := range e.cache {
+		if set.Language == language || set.hasLanguage(language) || (set.project != nil && set.project.HasLanguage(language)) {
+			delete(e.cache, projectID)
+		}
+	}
+	e.mu.Unlock()
+}
+
+// hasLanguage 指定言語を対象とするルールを含むか判定
+func (s *CompiledRuleSet) hasLanguage(language string) bool {
+	for _, cr := range s.rules {
+		if cr.rule.Language == language {
+			return true
+		}
+	}
+	for _, rule := range s.invalid {
+		if rule.Language == language {
+			return true
+		}
+	}
+	return false
+}
+
+// InvalidRules コンパイルできなかったルール
+func (s *CompiledRuleSet) InvalidRules() []domain.Rule {
+	return s.invalid
+}
+
+// Validate コードをルールセットで検証（filenameはfile_glob付きルールの適用判定と言語の推定に使用）
+func (s *CompiledRuleSet) Validate(filename, code string) *domain.ValidationResult {
+	return s.ValidateLanguage(filename, languageForPath(filename), code)
+}
+
+// ValidateLanguage 言語を指定してコードを検証（対象言語の異なるルールは適用しない）
+func (s *CompiledRuleSet) ValidateLanguage(filename, language, code string) *domain.ValidationResult {
+	return s.validate(filename, language, code, nil)
+}
+
+// validate コードを検証。scope指定時は変更行に掛かる問題のみを元ファイルの行番号で報告する
+func (s *CompiledRuleSet) validate(filename, language, code string, scope *lineScope) *domain.ValidationResult {
+	result := &domain.ValidationResult{
+		Valid:      true,
+		Errors:     []string{},
+		Warnings:   []string{},
+		Issues:     []domain.ValidationIssue{},
+		Suppressed: []domain.SuppressedIssue{},
+	}
+
+	src := newSourceFile(code)
+	suppressions := parseSuppressions(src.li)
+	for _, cr := range s.rules {
+		if !cr.appliesTo(filename, language) {
+			continue
+		}
+		// ファイル全体を前提とするマッチャーは断片には適用しない
+		if scope != nil && cr.fileLevel() {
+			continue
+		}
+		reported := 0
+		for _, issue := range cr.findIssues(src) {
+			if scope != nil && !scope.covers(issue) {
+				continue
+			}
+			if suppressed, ok := suppress(suppressions, issue); ok {
+				if scope != nil {
+					scope.remapSuppressed(&suppressed)
+				}
+				result.Suppressed = append(result.Suppressed, suppressed)
+				continue
+			}
+			if scope != nil {
+				scope.remap(&issue)
+			}
+			result.Issues = append(result.Issues, issue)
+			reported++
+		}
+		if reported == 0 {
+			continue
+		}
+
+		msg := ruleMessage(cr.rule)
+		if cr.rule.Severity == "error" {
+			result.Errors = append(result.Errors, msg)
+			result.Valid = false
+		} else if cr.rule.Severity == "warning" {
+			result.Warnings = append(result.Warnings, msg)
+		}
+	}
+
+	return result
+}
+
+// ValidatePattern ルールのパターンがコンパイル可能か検証
+func ValidatePattern(pattern string) error {
+	if details := patternErrorDetails(pattern); details != nil {
+		return apperr.WrapWithDetails(apperr.ErrValidation, "正規表現パターンが不正です", details)
+	}
+	return nil
+}
+
+// ValidateRules 複数ルールのパターン・マッチャー設定をまとめて検証（キーはルールID）
+func ValidateRules(rules map[string]domain.Rule) error {
+	ruleIDs := make([]string, 0, len(rules))
+	for ruleID := range rules {
+		ruleIDs = append(ruleIDs, ruleID)
+	}
+	sort.Strings(ruleIDs)
+
+	var invalid []map[string]interface{}
+	for _, ruleID := range ruleIDs {
+		rule := rules[ruleID]
+		if details := ruleErrorDetails(rule); details != nil {
+			details["rule_id"] = ruleID
+			invalid = append(invalid, details)
+		}
+	}
+	if len(invalid) == 0 {
+		return nil
+	}
+	return apperr.WrapWithDetails(apperr.ErrValidation, "不正な正規表現パターンが含まれています", map[string]interface{}{"invalid_patterns": invalid})
+}
+
+// patternErrorDetails コンパイルエラーの詳細（エラー内容・位置）を返す。正常ならnil
+func patternErrorDetails(pattern string) map[string]interface{} {
+	_, err := regexp.Compile(pattern)
+	if err == nil {
+		return nil
+	}
+	details := map[string]interface{}{"pattern": pattern, "error": err.Error(), "offset": 0}
+	var syntaxErr *syntax.Error
+	if errors.As(err, &syntaxErr) {
+		details["code"] = string(syntaxErr.Code)
+		if offset := strings.Index(pattern, syntaxErr.Expr); offset >= 0 {
+			details["offset"] = offset
+		}
+	}
+	return details
+}
+
+// rulesVersion ルール内容からキャッシュ用のバージョンを算出
+func rulesVersion(rules []domain.Rule) uint64 {
+	h := fnv.New64a()
+	for _, r := range rules {
+		h.Write([]byte(r.RuleID))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Language))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Name))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Severity))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Pattern))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Message))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Replacement))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Description))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.FormatBool(r.IsActive)))
+		h.Write([]byte{0})
+		h.Write([]byte(r.MatcherKind))
+		h.Write([]byte{0})
+		h.Write([]byte(r.Flags))
+		h.Write([]byte{0})
+		h.Write([]byte(r.FileGlob))
+		h.Write([]byte{0})
+		h.Write([]byte(strconv.Itoa(r.Limit)))
+		h.Write([]byte{1})
+	}
+	return h.Sum64()
+}
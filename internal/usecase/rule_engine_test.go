@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+func TestRuleEngine_CompileIsCachedUntilRulesChange(t *testing.T) {
+	engine := NewRuleEngine()
+	rules := []domain.Rule{{RuleID: "no-debugger", Severity: "error", Pattern: `debugger`, IsActive: true}}
+
+	first := engine.Compile("web-app", "javascript", rules)
+	if second := engine.Compile("web-app", "javascript", rules); second != first {
+		t.Errorf("Expected cached rule set to be reused")
+	}
+
+	rules[0].Pattern = `debugger;`
+	if changed := engine.Compile("web-app", "javascript", rules); changed == first {
+		t.Errorf("Expected rule set to be recompiled after pattern change")
+	}
+}
+
+func TestRuleEngine_InvalidateLanguage(t *testing.T) {
+	engine := NewRuleEngine()
+	rules := []domain.Rule{{RuleID: "no-print", Severity: "warning", Pattern: `print\(`, IsActive: true}}
+
+	js := engine.Compile("web-app", "javascript", rules)
+	py := engine.Compile("ml-service", "python", rules)
+	engine.InvalidateLanguage("python")
+
+	if engine.Compile("web-app", "javascript", rules) != js {
+		t.Errorf("Expected javascript project cache to survive python invalidation")
+	}
+	if engine.Compile("ml-service", "python", rules) == py {
+		t.Errorf("Expected python project cache to be dropped")
+	}
+}
+
+func TestRuleEngine_InvalidPatternIsReportedNotMatched(t *testing.T) {
+	engine := NewRuleEngine()
+	set := engine.Compile("web-app", "javascript", []domain.Rule{
+		{RuleID: "broken", Severity: "error", Pattern: `(unclosed`, IsActive: true},
+	})
+
+	if len(set.InvalidRules()) != 1 {
+		t.Errorf("Expected broken rule to be reported as invalid")
+	}
+	if result := set.Validate("", "(unclosed"); !result.Valid || len(result.Issues) != 0 {
+		t.Errorf("Expected broken rule to be skipped during validation, got %+v", result)
+	}
+}
+
+func TestValidatePattern_ReportsCompileErrorAndOffset(t *testing.T) {
+	if err := ValidatePattern(`console\.log`); err != nil {
+		t.Fatalf("Expected valid pattern, got %v", err)
+	}
+
+	err := ValidatePattern(`foo[a-`)
+	if !errors.Is(err, apperr.ErrValidation) {
+		t.Fatalf("Expected validation error, got %v", err)
+	}
+	var wd *apperr.WithDetails
+	if !errors.As(err, &wd) {
+		t.Fatalf("Expected error with details")
+	}
+	details := wd.Details.(map[string]interface{})
+	if details["offset"] != 3 {
+		t.Errorf("Expected offset 3, got %v", details["offset"])
+	}
+	if details["error"] == "" {
+		t.Errorf("Expected compile error message in details")
+	}
+}
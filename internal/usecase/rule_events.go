@@ -0,0 +1,49 @@
+package usecase
+
+import "sync"
+
+// RuleChange ルール変更イベント
+type RuleChange struct {
+	ProjectID string // プロジェクトのルール・適用設定が変わった場合のプロジェクトID
+	Language  string // 言語別グローバルルールが変わった場合の言語
+}
+
+// RuleEvents ルール変更を購読者へ配信する
+// 購読者は同期的に呼び出されるため、時間の掛かる処理は購読者側で非同期に行う
+type RuleEvents struct {
+	mu          sync.RWMutex
+	subscribers map[int]func(RuleChange)
+	nextID      int
+}
+
+// NewRuleEvents ルール変更イベントの配信元を作成
+func NewRuleEvents() *RuleEvents {
+	return &RuleEvents{subscribers: map[int]func(RuleChange){}}
+}
+
+// Subscribe 購読を登録し、解除する関数を返す
+func (e *RuleEvents) Subscribe(fn func(RuleChange)) func() {
+	e.mu.Lock()
+	id := e.nextID
+	e.nextID++
+	e.subscribers[id] = fn
+	e.mu.Unlock()
+
+	return func() {
+		e.mu.Lock()
+		delete(e.subscribers, id)
+		e.mu.Unlock()
+	}
+}
+
+// Publish 変更を配信（nilの場合は何もしない）
+func (e *RuleEvents) Publish(change RuleChange) {
+	if e == nil {
+		return
+	}
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	for _, fn := range e.subscribers {
+		fn(change)
+	}
+}
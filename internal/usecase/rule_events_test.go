@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+func TestRuleEvents_PublishedOnRuleChanges(t *testing.T) {
+	projects := &memProjectRepo{projects: map[string]*domain.Project{
+		"web": {ProjectID: "web", Name: "web", Language: "go", ApplyGlobalRules: true},
+	}}
+	events := NewRuleEvents()
+	var got []RuleChange
+	unsubscribe := events.Subscribe(func(c RuleChange) { got = append(got, c) })
+
+	ruleUseCase := NewRuleUseCase(&memRuleRepo{}, &memGlobalRuleRepo{}, projects)
+	ruleUseCase.SetRuleEvents(events)
+	globalRuleUseCase := NewGlobalRuleUseCase(&memGlobalRuleRepo{})
+	globalRuleUseCase.SetRuleEvents(events)
+	projectUseCase := NewProjectUseCase(projects)
+	projectUseCase.SetRuleEvents(events)
+
+	if err := ruleUseCase.CreateRule("web", "r1", "R1", "", "style", "warning", "x", "m", "", domain.RuleMatcher{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := globalRuleUseCase.CreateGlobalRule("go", "g1", "G1", "", "style", "warning", "x", "m", "", domain.RuleMatcher{}); err != nil {
+		t.Fatal(err)
+	}
+	// 名前だけの変更は適用ルールに影響しないため通知しない
+	if err := projectUseCase.UpdateProject("web", "renamed", "", "go", nil, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if err := projectUseCase.UpdateProject("web", "renamed", "", "go", nil, false, ""); err != nil {
+		t.Fatal(err)
+	}
+
+	want := []RuleChange{{ProjectID: "web"}, {Language: "go"}, {ProjectID: "web"}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("changes = %+v, want %+v", got, want)
+	}
+
+	unsubscribe()
+	_ = ruleUseCase.DeleteRule("web", "r1")
+	if len(got) != len(want) {
+		t.Errorf("received %d changes after unsubscribe", len(got)-len(want))
+	}
+}
+
+func TestRuleUseCase_ProjectsUsingGlobalRules(t *testing.T) {
+	projects := &memProjectRepo{projects: map[string]*domain.Project{
+		"a": {ProjectID: "a", Language: "go", ApplyGlobalRules: true},
+		"b": {ProjectID: "b", Language: "go", ApplyGlobalRules: false},
+		"c": {ProjectID: "c", Language: "python", ApplyGlobalRules: true},
+	}}
+	uc := NewRuleUseCase(&memRuleRepo{}, &memGlobalRuleRepo{}, projects)
+	ids, err := uc.ProjectsUsingGlobalRules("go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ids, []string{"a"}) {
+		t.Errorf("projects = %v, want [a]", ids)
+	}
+}
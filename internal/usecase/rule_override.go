@@ -0,0 +1,150 @@
+package usecase
+
+import (
+	"sort"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// SetGlobalRuleOverrideRepository プロジェクト単位のグローバルルール上書きの保存先を注入
+// 未設定の場合、グローバルルールは上書きせずに継承する
+func (uc *RuleUseCase) SetGlobalRuleOverrideRepository(repo domain.GlobalRuleOverrideRepository) {
+	uc.overrideRepo = repo
+}
+
+// ListGlobalRuleOverrides プロジェクトの上書き一覧（対象のグローバルルールが無効・削除済みのものも含む）
+func (uc *RuleUseCase) ListGlobalRuleOverrides(projectID string) ([]*domain.GlobalRuleOverride, error) {
+	if _, err := uc.projectRepo.GetByID(projectID); err != nil {
+		return nil, err
+	}
+	if uc.overrideRepo == nil {
+		return []*domain.GlobalRuleOverride{}, nil
+	}
+	return uc.overrideRepo.GetByProject(projectID)
+}
+
+// SetGlobalRuleOverride グローバルルールの上書きを登録（登録済みなら置き換える）
+// language を省略した場合はプロジェクトの言語を対象とする
+func (uc *RuleUseCase) SetGlobalRuleOverride(projectID string, override domain.GlobalRuleOverride) (*domain.GlobalRuleOverride, error) {
+	if uc.overrideRepo == nil {
+		return nil, apperr.Wrap(apperr.ErrInternal, "上書き設定の保存先がありません")
+	}
+	project, err := uc.projectRepo.GetByID(projectID)
+	if err != nil {
+		return nil, err
+	}
+	if override.Language == "" {
+		override.Language = project.Language
+	}
+	if override.RuleID == "" || override.Language == "" {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"language", "rule_id"}})
+	}
+	if !override.Disabled && override.Severity == "" && override.Message == "" {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "disabled・severity・message のいずれかを指定してください", map[string]interface{}{"rule_id": override.RuleID})
+	}
+	if override.Severity != "" {
+		if err := validateSeverity(override.Severity); err != nil {
+			return nil, err
+		}
+	}
+	if _, err := uc.globalRuleRepo.GetByID(override.Language, override.RuleID); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	override.ProjectID = projectID
+	override.CreatedAt = now
+	if existing, err := uc.overrideRepo.Get(projectID, override.Language, override.RuleID); err == nil {
+		override.CreatedAt = existing.CreatedAt
+	}
+	override.UpdatedAt = now
+	if err := uc.overrideRepo.Save(&override); err != nil {
+		return nil, err
+	}
+	uc.rulesChanged(projectID)
+	return &override, nil
+}
+
+// DeleteGlobalRuleOverride 上書きを削除し、グローバルルールをそのまま継承する状態に戻す
+func (uc *RuleUseCase) DeleteGlobalRuleOverride(projectID, language, ruleID string) error {
+	if uc.overrideRepo == nil {
+		return apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+	}
+	project, err := uc.projectRepo.GetByID(projectID)
+	if err != nil {
+		return err
+	}
+	if language == "" {
+		language = project.Language
+	}
+	if _, err := uc.overrideRepo.Get(projectID, language, ruleID); err != nil {
+		return err
+	}
+	if err := uc.overrideRepo.Delete(projectID, language, ruleID); err != nil {
+		return err
+	}
+	uc.rulesChanged(projectID)
+	return nil
+}
+
+// inheritedRules プロジェクトが継承するグローバルルールを上書きを適用して取得
+// 複数言語のプロジェクトでは全言語のルールを継承する（検証時はファイルの言語で絞り込む）
+// ルールID・言語の順に並べ、無効化したルールは除く
+func (uc *RuleUseCase) inheritedRules(project *domain.Project) ([]domain.Rule, []domain.AppliedOverride, error) {
+	if !project.ApplyGlobalRules {
+		return nil, nil, nil
+	}
+	var globalRules []*domain.GlobalRule
+	for _, language := range project.LanguageCodes() {
+		rules, err := uc.globalRuleRepo.GetByLanguage(language)
+		if err != nil {
+			return nil, nil, err
+		}
+		globalRules = append(globalRules, rules...)
+	}
+	overrides := map[string]*domain.GlobalRuleOverride{}
+	if uc.overrideRepo != nil {
+		list, err := uc.overrideRepo.GetByProject(project.ProjectID)
+		if err != nil {
+			return nil, nil, err
+		}
+		for _, o := range list {
+			overrides[o.Language+"/"+o.RuleID] = o
+		}
+	}
+
+	sort.SliceStable(globalRules, func(i, j int) bool {
+		if globalRules[i].RuleID != globalRules[j].RuleID {
+			return globalRules[i].RuleID < globalRules[j].RuleID
+		}
+		return globalRules[i].Language < globalRules[j].Language
+	})
+	rules := make([]domain.Rule, 0, len(globalRules))
+	var applied []domain.AppliedOverride
+	for _, g := range globalRules {
+		rule := g.AsRule(project.ProjectID)
+		o, ok := overrides[g.Language+"/"+g.RuleID]
+		if !ok {
+			rules = append(rules, rule)
+			continue
+		}
+		a := domain.AppliedOverride{RuleID: g.RuleID, Language: g.Language, Disabled: o.Disabled}
+		if o.Severity != "" && o.Severity != g.Severity {
+			a.Severity, a.OriginalSeverity = o.Severity, g.Severity
+			rule.Severity = o.Severity
+		}
+		if o.Message != "" && o.Message != g.Message {
+			a.Message, a.OriginalMessage = o.Message, g.Message
+			rule.Message = o.Message
+		}
+		if a.Disabled || a.Severity != "" || a.Message != "" {
+			applied = append(applied, a)
+		}
+		if !o.Disabled {
+			rules = append(rules, rule)
+		}
+	}
+	return rules, applied, nil
+}
@@ -0,0 +1,135 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// memOverrideRepo テスト用のグローバルルール上書きリポジトリ
+type memOverrideRepo struct{ overrides []*domain.GlobalRuleOverride }
+
+func (r *memOverrideRepo) GetByProject(projectID string) ([]*domain.GlobalRuleOverride, error) {
+	var out []*domain.GlobalRuleOverride
+	for _, o := range r.overrides {
+		if o.ProjectID == projectID {
+			out = append(out, o)
+		}
+	}
+	return out, nil
+}
+func (r *memOverrideRepo) Get(projectID, language, ruleID string) (*domain.GlobalRuleOverride, error) {
+	for _, o := range r.overrides {
+		if o.ProjectID == projectID && o.Language == language && o.RuleID == ruleID {
+			return o, nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+func (r *memOverrideRepo) Save(override *domain.GlobalRuleOverride) error {
+	_ = r.Delete(override.ProjectID, override.Language, override.RuleID)
+	r.overrides = append(r.overrides, override)
+	return nil
+}
+func (r *memOverrideRepo) Delete(projectID, language, ruleID string) error {
+	for i, o := range r.overrides {
+		if o.ProjectID == projectID && o.Language == language && o.RuleID == ruleID {
+			r.overrides = append(r.overrides[:i], r.overrides[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func newOverrideFixture(rules ...*domain.Rule) (*RuleUseCase, *memOverrideRepo) {
+	projects := &memProjectRepo{projects: map[string]*domain.Project{
+		"legacy": {ProjectID: "legacy", Language: "go", ApplyGlobalRules: true},
+	}}
+	globals := &memGlobalRuleRepo{rules: []*domain.GlobalRule{
+		{Language: "go", RuleID: "no-todo", Name: "No TODO", Severity: "error", Pattern: "TODO", Message: "todo", IsActive: true},
+		{Language: "go", RuleID: "no-panic", Name: "No Panic", Severity: "error", Pattern: `panic\(`, Message: "panic", IsActive: true},
+		{Language: "go", RuleID: "no-fixme", Name: "No FIXME", Severity: "warning", Pattern: "FIXME", Message: "fixme", IsActive: true},
+	}}
+	overrides := &memOverrideRepo{}
+	uc := NewRuleUseCase(&memRuleRepo{rules: rules}, globals, projects)
+	uc.SetGlobalRuleOverrideRepository(overrides)
+	return uc, overrides
+}
+
+func TestRuleUseCase_GlobalRuleOverridesApplied(t *testing.T) {
+	uc, _ := newOverrideFixture(&domain.Rule{ProjectID: "legacy", RuleID: "no-fixme", Name: "Own FIXME", Severity: "info", Pattern: "FIXME", IsActive: true})
+	if _, err := uc.SetGlobalRuleOverride("legacy", domain.GlobalRuleOverride{RuleID: "no-panic", Disabled: true}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := uc.SetGlobalRuleOverride("legacy", domain.GlobalRuleOverride{RuleID: "no-todo", Severity: "warning", Message: "track it in the issue tracker"}); err != nil {
+		t.Fatal(err)
+	}
+
+	rules, err := uc.GetProjectRules("legacy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// プロジェクトルールが先、同じルールIDのグローバルルールは継承しない、無効化したルールは除く
+	if len(rules.Rules) != 2 || rules.Rules[0].Name != "Own FIXME" || rules.Rules[1].RuleID != "no-todo" {
+		t.Fatalf("rules = %+v", rules.Rules)
+	}
+	if rules.Rules[1].Severity != "warning" || rules.Rules[1].Message != "track it in the issue tracker" {
+		t.Errorf("override not applied: %+v", rules.Rules[1])
+	}
+	want := []domain.AppliedOverride{
+		{RuleID: "no-panic", Language: "go", Disabled: true},
+		{RuleID: "no-todo", Language: "go", Severity: "warning", OriginalSeverity: "error", Message: "track it in the issue tracker", OriginalMessage: "todo"},
+	}
+	if len(rules.Overrides) != len(want) || rules.Overrides[0] != want[0] || rules.Overrides[1] != want[1] {
+		t.Errorf("overrides = %+v, want %+v", rules.Overrides, want)
+	}
+
+	result, err := uc.ValidateCode("legacy", "// TODO\npanic(err)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.Valid || len(result.Issues) != 1 || result.Issues[0].Severity != "warning" {
+		t.Errorf("validation = %+v, want only the softened TODO warning", result)
+	}
+
+	if err := uc.DeleteGlobalRuleOverride("legacy", "", "no-panic"); err != nil {
+		t.Fatal(err)
+	}
+	if result, _ := uc.ValidateCode("legacy", "panic(err)"); result.Valid {
+		t.Error("no-panic still disabled after deleting the override")
+	}
+}
+
+func TestRuleUseCase_SetGlobalRuleOverrideValidation(t *testing.T) {
+	uc, overrides := newOverrideFixture()
+	if _, err := uc.SetGlobalRuleOverride("legacy", domain.GlobalRuleOverride{RuleID: "no-todo"}); !errors.Is(err, apperr.ErrValidation) {
+		t.Errorf("empty override error = %v, want validation error", err)
+	}
+	// 重要度の誤りは保存せずに検証エラーとする（評価時に error・warning のどちらにも数えられなくなるため）
+	for _, severity := range []string{"critical", "Error"} {
+		_, err := uc.SetGlobalRuleOverride("legacy", domain.GlobalRuleOverride{RuleID: "no-todo", Severity: severity})
+		var wd *apperr.WithDetails
+		if !errors.As(err, &wd) || !errors.Is(err, apperr.ErrValidation) || wd.Details.(map[string]interface{})["severity"] != severity {
+			t.Errorf("severity %q error = %v, want validation error with details", severity, err)
+		}
+	}
+	if _, err := uc.SetGlobalRuleOverride("legacy", domain.GlobalRuleOverride{RuleID: "no-fixme", Severity: "info"}); err != nil {
+		t.Errorf("info severity rejected: %v", err)
+	} else {
+		_ = uc.DeleteGlobalRuleOverride("legacy", "go", "no-fixme")
+	}
+	if _, err := uc.SetGlobalRuleOverride("legacy", domain.GlobalRuleOverride{RuleID: "missing", Disabled: true}); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("unknown global rule error = %v, want not found", err)
+	}
+	if _, err := uc.SetGlobalRuleOverride("missing", domain.GlobalRuleOverride{RuleID: "no-todo", Disabled: true}); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("unknown project error = %v, want not found", err)
+	}
+	if err := uc.DeleteGlobalRuleOverride("legacy", "go", "no-todo"); !errors.Is(err, apperr.ErrNotFound) {
+		t.Errorf("delete missing override error = %v, want not found", err)
+	}
+	if len(overrides.overrides) != 0 {
+		t.Errorf("rejected overrides were stored: %+v", overrides.overrides)
+	}
+}
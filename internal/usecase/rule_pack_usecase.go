@@ -0,0 +1,236 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// rulePackName パック名として使える文字列（小文字英数字で始まり、英小文字・数字・. _ - のみ）
+var rulePackName = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,99}$`)
+
+type RulePackUseCase struct {
+	packRepo    domain.RulePackRepository
+	projectRepo domain.ProjectRepository
+	engine      *RuleEngine
+	events      *RuleEvents
+}
+
+func NewRulePackUseCase(packRepo domain.RulePackRepository, projectRepo domain.ProjectRepository) *RulePackUseCase {
+	return &RulePackUseCase{
+		packRepo:    packRepo,
+		projectRepo: projectRepo,
+	}
+}
+
+// SetRuleEngine パック変更時にキャッシュを破棄するルールエンジンを注入
+func (uc *RulePackUseCase) SetRuleEngine(engine *RuleEngine) {
+	uc.engine = engine
+}
+
+// SetRuleEvents ルール変更の配信先を注入
+func (uc *RulePackUseCase) SetRuleEvents(events *RuleEvents) {
+	uc.events = events
+}
+
+// projectChanged プロジェクトのコンパイル済みルールを破棄し、変更を配信
+func (uc *RulePackUseCase) projectChanged(projectID string) {
+	if uc.engine != nil {
+		uc.engine.Invalidate(projectID)
+	}
+	uc.events.Publish(RuleChange{ProjectID: projectID})
+}
+
+// packChanged パックを適用している全プロジェクトに変更を反映
+func (uc *RulePackUseCase) packChanged(name string) error {
+	projectIDs, err := uc.packRepo.GetProjects(name)
+	if err != nil {
+		return err
+	}
+	for _, projectID := range projectIDs {
+		uc.projectChanged(projectID)
+	}
+	return nil
+}
+
+func (uc *RulePackUseCase) GetRulePacks() ([]*domain.RulePack, error) {
+	return uc.packRepo.GetAll()
+}
+
+func (uc *RulePackUseCase) GetRulePack(name string) (*domain.RulePack, error) {
+	return uc.packRepo.GetByName(name)
+}
+
+func (uc *RulePackUseCase) CreateRulePack(name, description string, rules []domain.Rule) (*domain.RulePack, error) {
+	if !rulePackName.MatchString(name) {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "パック名が不正です", map[string]interface{}{"name": name, "format": rulePackName.String()})
+	}
+	normalized, err := normalizePackRules(name, rules)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	pack := &domain.RulePack{
+		Name:        name,
+		Description: description,
+		Rules:       normalized,
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+	if err := uc.packRepo.Create(pack); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// UpdateRulePack 説明とルールを更新（nilの項目は維持し、rules を指定した場合はパックのルールを置き換える）
+func (uc *RulePackUseCase) UpdateRulePack(name string, description *string, rules []domain.Rule) (*domain.RulePack, error) {
+	pack, err := uc.packRepo.GetByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if description != nil {
+		pack.Description = *description
+	}
+	if rules != nil {
+		if pack.Rules, err = normalizePackRules(name, rules); err != nil {
+			return nil, err
+		}
+	}
+	pack.UpdatedAt = time.Now()
+	if err := uc.packRepo.Update(pack); err != nil {
+		return nil, err
+	}
+	if err := uc.packChanged(name); err != nil {
+		return nil, err
+	}
+	return pack, nil
+}
+
+// DeleteRulePack パックを削除（適用していたプロジェクトからも外れる）
+func (uc *RulePackUseCase) DeleteRulePack(name string) error {
+	projectIDs, err := uc.packRepo.GetProjects(name)
+	if err != nil {
+		return err
+	}
+	if err := uc.packRepo.Delete(name); err != nil {
+		return err
+	}
+	for _, projectID := range projectIDs {
+		uc.projectChanged(projectID)
+	}
+	return nil
+}
+
+// ListProjectRulePacks プロジェクトに適用しているパック（優先度の高い順）
+func (uc *RulePackUseCase) ListProjectRulePacks(projectID string) ([]*domain.ProjectRulePack, error) {
+	if _, err := uc.projectRepo.GetByID(projectID); err != nil {
+		return nil, err
+	}
+	return uc.packRepo.GetByProject(projectID)
+}
+
+// AttachRulePack パックをプロジェクトに適用（適用済みなら優先度を変更）
+func (uc *RulePackUseCase) AttachRulePack(projectID, name string, priority int) (*domain.ProjectRulePack, error) {
+	if _, err := uc.projectRepo.GetByID(projectID); err != nil {
+		return nil, err
+	}
+	if _, err := uc.packRepo.GetByName(name); err != nil {
+		return nil, err
+	}
+	attachment := &domain.ProjectRulePack{ProjectID: projectID, Pack: name, Priority: priority, CreatedAt: time.Now()}
+	if err := uc.packRepo.Attach(attachment); err != nil {
+		return nil, err
+	}
+	uc.projectChanged(projectID)
+	return attachment, nil
+}
+
+// DetachRulePack パックをプロジェクトから外す
+func (uc *RulePackUseCase) DetachRulePack(projectID, name string) error {
+	attached, err := uc.packRepo.GetByProject(projectID)
+	if err != nil {
+		return err
+	}
+	for _, a := range attached {
+		if a.Pack == name {
+			if err := uc.packRepo.Detach(projectID, name); err != nil {
+				return err
+			}
+			uc.projectChanged(projectID)
+			return nil
+		}
+	}
+	return apperr.WrapWithDetails(apperr.ErrNotFound, "対象が見つかりません", map[string]interface{}{"project_id": projectID, "pack": name})
+}
+
+// normalizePackRules パックのルールを検証し、パック名を設定
+func normalizePackRules(name string, rules []domain.Rule) ([]domain.Rule, error) {
+	normalized := make([]domain.Rule, 0, len(rules))
+	byID := make(map[string]domain.Rule, len(rules))
+	var missing, duplicate []string
+	for i, rule := range rules {
+		if rule.RuleID == "" || rule.Name == "" {
+			missing = append(missing, fmt.Sprintf("rules[%d]", i))
+			continue
+		}
+		if _, ok := byID[rule.RuleID]; ok {
+			duplicate = append(duplicate, rule.RuleID)
+			continue
+		}
+		rule.ProjectID = ""
+		rule.Pack = name
+		byID[rule.RuleID] = rule
+		normalized = append(normalized, rule)
+	}
+	if len(missing) > 0 || len(duplicate) > 0 {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing_rule_id_or_name": missing, "duplicate_rule_ids": duplicate})
+	}
+	if err := ValidateRules(byID); err != nil {
+		return nil, err
+	}
+	return normalized, nil
+}
+
+// SetRulePackRepository ルールパックの参照先を注入（未設定の場合パックは適用しない）
+func (uc *RuleUseCase) SetRulePackRepository(repo domain.RulePackRepository) {
+	uc.packRepo = repo
+}
+
+// packRules プロジェクトに適用するパックと、そのルールを優先度の高い順に取得
+// 同じ優先度のパックはパック名の昇順とする
+func (uc *RuleUseCase) packRules(projectID string) ([]domain.ProjectRulePack, []domain.Rule, error) {
+	if uc.packRepo == nil {
+		return nil, nil, nil
+	}
+	attached, err := uc.packRepo.GetByProject(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	sort.SliceStable(attached, func(i, j int) bool {
+		if attached[i].Priority != attached[j].Priority {
+			return attached[i].Priority > attached[j].Priority
+		}
+		return attached[i].Pack < attached[j].Pack
+	})
+
+	packs := make([]domain.ProjectRulePack, 0, len(attached))
+	var rules []domain.Rule
+	for _, a := range attached {
+		pack, err := uc.packRepo.GetByName(a.Pack)
+		if err != nil {
+			return nil, nil, err
+		}
+		packs = append(packs, *a)
+		for _, r := range pack.Rules {
+			r.ProjectID = projectID
+			r.Pack = pack.Name
+			rules = append(rules, r)
+		}
+	}
+	return packs, rules, nil
+}
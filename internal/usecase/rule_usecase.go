@@ -1,16 +1,25 @@
 package usecase
 
 import (
-	"regexp"
+	"fmt"
+	"time"
 
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/udiff"
 )
 
 type RuleUseCase struct {
 	ruleRepo       domain.RuleRepository
 	globalRuleRepo domain.GlobalRuleRepository
 	projectRepo    domain.ProjectRepository
+	engine         *RuleEngine
+	events         *RuleEvents
+	languageRepo   domain.LanguageRepository
+	violationRepo  domain.ViolationRepository
+	violations     *ViolationRecorder
+	overrideRepo   domain.GlobalRuleOverrideRepository
+	packRepo       domain.RulePackRepository
 }
 
 func NewRuleUseCase(ruleRepo domain.RuleRepository, globalRuleRepo domain.GlobalRuleRepository, projectRepo domain.ProjectRepository) *RuleUseCase {
@@ -18,10 +27,57 @@ func NewRuleUseCase(ruleRepo domain.RuleRepository, globalRuleRepo domain.Global
 		ruleRepo:       ruleRepo,
 		globalRuleRepo: globalRuleRepo,
 		projectRepo:    projectRepo,
+		engine:         NewRuleEngine(),
 	}
 }
 
-func (uc *RuleUseCase) CreateRule(projectID, ruleID, name, description, ruleType, severity, pattern, message string) error {
+// SetRuleEngine 共有のルールエンジンを注入
+func (uc *RuleUseCase) SetRuleEngine(engine *RuleEngine) {
+	uc.engine = engine
+}
+
+// SetRuleEvents ルール変更の配信先を注入
+func (uc *RuleUseCase) SetRuleEvents(events *RuleEvents) {
+	uc.events = events
+}
+
+// SetLanguageRepository 言語マスタを注入（プロジェクト概要に言語のメタデータを含める）
+func (uc *RuleUseCase) SetLanguageRepository(repo domain.LanguageRepository) {
+	uc.languageRepo = repo
+}
+
+// SetViolationRepository 違反の記録先を注入（検証で検出した違反を記録し、プロジェクト概要で集計する）
+// 記録はバックグラウンドで行う。プロセスの終了前に Close で記録待ちの違反を書き込む
+func (uc *RuleUseCase) SetViolationRepository(repo domain.ViolationRepository) {
+	if uc.violations != nil {
+		uc.violations.Close()
+	}
+	uc.violationRepo = repo
+	uc.violations = NewViolationRecorder(repo)
+}
+
+// Close 記録待ちの違反を書き込み、記録用のワーカーを停止する
+func (uc *RuleUseCase) Close() {
+	if uc.violations != nil {
+		uc.violations.Close()
+	}
+}
+
+// recordViolations 検出した違反を記録待ちに追加（記録の失敗・破棄は検証結果に影響させない）
+func (uc *RuleUseCase) recordViolations(projectID, filePath string, issues []domain.ValidationIssue) {
+	if uc.violations == nil {
+		return
+	}
+	uc.violations.Record(projectID, filePath, issues)
+}
+
+// rulesChanged プロジェクトのキャッシュを破棄し、変更を配信
+func (uc *RuleUseCase) rulesChanged(projectID string) {
+	uc.engine.Invalidate(projectID)
+	uc.events.Publish(RuleChange{ProjectID: projectID})
+}
+
+func (uc *RuleUseCase) CreateRule(projectID, ruleID, name, description, ruleType, severity, pattern, message, replacement string, matcher domain.RuleMatcher) error {
 	if projectID == "" || ruleID == "" || name == "" {
 		missing := []string{}
 		if projectID == "" {
@@ -35,7 +91,7 @@ func (uc *RuleUseCase) CreateRule(projectID, ruleID, name, description, ruleType
 		}
 		return apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": missing})
 	}
-
+	now := time.Now()
 	rule := &domain.Rule{
 		ProjectID:   projectID,
 		RuleID:      ruleID,
@@ -45,10 +101,20 @@ func (uc *RuleUseCase) CreateRule(projectID, ruleID, name, description, ruleType
 		Severity:    severity,
 		Pattern:     pattern,
 		Message:     message,
+		Replacement: replacement,
 		IsActive:    true,
+		UpdatedAt:   &now,
+		RuleMatcher: matcher,
+	}
+	if err := ValidateRule(*rule); err != nil {
+		return err
 	}
 
-	return uc.ruleRepo.Create(rule)
+	if err := uc.ruleRepo.Create(rule); err != nil {
+		return err
+	}
+	uc.rulesChanged(projectID)
+	return nil
 }
 
 func (uc *RuleUseCase) GetRule(projectID, ruleID string) (*domain.Rule, error) {
@@ -58,7 +124,8 @@ func (uc *RuleUseCase) GetRule(projectID, ruleID string) (*domain.Rule, error) {
 	return uc.ruleRepo.GetByID(projectID, ruleID)
 }
 
-func (uc *RuleUseCase) UpdateRule(projectID, ruleID, name, description, ruleType, severity, pattern, message string, isActive *bool) error {
+// UpdateRule ルールを更新（matcherがnilの場合は既存のマッチャー設定を維持）
+func (uc *RuleUseCase) UpdateRule(projectID, ruleID, name, description, ruleType, severity, pattern, message, replacement string, matcher *domain.RuleMatcher, isActive *bool) error {
 	if projectID == "" || ruleID == "" {
 		return apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"project_id", "rule_id"}})
 	}
@@ -66,6 +133,9 @@ func (uc *RuleUseCase) UpdateRule(projectID, ruleID, name, description, ruleType
 	if err != nil {
 		return err
 	}
+	if matcher != nil {
+		existing.RuleMatcher = *matcher
+	}
 	if name != "" {
 		existing.Name = name
 	}
@@ -78,10 +148,20 @@ func (uc *RuleUseCase) UpdateRule(projectID, ruleID, name, description, ruleType
 	}
 	existing.Pattern = pattern
 	existing.Message = message
+	existing.Replacement = replacement
 	if isActive != nil {
 		existing.IsActive = *isActive
 	}
-	return uc.ruleRepo.Update(existing)
+	if err := ValidateRule(*existing); err != nil {
+		return err
+	}
+	now := time.Now()
+	existing.UpdatedAt = &now
+	if err := uc.ruleRepo.Update(existing); err != nil {
+		return err
+	}
+	uc.rulesChanged(projectID)
+	return nil
 }
 
 func (uc *RuleUseCase) GetProjectRules(projectID string) (*domain.ProjectRules, error) {
@@ -89,7 +169,15 @@ func (uc *RuleUseCase) GetProjectRules(projectID string) (*domain.ProjectRules,
 	if err != nil {
 		return nil, err
 	}
+	return uc.projectRules(project)
+}
 
+// projectRules プロジェクトに適用されるルール（ルールパック・グローバルルール含む）を取得
+// ルールパック → 言語別グローバルルール → プロジェクトルールの順に重ね、同じルールIDは後の層のルールで置き換える
+// （パック同士では優先度の高いパックのルールを使う）
+// 結果はプロジェクトルール、グローバルルール（ルールID順）、パックのルール（優先度の高いパック順）の順に並ぶ
+func (uc *RuleUseCase) projectRules(project *domain.Project) (*domain.ProjectRules, error) {
+	projectID := project.ProjectID
 	rules, err := uc.ruleRepo.GetByProjectID(projectID)
 	if err != nil {
 		return nil, err
@@ -100,81 +188,212 @@ func (uc *RuleUseCase) GetProjectRules(projectID string) (*domain.ProjectRules,
 		Rules:     make([]domain.Rule, 0, len(rules)),
 	}
 
+	own := make(map[string]bool, len(rules))
 	for _, r := range rules {
 		projectRules.Rules = append(projectRules.Rules, *r)
+		own[r.RuleID] = true
 	}
 
-	if project.ApplyGlobalRules {
-		globalRules, err := uc.globalRuleRepo.GetByLanguage(project.Language)
-		if err != nil {
-			return nil, err
+	inherited, overrides, err := uc.inheritedRules(project)
+	if err != nil {
+		return nil, err
+	}
+	taken := make(map[string]bool, len(own)+len(inherited))
+	for ruleID := range own {
+		taken[ruleID] = true
+	}
+	// 言語の異なるグローバルルールは同じルールIDでも別のルールとして継承する
+	for _, r := range inherited {
+		if !own[r.RuleID] {
+			projectRules.Rules = append(projectRules.Rules, r)
+			taken[r.RuleID] = true
+		}
+	}
+	for _, o := range overrides {
+		if !own[o.RuleID] {
+			projectRules.Overrides = append(projectRules.Overrides, o)
 		}
+	}
 
-		for _, globalRule := range globalRules {
-			rule := domain.Rule{
-				ProjectID:   projectID,
-				RuleID:      globalRule.RuleID,
-				Name:        globalRule.Name,
-				Description: globalRule.Description,
-				Type:        globalRule.Type,
-				Severity:    globalRule.Severity,
-				Pattern:     globalRule.Pattern,
-				Message:     globalRule.Message,
-				IsActive:    globalRule.IsActive,
-			}
-			projectRules.Rules = append(projectRules.Rules, rule)
+	packs, packRules, err := uc.packRules(projectID)
+	if err != nil {
+		return nil, err
+	}
+	for _, r := range packRules {
+		if !taken[r.RuleID] {
+			projectRules.Rules = append(projectRules.Rules, r)
+			taken[r.RuleID] = true
 		}
 	}
+	if len(packs) > 0 {
+		projectRules.Packs = packs
+	}
 
 	return projectRules, nil
 }
 
+// ProjectsUsingGlobalRules 指定言語のグローバルルールを継承するプロジェクトIDを取得（主言語以外に指定言語を含むプロジェクトも対象）
+func (uc *RuleUseCase) ProjectsUsingGlobalRules(language string) ([]string, error) {
+	projects, err := uc.projectRepo.GetByLanguage(language)
+	if err != nil {
+		return nil, err
+	}
+	projectIDs := make([]string, 0, len(projects))
+	for _, p := range projects {
+		if p.ApplyGlobalRules {
+			projectIDs = append(projectIDs, p.ProjectID)
+		}
+	}
+	return projectIDs, nil
+}
+
 func (uc *RuleUseCase) DeleteRule(projectID, ruleID string) error {
-	return uc.ruleRepo.Delete(projectID, ruleID)
+	if err := uc.ruleRepo.Delete(projectID, ruleID); err != nil {
+		return err
+	}
+	uc.rulesChanged(projectID)
+	return nil
+}
+
+// TestPattern パターンをサンプルコードに対して試行（保存はしない）
+func (uc *RuleUseCase) TestPattern(pattern, code string) ([]domain.ValidationIssue, error) {
+	if pattern == "" {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"pattern"}})
+	}
+	if err := ValidatePattern(pattern); err != nil {
+		return nil, err
+	}
+
+	set := NewRuleEngine().Compile("", "", []domain.Rule{{RuleID: "pattern-test", Name: "Pattern Test", Pattern: pattern, IsActive: true}})
+	return set.Validate("", code).Issues, nil
+}
+
+// ruleSet 検証に使うプロジェクトとコンパイル済みルールセットを取得
+// 解決済みのルールセットがキャッシュにあれば、プロジェクトとルールの取得を省く
+func (uc *RuleUseCase) ruleSet(projectID string) (*domain.Project, *CompiledRuleSet, error) {
+	if set, ok := uc.engine.cached(projectID); ok {
+		return set.project, set, nil
+	}
+	generation := uc.engine.currentGeneration()
+	project, err := uc.projectRepo.GetByID(projectID)
+	if err != nil {
+		return nil, nil, err
+	}
+	projectRules, err := uc.projectRules(project)
+	if err != nil {
+		return nil, nil, err
+	}
+	return project, uc.engine.compileProject(generation, project, projectRules.Rules), nil
 }
 
 func (uc *RuleUseCase) ValidateCode(projectID, code string) (*domain.ValidationResult, error) {
-	projectRules, err := uc.GetProjectRules(projectID)
+	return uc.ValidateFile(projectID, "", "", code)
+}
+
+// FixCode 置換テンプレートを持つルールの修正をコードに適用し、修正後のコードと差分を返す
+func (uc *RuleUseCase) FixCode(projectID, filename, code string, ruleIDs []string) (*domain.FixResult, error) {
+	project, ruleSet, err := uc.ruleSet(projectID)
 	if err != nil {
 		return nil, err
 	}
+	language := newProjectLanguages(project).forPath(filename, "")
+	return ruleSet.FixLanguage(filename, language, code, ruleIDs), nil
+}
 
-	result := &domain.ValidationResult{
-		Valid:    true,
-		Errors:   []string{},
-		Warnings: []string{},
+// maxFilesPerValidation 1回の複数ファイル検証で受け付けるファイル数の上限
+const maxFilesPerValidation = 500
+
+// ValidateFiles 複数ファイルをまとめて検証（言語未指定のファイルはプロジェクトのパスglobか拡張子から推定）
+func (uc *RuleUseCase) ValidateFiles(projectID string, files []domain.FileInput) (*domain.FilesValidationResult, error) {
+	if len(files) == 0 || len(files) > maxFilesPerValidation {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"files": len(files), "max_files": maxFilesPerValidation})
+	}
+	for i, f := range files {
+		if f.Path == "" {
+			return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{fmt.Sprintf("files[%d].path", i)}})
+		}
 	}
 
-	for _, rule := range projectRules.Rules {
-		if !rule.IsActive {
-			continue
+	project, ruleSet, err := uc.ruleSet(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.FilesValidationResult{
+		Valid: true,
+		Files: make([]domain.FileValidationResult, 0, len(files)),
+	}
+	languages := newProjectLanguages(project)
+	for _, f := range files {
+		language := languages.forPath(f.Path, f.Language)
+		fileResult := ruleSet.ValidateLanguage(f.Path, language, f.Content)
+		uc.recordViolations(projectID, f.Path, fileResult.Issues)
+		result.Files = append(result.Files, domain.FileValidationResult{
+			Path:             f.Path,
+			Language:         language,
+			ValidationResult: *fileResult,
+		})
+		result.IssueCount += len(fileResult.Issues)
+		if !fileResult.Valid {
+			result.Valid = false
 		}
-		if rule.Pattern == "" {
+	}
+	return result, nil
+}
+
+// ValidateDiff unified diffの追加行のみを検証し、問題を変更後ファイルの行番号で報告
+// 削除されたファイルや追加行の無いファイルは結果に含めない
+func (uc *RuleUseCase) ValidateDiff(projectID, diff string) (*domain.FilesValidationResult, error) {
+	if diff == "" {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "入力値が不正です", map[string]interface{}{"missing": []string{"diff"}})
+	}
+	files, err := udiff.Parse(diff)
+	if err != nil {
+		return nil, apperr.WrapWithDetails(apperr.ErrValidation, "差分を解析できません", map[string]interface{}{"error": err.Error()})
+	}
+
+	project, ruleSet, err := uc.ruleSet(projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &domain.FilesValidationResult{
+		Valid: true,
+		Files: []domain.FileValidationResult{},
+	}
+	languages := newProjectLanguages(project)
+	for _, file := range files {
+		if file.NewPath == udiff.DevNull {
 			continue
 		}
-
-		matched, err := regexp.MatchString(rule.Pattern, code)
-		if err != nil {
+		fragments := diffFragments(file)
+		if len(fragments) == 0 {
 			continue
 		}
-
-		if matched {
-			msg := rule.Message
-			if msg == "" {
-				if rule.Name != "" {
-					msg = rule.Name
-				} else {
-					msg = rule.Description
-				}
-			}
-			if rule.Severity == "error" {
-				result.Errors = append(result.Errors, msg)
-				result.Valid = false
-			} else if rule.Severity == "warning" {
-				result.Warnings = append(result.Warnings, msg)
-			}
+		language := languages.forPath(file.NewPath, "")
+		fileResult := ruleSet.validateFragments(file.NewPath, language, fragments)
+		uc.recordViolations(projectID, file.NewPath, fileResult.Issues)
+		result.Files = append(result.Files, domain.FileValidationResult{
+			Path:             file.NewPath,
+			Language:         language,
+			ValidationResult: *fileResult,
+		})
+		result.IssueCount += len(fileResult.Issues)
+		if !fileResult.Valid {
+			result.Valid = false
 		}
 	}
+	return result, nil
+}
 
+// ValidateFile ファイル名を考慮してコードを検証（file_glob付きルールはファイル名が一致する場合のみ適用）
+// language を指定した場合はその言語のルールのみ適用し、空ならファイル名から言語を判定する
+func (uc *RuleUseCase) ValidateFile(projectID, filename, language, code string) (*domain.ValidationResult, error) {
+	project, ruleSet, err := uc.ruleSet(projectID)
+	if err != nil {
+		return nil, err
+	}
+	result := ruleSet.ValidateLanguage(filename, newProjectLanguages(project).forPath(filename, language), code)
+	uc.recordViolations(projectID, filename, result.Issues)
 	return result, nil
 }
@@ -0,0 +1,494 @@
+package usecase
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
+)
+
+// テスト用のインメモリリポジトリ
+type memProjectRepo struct{ projects map[string]*domain.Project }
+
+func (r *memProjectRepo) Create(p *domain.Project) error { r.projects[p.ProjectID] = p; return nil }
+func (r *memProjectRepo) GetByID(id string) (*domain.Project, error) {
+	if p, ok := r.projects[id]; ok {
+		return p, nil
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+func (r *memProjectRepo) GetAll() ([]*domain.Project, error) {
+	var out []*domain.Project
+	for _, p := range r.projects {
+		out = append(out, p)
+	}
+	return out, nil
+}
+func (r *memProjectRepo) GetByLanguage(language string) ([]*domain.Project, error) {
+	var out []*domain.Project
+	for _, p := range r.projects {
+		if p.HasLanguage(language) {
+			out = append(out, p)
+		}
+	}
+	return out, nil
+}
+func (r *memProjectRepo) Update(p *domain.Project) error { r.projects[p.ProjectID] = p; return nil }
+func (r *memProjectRepo) Delete(id string) error         { delete(r.projects, id); return nil }
+
+type memRuleRepo struct{ rules []*domain.Rule }
+
+func (r *memRuleRepo) Create(rule *domain.Rule) error { r.rules = append(r.rules, rule); return nil }
+func (r *memRuleRepo) GetByProjectID(projectID string) ([]*domain.Rule, error) {
+	var out []*domain.Rule
+	for _, rule := range r.rules {
+		if rule.ProjectID == projectID {
+			out = append(out, rule)
+		}
+	}
+	return out, nil
+}
+func (r *memRuleRepo) GetByID(projectID, ruleID string) (*domain.Rule, error) {
+	for _, rule := range r.rules {
+		if rule.ProjectID == projectID && rule.RuleID == ruleID {
+			return rule, nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+func (r *memRuleRepo) Update(rule *domain.Rule) error {
+	for i, existing := range r.rules {
+		if existing.ProjectID == rule.ProjectID && existing.RuleID == rule.RuleID {
+			r.rules[i] = rule
+		}
+	}
+	return nil
+}
+func (r *memRuleRepo) Delete(projectID, ruleID string) error {
+	for i, rule := range r.rules {
+		if rule.ProjectID == projectID && rule.RuleID == ruleID {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+type memGlobalRuleRepo struct{ rules []*domain.GlobalRule }
+
+func (r *memGlobalRuleRepo) Create(rule *domain.GlobalRule) error {
+	for _, existing := range r.rules {
+		if existing.Language == rule.Language && existing.RuleID == rule.RuleID {
+			return apperr.Wrap(apperr.ErrConflict, "既に存在します")
+		}
+	}
+	r.rules = append(r.rules, rule)
+	return nil
+}
+
+// GetByLanguage Postgres実装と同じく無効なルールは除く
+func (r *memGlobalRuleRepo) GetByLanguage(language string) ([]*domain.GlobalRule, error) {
+	var out []*domain.GlobalRule
+	for _, rule := range r.rules {
+		if rule.Language == language && rule.IsActive {
+			out = append(out, rule)
+		}
+	}
+	return out, nil
+}
+func (r *memGlobalRuleRepo) GetByID(language, ruleID string) (*domain.GlobalRule, error) {
+	for _, rule := range r.rules {
+		if rule.Language == language && rule.RuleID == ruleID {
+			cp := *rule
+			return &cp, nil
+		}
+	}
+	return nil, apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+func (r *memGlobalRuleRepo) Update(rule *domain.GlobalRule) error {
+	for i, existing := range r.rules {
+		if existing.Language == rule.Language && existing.RuleID == rule.RuleID {
+			r.rules[i] = rule
+			return nil
+		}
+	}
+	return apperr.Wrap(apperr.ErrNotFound, "対象が見つかりません")
+}
+func (r *memGlobalRuleRepo) GetAll() ([]*domain.GlobalRule, error) { return r.rules, nil }
+func (r *memGlobalRuleRepo) GetAllLanguages() ([]string, error) {
+	var languages []string
+	seen := map[string]bool{}
+	for _, rule := range r.rules {
+		if !seen[rule.Language] {
+			seen[rule.Language] = true
+			languages = append(languages, rule.Language)
+		}
+	}
+	return languages, nil
+}
+func (r *memGlobalRuleRepo) Delete(language, ruleID string) error {
+	for i, rule := range r.rules {
+		if rule.Language == language && rule.RuleID == ruleID {
+			r.rules = append(r.rules[:i], r.rules[i+1:]...)
+			return nil
+		}
+	}
+	return nil
+}
+
+func newTestRuleUseCase(rules ...*domain.Rule) *RuleUseCase {
+	projects := &memProjectRepo{projects: map[string]*domain.Project{
+		"web-app": {ProjectID: "web-app", Name: "Web Application", Language: "javascript"},
+	}}
+	return NewRuleUseCase(&memRuleRepo{rules: rules}, &memGlobalRuleRepo{}, projects)
+}
+
+func TestRuleUseCase_ValidateCodeReportsEveryMatchWithPosition(t *testing.T) {
+	uc := newTestRuleUseCase(&domain.Rule{
+		ProjectID: "web-app", RuleID: "no-console-log", Name: "No Console Log",
+		Severity: "warning", Pattern: `console\.log`, Message: "Console.log detected.", IsActive: true,
+	})
+
+	code := "const a = 1;\nconsole.log(a);\n  // 日本語 console.log(a)\n"
+	result, err := uc.ValidateCode("web-app", code)
+	if err != nil {
+		t.Fatalf("ValidateCode returned error: %v", err)
+	}
+	if len(result.Issues) != 2 {
+		t.Fatalf("Expected 2 issues, got %d", len(result.Issues))
+	}
+
+	first := result.Issues[0]
+	if first.RuleID != "no-console-log" || first.Severity != "warning" {
+		t.Errorf("Unexpected rule metadata: %+v", first)
+	}
+	if first.LineNumber != 2 || first.ColumnStart != 1 || first.ColumnEnd != 12 {
+		t.Errorf("Expected 2:1-12, got %d:%d-%d", first.LineNumber, first.ColumnStart, first.ColumnEnd)
+	}
+	if first.Snippet != "console.log" {
+		t.Errorf("Expected snippet 'console.log', got %q", first.Snippet)
+	}
+
+	// 列はバイトではなく文字単位で数える
+	second := result.Issues[1]
+	if second.LineNumber != 3 || second.ColumnStart != 10 {
+		t.Errorf("Expected 3:10, got %d:%d", second.LineNumber, second.ColumnStart)
+	}
+	if !result.Valid || len(result.Warnings) != 1 {
+		t.Errorf("Expected valid result with one warning, got valid=%v warnings=%v", result.Valid, result.Warnings)
+	}
+}
+
+func TestRuleUseCase_ValidateCodeErrorSeverityInvalidates(t *testing.T) {
+	uc := newTestRuleUseCase(&domain.Rule{
+		ProjectID: "web-app", RuleID: "no-debugger", Name: "No Debugger",
+		Severity: "error", Pattern: `debugger`, IsActive: true,
+	})
+
+	result, err := uc.ValidateCode("web-app", "debugger;\n")
+	if err != nil {
+		t.Fatalf("ValidateCode returned error: %v", err)
+	}
+	if result.Valid {
+		t.Errorf("Expected invalid result")
+	}
+	if len(result.Issues) != 1 || result.Issues[0].Message != "No Debugger" {
+		t.Errorf("Expected fallback message from rule name, got %+v", result.Issues)
+	}
+}
+
+func TestRuleUseCase_ValidateFilesAppliesRulesPerFile(t *testing.T) {
+	projects := &memProjectRepo{projects: map[string]*domain.Project{
+		"web-app": {ProjectID: "web-app", Language: "javascript", ApplyGlobalRules: true},
+	}}
+	rules := &memRuleRepo{rules: []*domain.Rule{{
+		ProjectID: "web-app", RuleID: "no-only", Severity: "error", Pattern: `\.only\(`, IsActive: true,
+		RuleMatcher: domain.RuleMatcher{FileGlob: "**/*.test.js"},
+	}}}
+	globals := &memGlobalRuleRepo{rules: []*domain.GlobalRule{{
+		Language: "javascript", RuleID: "no-console-log", Severity: "warning", Pattern: `console\.log`, IsActive: true,
+	}}}
+	uc := NewRuleUseCase(rules, globals, projects)
+
+	result, err := uc.ValidateFiles("web-app", []domain.FileInput{
+		{Path: "src/app.js", Content: "console.log(1)\nit.only('x')"},
+		{Path: "src/app.test.js", Content: "it.only('x')"},
+		{Path: "scripts/build.py", Content: "console.log(1)"},
+	})
+	if err != nil {
+		t.Fatalf("ValidateFiles returned error: %v", err)
+	}
+
+	if len(result.Files) != 3 || result.IssueCount != 2 || result.Valid {
+		t.Fatalf("Unexpected summary: %+v", result)
+	}
+	if f := result.Files[0]; f.Language != "javascript" || len(f.Issues) != 1 || f.Issues[0].RuleID != "no-console-log" {
+		t.Errorf("Expected only the language rule on app.js, got %+v", f)
+	}
+	if f := result.Files[1]; len(f.Issues) != 1 || f.Issues[0].RuleID != "no-only" || f.Valid {
+		t.Errorf("Expected glob-scoped rule on test file, got %+v", f)
+	}
+	if f := result.Files[2]; f.Language != "python" || len(f.Issues) != 0 {
+		t.Errorf("Expected javascript rules to skip python file, got %+v", f)
+	}
+
+	if _, err := uc.ValidateFiles("web-app", []domain.FileInput{{Content: "x"}}); err == nil {
+		t.Errorf("Expected missing path to be rejected")
+	}
+}
+
+func TestRuleUseCase_MultiLanguageProjectAppliesGlobalRulesPerFile(t *testing.T) {
+	projects := &memProjectRepo{projects: map[string]*domain.Project{}}
+	globals := &memGlobalRuleRepo{rules: []*domain.GlobalRule{
+		{Language: "go", RuleID: "no-todo", Name: "No TODO", Severity: "error", Pattern: "TODO", Message: "go todo", IsActive: true},
+		{Language: "go", RuleID: "no-println", Name: "No Println", Severity: "error", Pattern: `fmt\.Println`, IsActive: true},
+		{Language: "typescript", RuleID: "no-todo", Name: "No TODO", Severity: "warning", Pattern: "TODO", Message: "ts todo", IsActive: true},
+		{Language: "typescript", RuleID: "no-console-log", Name: "No console.log", Severity: "warning", Pattern: `console\.log`, IsActive: true},
+	}}
+	uc := NewRuleUseCase(&memRuleRepo{}, globals, projects)
+	projectUseCase := NewProjectUseCase(projects)
+
+	err := projectUseCase.CreateProject("mono", "Mono", "", "", []domain.ProjectLanguage{
+		{Language: "go", Paths: []string{"backend/**"}},
+		{Language: "typescript", Paths: []string{"frontend/**"}},
+	}, true, "", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if project, _ := projects.GetByID("mono"); project.Language != "go" {
+		t.Errorf("primary language = %q, want the first declared language", project.Language)
+	}
+
+	rules, err := uc.GetProjectRules("mono")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 同じルールIDでも言語が異なるグローバルルールはそれぞれ継承する
+	if len(rules.Rules) != 4 {
+		t.Fatalf("inherited rules = %+v, want both languages", rules.Rules)
+	}
+
+	result, err := uc.ValidateFiles("mono", []domain.FileInput{
+		{Path: "backend/main.go", Content: "// TODO\nfmt.Println(1)\nconsole.log(1)"},
+		{Path: "frontend/src/app.tsx", Content: "// TODO\nconsole.log(1)\nfmt.Println(1)"},
+		// パスglobは拡張子より優先する
+		{Path: "frontend/scripts/gen.js", Content: "console.log(1)"},
+		{Path: "tools/lint.go", Content: "fmt.Println(1)"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		language string
+		issues   int
+	}{{"go", 2}, {"typescript", 2}, {"typescript", 1}, {"go", 1}}
+	for i, f := range result.Files {
+		if f.Language != want[i].language || len(f.Issues) != want[i].issues {
+			t.Errorf("files[%d] = %+v, want %d %s issues", i, f, want[i].issues, want[i].language)
+		}
+	}
+	for _, issue := range result.Files[1].Issues {
+		if issue.RuleID == "no-todo" && issue.Message != "ts todo" {
+			t.Errorf("frontend TODO issue = %+v, want the typescript rule", issue)
+		}
+	}
+
+	single, err := uc.ValidateFile("mono", "frontend/index.ts", "", "fmt.Println(1)")
+	if err != nil || len(single.Issues) != 0 {
+		t.Errorf("go rule applied to a typescript file: %+v, %v", single, err)
+	}
+
+	if ids, _ := uc.ProjectsUsingGlobalRules("typescript"); len(ids) != 1 || ids[0] != "mono" {
+		t.Errorf("projects using typescript rules = %v", ids)
+	}
+
+	err = projectUseCase.UpdateProject("mono", "Mono", "", "go", []domain.ProjectLanguage{{Language: "go"}, {Language: "go"}, {Language: "typescript", Paths: []string{""}}}, true, "")
+	if !errors.Is(err, apperr.ErrValidation) {
+		t.Errorf("duplicate language error = %v, want validation error", err)
+	}
+	if err := projectUseCase.UpdateProject("mono", "Renamed", "", "go", nil, true, ""); err != nil {
+		t.Fatal(err)
+	}
+	if project, _ := projects.GetByID("mono"); len(project.Languages) != 2 {
+		t.Errorf("languages = %+v, want kept when omitted", project.Languages)
+	}
+}
+
+func TestRuleUseCase_ValidateDiffReportsOnlyAddedLines(t *testing.T) {
+	uc := newTestRuleUseCase(
+		&domain.Rule{ProjectID: "web-app", RuleID: "no-console-log", Severity: "error", Pattern: `console\.log`, IsActive: true},
+		&domain.Rule{ProjectID: "web-app", RuleID: "use-strict", Severity: "error", Pattern: `use strict`, IsActive: true,
+			RuleMatcher: domain.RuleMatcher{MatcherKind: domain.MatcherRequired}},
+	)
+	diff := `--- a/src/legacy.js
++++ b/src/legacy.js
+@@ -10,4 +10,5 @@ function legacy() {
+ console.log('old');
+-const a = 1;
++const a = 2;
++console.log(a);
+ console.log('also old');
+ }
+--- a/src/gone.js
++++ /dev/null
+@@ -1 +0,0 @@
+-console.log('deleted');
+`
+	result, err := uc.ValidateDiff("web-app", diff)
+	if err != nil {
+		t.Fatalf("ValidateDiff returned error: %v", err)
+	}
+	if len(result.Files) != 1 || result.Files[0].Path != "src/legacy.js" {
+		t.Fatalf("Expected only the modified file, got %+v", result.Files)
+	}
+	issues := result.Files[0].Issues
+	if len(issues) != 1 || issues[0].LineNumber != 12 || issues[0].ColumnStart != 1 {
+		t.Errorf("Expected a single issue on new line 12, got %+v", issues)
+	}
+	if result.Valid || result.IssueCount != 1 {
+		t.Errorf("Unexpected summary: valid=%v count=%d", result.Valid, result.IssueCount)
+	}
+
+	if _, err := uc.ValidateDiff("web-app", "@@ -1 +1 @@\n-a\n+b\n"); err == nil {
+		t.Errorf("Expected malformed diff to be rejected")
+	}
+}
+
+func TestRuleUseCase_ValidateDiffDoesNotMatchAcrossHunks(t *testing.T) {
+	uc := newTestRuleUseCase(
+		&domain.Rule{ProjectID: "web-app", RuleID: "no-begin-end", Severity: "error", Pattern: `BEGIN.*?END`, IsActive: true,
+			RuleMatcher: domain.RuleMatcher{Flags: "s"}},
+	)
+	// 1つ目のハンクの BEGIN と2つ目のハンクの END の間には省略された行がある
+	diff := `--- a/src/app.js
++++ b/src/app.js
+@@ -1,2 +1,3 @@
+ start();
++// BEGIN
+ one();
+@@ -40,2 +41,3 @@
+ two();
++// END
+ finish();
+@@ -80,2 +82,4 @@
+ three();
++// BEGIN
++// END
+ done();
+`
+	result, err := uc.ValidateDiff("web-app", diff)
+	if err != nil {
+		t.Fatalf("ValidateDiff returned error: %v", err)
+	}
+	if len(result.Files) != 1 {
+		t.Fatalf("Expected one file, got %+v", result.Files)
+	}
+	file := result.Files[0]
+	// ハンク内で完結する一致だけを変更後ファイルの行番号で報告する
+	if len(file.Issues) != 1 || file.Issues[0].LineNumber != 83 || file.Issues[0].EndLineNumber != 84 {
+		t.Errorf("Expected a single issue on new lines 83-84, got %+v", file.Issues)
+	}
+	if file.Valid || len(file.Errors) != 1 || result.IssueCount != 1 {
+		t.Errorf("Unexpected summary: valid=%v errors=%v count=%d", file.Valid, file.Errors, result.IssueCount)
+	}
+}
+
+func TestRuleUseCase_ValidateSnippetWithoutLanguageUsesPrimaryLanguage(t *testing.T) {
+	projects := &memProjectRepo{projects: map[string]*domain.Project{
+		"mono": {ProjectID: "mono", Language: "typescript", ApplyGlobalRules: true, Languages: []domain.ProjectLanguage{
+			{Language: "typescript", Paths: []string{"web/**"}},
+			{Language: "python", Paths: []string{"ml/**"}},
+		}},
+	}}
+	globals := &memGlobalRuleRepo{rules: []*domain.GlobalRule{
+		{Language: "typescript", RuleID: "no-any", Severity: "error", Pattern: `: any`, IsActive: true},
+		{Language: "python", RuleID: "no-print", Severity: "error", Pattern: `print\(`, IsActive: true},
+	}}
+	uc := NewRuleUseCase(&memRuleRepo{}, globals, projects)
+	code := "let x: any = print(1);\n"
+
+	// ファイル名も言語も無い場合は主言語のルールだけを適用する
+	result, err := uc.ValidateCode("mono", code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].RuleID != "no-any" {
+		t.Errorf("snippet issues = %+v, want only the typescript rule", result.Issues)
+	}
+
+	// 言語を指定した場合はその言語のルールを適用する
+	result, err = uc.ValidateFile("mono", "", "python", code)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.Issues) != 1 || result.Issues[0].RuleID != "no-print" {
+		t.Errorf("python snippet issues = %+v, want only the python rule", result.Issues)
+	}
+}
+
+// countingProjectRepo プロジェクトの取得回数を数える
+type countingProjectRepo struct {
+	*memProjectRepo
+	gets int
+}
+
+func (r *countingProjectRepo) GetByID(id string) (*domain.Project, error) {
+	r.gets++
+	return r.memProjectRepo.GetByID(id)
+}
+
+func TestRuleUseCase_ValidationReusesResolvedRulesUntilChanged(t *testing.T) {
+	projects := &countingProjectRepo{memProjectRepo: &memProjectRepo{projects: map[string]*domain.Project{
+		"mono": {ProjectID: "mono", Name: "Mono", Language: "go", Languages: []domain.ProjectLanguage{{Language: "go"}, {Language: "typescript"}}, ApplyGlobalRules: true},
+	}}}
+	globals := &memGlobalRuleRepo{}
+	engine := NewRuleEngine()
+	uc := NewRuleUseCase(&memRuleRepo{}, globals, projects)
+	uc.SetRuleEngine(engine)
+	globalRuleUseCase := NewGlobalRuleUseCase(globals)
+	globalRuleUseCase.SetRuleEngine(engine)
+	projectUseCase := NewProjectUseCase(projects)
+	projectUseCase.SetRuleEngine(engine)
+
+	issues := func(filename, code string) int {
+		t.Helper()
+		result, err := uc.ValidateFile("mono", filename, "", code)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return len(result.Issues)
+	}
+
+	if err := uc.CreateRule("mono", "no-todo", "No TODO", "", "", "error", "TODO", "", "", domain.RuleMatcher{}); err != nil {
+		t.Fatal(err)
+	}
+	issues("main.go", "// TODO")
+	issues("main.go", "// TODO")
+	if projects.gets != 1 {
+		t.Errorf("project fetched %d times for two validations, want the resolved rules reused", projects.gets)
+	}
+
+	// 主言語以外の言語にグローバルルールが追加されても反映される
+	if err := globalRuleUseCase.CreateGlobalRule("typescript", "no-console-log", "No console.log", "", "", "warning", `console\.log`, "", "", domain.RuleMatcher{}); err != nil {
+		t.Fatal(err)
+	}
+	if n := issues("app.ts", "console.log(1)"); n != 1 {
+		t.Errorf("issues after adding a typescript global rule = %d, want 1", n)
+	}
+
+	inactive := false
+	if err := uc.UpdateRule("mono", "no-todo", "No TODO", "", "", "error", "TODO", "", "", nil, &inactive); err != nil {
+		t.Fatal(err)
+	}
+	if n := issues("main.go", "// TODO"); n != 0 {
+		t.Errorf("issues after deactivating the rule = %d, want 0", n)
+	}
+
+	if err := projectUseCase.UpdateProject("mono", "Mono", "", "go", nil, false, ""); err != nil {
+		t.Fatal(err)
+	}
+	if n := issues("app.ts", "console.log(1)"); n != 0 {
+		t.Errorf("issues after disabling global rules = %d, want 0", n)
+	}
+}
@@ -0,0 +1,100 @@
+package usecase
+
+import (
+	"strings"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+// 抑制ディレクティブ（コメント記法は問わず、行内に書かれていれば認識する）
+//
+//	// rule-mcp-disable-next-line no-console-log -- 理由
+//	# rule-mcp-disable-line no-print
+//	/* rule-mcp-disable no-inline-styles, no-console-log */
+//
+// ルールIDを省略した場合は全ルールが対象になる
+const (
+	directivePrefix   = "rule-mcp-disable"
+	directiveNextLine = "rule-mcp-disable-next-line"
+	directiveLine     = "rule-mcp-disable-line"
+	directiveFile     = "rule-mcp-disable"
+)
+
+// suppression 1つの抑制ディレクティブ
+type suppression struct {
+	directive     string
+	line          int // ディレクティブが書かれた行
+	target        int // 抑制対象の行（ファイル全体なら0）
+	ruleIDs       map[string]bool
+	justification string
+}
+
+// covers 問題がこのディレクティブで抑制されるか判定
+func (s suppression) covers(issue domain.ValidationIssue) bool {
+	if s.target != 0 && s.target != issue.LineNumber {
+		return false
+	}
+	return len(s.ruleIDs) == 0 || s.ruleIDs[issue.RuleID]
+}
+
+// parseSuppressions コード中の抑制ディレクティブを収集
+func parseSuppressions(li *lineIndex) []suppression {
+	if !strings.Contains(li.code, directivePrefix) {
+		return nil
+	}
+
+	var result []suppression
+	for n := 1; n <= li.lineCount(); n++ {
+		start, end := li.lineBounds(n)
+		line := li.code[start:end]
+		idx := strings.Index(line, directivePrefix)
+		if idx < 0 {
+			continue
+		}
+
+		s := suppression{line: n}
+		rest := line[idx:]
+		switch {
+		case strings.HasPrefix(rest, directiveNextLine):
+			s.directive, s.target = directiveNextLine, n+1
+		case strings.HasPrefix(rest, directiveLine):
+			s.directive, s.target = directiveLine, n
+		default:
+			s.directive = directiveFile
+		}
+		rest = rest[len(s.directive):]
+		// rule-mcp-disable-foo のような別の語は対象外
+		if rest != "" && !strings.ContainsAny(rest[:1], " \t") && !strings.HasPrefix(rest, "*/") && !strings.HasPrefix(rest, "-->") {
+			continue
+		}
+		rest = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(rest), "*/"))
+		rest = strings.TrimSpace(strings.TrimSuffix(rest, "-->"))
+
+		if before, reason, ok := strings.Cut(rest, "--"); ok {
+			rest, s.justification = before, strings.TrimSpace(reason)
+		}
+		for _, id := range strings.FieldsFunc(rest, func(r rune) bool { return r == ',' || r == ' ' || r == '\t' }) {
+			if s.ruleIDs == nil {
+				s.ruleIDs = map[string]bool{}
+			}
+			s.ruleIDs[id] = true
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// suppress 問題を抑制するディレクティブがあれば抑制済みの問題として返す
+func suppress(suppressions []suppression, issue domain.ValidationIssue) (domain.SuppressedIssue, bool) {
+	for _, s := range suppressions {
+		if s.covers(issue) {
+			return domain.SuppressedIssue{
+				ValidationIssue: issue,
+				Directive:       s.directive,
+				DirectiveLine:   s.line,
+				Justification:   s.justification,
+			}, true
+		}
+	}
+	return domain.SuppressedIssue{}, false
+}
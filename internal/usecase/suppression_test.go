@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"testing"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+func TestSuppression_NextLineAndSameLine(t *testing.T) {
+	rules := []domain.Rule{
+		{RuleID: "no-console-log", Severity: "error", Pattern: `console\.log`, IsActive: true},
+		{RuleID: "no-debugger", Severity: "warning", Pattern: `debugger`, IsActive: true},
+	}
+	code := "// rule-mcp-disable-next-line no-console-log -- CLI output is intentional\n" +
+		"console.log(a); debugger;\n" +
+		"console.log(b); // rule-mcp-disable-line\n" +
+		"console.log(c);\n"
+
+	result := NewRuleEngine().Compile("web-app", "javascript", rules).Validate("", code)
+
+	if len(result.Issues) != 2 {
+		t.Fatalf("Expected 2 active issues, got %+v", result.Issues)
+	}
+	if result.Issues[0].LineNumber != 4 || result.Issues[1].RuleID != "no-debugger" {
+		t.Errorf("Unexpected active issues: %+v", result.Issues)
+	}
+	if len(result.Suppressed) != 2 {
+		t.Fatalf("Expected 2 suppressed issues, got %+v", result.Suppressed)
+	}
+	first := result.Suppressed[0]
+	if first.LineNumber != 2 || first.Directive != directiveNextLine || first.DirectiveLine != 1 || first.Justification != "CLI output is intentional" {
+		t.Errorf("Unexpected suppressed issue: %+v", first)
+	}
+	if result.Suppressed[1].LineNumber != 3 || result.Suppressed[1].Directive != directiveLine {
+		t.Errorf("Unexpected suppressed issue: %+v", result.Suppressed[1])
+	}
+	if result.Valid {
+		t.Errorf("Expected unsuppressed error to keep result invalid")
+	}
+}
+
+func TestSuppression_FileLevel(t *testing.T) {
+	rules := []domain.Rule{{RuleID: "no-print", Severity: "error", Pattern: `print\(`, IsActive: true}}
+	code := "# rule-mcp-disable no-print\nprint(1)\nprint(2)\n"
+
+	result := NewRuleEngine().Compile("ml", "python", rules).Validate("", code)
+	if !result.Valid || len(result.Issues) != 0 || len(result.Errors) != 0 {
+		t.Errorf("Expected file-level directive to suppress all issues, got %+v", result)
+	}
+	if len(result.Suppressed) != 2 {
+		t.Errorf("Expected suppressed issues to be reported, got %+v", result.Suppressed)
+	}
+}
+
+func TestSuppression_IgnoresOtherWordsAndRules(t *testing.T) {
+	rules := []domain.Rule{{RuleID: "no-print", Severity: "warning", Pattern: `print\(`, IsActive: true}}
+	code := "# rule-mcp-disable-foo\n# rule-mcp-disable-next-line other-rule\nprint(1)\n"
+
+	result := NewRuleEngine().Compile("ml", "python", rules).Validate("", code)
+	if len(result.Issues) != 1 || len(result.Suppressed) != 0 {
+		t.Errorf("Expected issue not to be suppressed, got %+v", result)
+	}
+}
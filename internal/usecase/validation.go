@@ -0,0 +1,114 @@
+package usecase
+
+import (
+	"sort"
+	"unicode/utf8"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+const (
+	// maxIssuesPerRule 1ルールあたりに報告する問題の上限
+	maxIssuesPerRule = 1000
+	// maxSnippetRunes スニペットとして返す最大文字数
+	maxSnippetRunes = 200
+)
+
+// lineIndex バイトオフセットを行・列へ変換するための索引
+type lineIndex struct {
+	code   string
+	starts []int
+}
+
+func newLineIndex(code string) *lineIndex {
+	starts := []int{0}
+	for i := 0; i < len(code); i++ {
+		if code[i] == '\n' {
+			starts = append(starts, i+1)
+		}
+	}
+	return &lineIndex{code: code, starts: starts}
+}
+
+// sourceFile 検証対象のコード（Goの構文木は必要になった時点で一度だけ解析）
+type sourceFile struct {
+	li       *lineIndex
+	goParsed bool
+	goSrc    *goSource
+}
+
+func newSourceFile(code string) *sourceFile {
+	return &sourceFile{li: newLineIndex(code)}
+}
+
+// goSource Goとして解析した結果（解析できない場合はnil）
+func (s *sourceFile) goSource() *goSource {
+	if !s.goParsed {
+		s.goSrc = parseGoSource(s.li.code)
+		s.goParsed = true
+	}
+	return s.goSrc
+}
+
+// position オフセットを1始まりの行・列（ルーン単位）に変換
+func (li *lineIndex) position(offset int) (int, int) {
+	line := sort.Search(len(li.starts), func(i int) bool { return li.starts[i] > offset }) - 1
+	col := utf8.RuneCountInString(li.code[li.starts[line]:offset]) + 1
+	return line + 1, col
+}
+
+// lineCount 行数（末尾の改行の後ろは行として数えない）
+func (li *lineIndex) lineCount() int {
+	n := len(li.starts)
+	if n > 1 && li.starts[n-1] == len(li.code) {
+		n--
+	}
+	return n
+}
+
+// lineBounds n行目（1始まり）の範囲 [start, end) を改行文字を除いて返す
+func (li *lineIndex) lineBounds(n int) (int, int) {
+	start := li.starts[n-1]
+	end := len(li.code)
+	if n < len(li.starts) {
+		end = li.starts[n] - 1
+	}
+	if end > start && li.code[end-1] == '\r' {
+		end--
+	}
+	return start, end
+}
+
+// newIssue マッチ範囲 [start, end) からValidationIssueを生成
+func newIssue(rule domain.Rule, li *lineIndex, start, end int) domain.ValidationIssue {
+	line, colStart := li.position(start)
+	endLine, colEnd := li.position(end)
+
+	snippet := li.code[start:end]
+	if utf8.RuneCountInString(snippet) > maxSnippetRunes {
+		snippet = string([]rune(snippet)[:maxSnippetRunes])
+	}
+
+	return domain.ValidationIssue{
+		RuleID:        rule.RuleID,
+		RuleName:      rule.Name,
+		Severity:      rule.Severity,
+		Message:       ruleMessage(rule),
+		LineNumber:    line,
+		ColumnStart:   colStart,
+		EndLineNumber: endLine,
+		ColumnEnd:     colEnd,
+		Snippet:       snippet,
+	}
+}
+
+// ruleMessage ルールの表示用メッセージ（未設定時は名前・説明で代替）
+func ruleMessage(rule domain.Rule) string {
+	if rule.Message != "" {
+		return rule.Message
+	}
+	if rule.Name != "" {
+		return rule.Name
+	}
+	return rule.Description
+}
@@ -0,0 +1,125 @@
+package usecase
+
+import (
+	"log"
+	"sync"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+const (
+	// violationQueueSize 記録待ちにできる検証結果（ファイル単位）の数。溢れた分は破棄する
+	violationQueueSize = 256
+	// maxRecordedViolations 1ファイルの検証結果から記録する違反の上限
+	maxRecordedViolations = 100
+)
+
+// violationBatch 1ファイル分の記録待ちの違反
+type violationBatch struct {
+	projectID string
+	filePath  string
+	issues    []domain.ValidationIssue
+}
+
+// ViolationRecorder 検出した違反をバックグラウンドで記録する
+// 検証のレスポンスを記録先の書き込みで待たせないため、キューに積んで1つのワーカーが順に書き込む
+type ViolationRecorder struct {
+	repo    domain.ViolationRepository
+	queue   chan violationBatch
+	flushes chan chan struct{}
+	done    chan struct{}
+
+	closeOnce sync.Once
+	mu        sync.RWMutex
+	closed    bool
+}
+
+// NewViolationRecorder 記録用のワーカーを起動する（不要になったら Close で停止する）
+func NewViolationRecorder(repo domain.ViolationRepository) *ViolationRecorder {
+	r := &ViolationRecorder{
+		repo:    repo,
+		queue:   make(chan violationBatch, violationQueueSize),
+		flushes: make(chan chan struct{}),
+		done:    make(chan struct{}),
+	}
+	go r.run()
+	return r
+}
+
+// Record 違反を記録待ちに追加（待たずに戻る。キューが一杯なら破棄してログに残す）
+func (r *ViolationRecorder) Record(projectID, filePath string, issues []domain.ValidationIssue) {
+	if len(issues) == 0 {
+		return
+	}
+	if len(issues) > maxRecordedViolations {
+		issues = issues[:maxRecordedViolations]
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if r.closed {
+		return
+	}
+	select {
+	case r.queue <- violationBatch{projectID: projectID, filePath: filePath, issues: issues}:
+	default:
+		log.Printf("Warning: violation queue is full, dropped %d violations for %s", len(issues), projectID)
+	}
+}
+
+// Flush 記録待ちの違反をすべて書き込むまで待つ
+func (r *ViolationRecorder) Flush() {
+	ack := make(chan struct{})
+	select {
+	case r.flushes <- ack:
+		<-ack
+	case <-r.done:
+	}
+}
+
+// Close 記録待ちの違反を書き込んでからワーカーを停止する
+func (r *ViolationRecorder) Close() {
+	r.closeOnce.Do(func() {
+		r.mu.Lock()
+		r.closed = true
+		close(r.queue)
+		r.mu.Unlock()
+	})
+	<-r.done
+}
+
+func (r *ViolationRecorder) run() {
+	defer close(r.done)
+	for {
+		select {
+		case batch, ok := <-r.queue:
+			if !ok {
+				return
+			}
+			r.write(batch)
+		case ack := <-r.flushes:
+			r.drain()
+			close(ack)
+		}
+	}
+}
+
+// drain キューに残っている違反を書き込む
+func (r *ViolationRecorder) drain() {
+	for {
+		select {
+		case batch, ok := <-r.queue:
+			if !ok {
+				return
+			}
+			r.write(batch)
+		default:
+			return
+		}
+	}
+}
+
+func (r *ViolationRecorder) write(batch violationBatch) {
+	if err := r.repo.Record(batch.projectID, batch.filePath, batch.issues); err != nil {
+		log.Printf("Warning: failed to record %d violations for %s: %v", len(batch.issues), batch.projectID, err)
+	}
+}
@@ -0,0 +1,80 @@
+package usecase
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/internal/domain"
+)
+
+// blockingViolationRepo release を閉じるまで書き込みが終わらない記録先
+type blockingViolationRepo struct {
+	release chan struct{}
+
+	mu       sync.Mutex
+	recorded int
+}
+
+func (r *blockingViolationRepo) Record(projectID, filePath string, issues []domain.ValidationIssue) error {
+	<-r.release
+	r.mu.Lock()
+	r.recorded += len(issues)
+	r.mu.Unlock()
+	return nil
+}
+func (r *blockingViolationRepo) CountBySeverity(projectID string, since time.Time) (map[string]int, error) {
+	return map[string]int{}, nil
+}
+
+func TestValidationDoesNotWaitForViolationRepository(t *testing.T) {
+	repo := &blockingViolationRepo{release: make(chan struct{})}
+	uc := newTestRuleUseCase(&domain.Rule{ProjectID: "web-app", RuleID: "no-eval", Severity: "error", Pattern: "eval", IsActive: true})
+	uc.SetViolationRepository(repo)
+	defer uc.Close()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := 0; i < 3; i++ {
+			if _, err := uc.ValidateCode("web-app", "eval(x)"); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	// 記録先が書き込みを終えていなくても検証は戻る
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("validation blocked on the violation repository")
+	}
+
+	close(repo.release)
+	uc.violations.Flush()
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if repo.recorded != 3 {
+		t.Errorf("recorded %d violations, want 3", repo.recorded)
+	}
+}
+
+func TestViolationRecorderCapsAndDrops(t *testing.T) {
+	repo := &blockingViolationRepo{release: make(chan struct{})}
+	rec := NewViolationRecorder(repo)
+
+	issues := make([]domain.ValidationIssue, maxRecordedViolations+50)
+	// ワーカーが1件目の書き込みで止まっている間にキューを溢れさせる
+	for i := 0; i < violationQueueSize+10; i++ {
+		rec.Record("web-app", "a.go", issues)
+	}
+	close(repo.release)
+	rec.Close()
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+	if limit := (violationQueueSize + 1) * maxRecordedViolations; repo.recorded > limit || repo.recorded%maxRecordedViolations != 0 {
+		t.Errorf("recorded %d violations, want at most %d in batches of %d", repo.recorded, limit, maxRecordedViolations)
+	}
+	// 停止後の記録は破棄する
+	rec.Record("web-app", "a.go", issues)
+}
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"time"
 )
 
 type Config struct {
@@ -11,6 +12,9 @@ type Config struct {
 	Host        string
 	Environment string
 	LogLevel    string
+	// AccessTokenTTL・RefreshTokenTTL 0 の場合は既定値（アクセス15分・リフレッシュ30日）を使う
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
 }
 
 func LoadConfig() *Config {
@@ -40,6 +44,14 @@ func LoadConfig() *Config {
 		config.LogLevel = logLevel
 	}
 
+	if ttl, err := time.ParseDuration(os.Getenv("ACCESS_TOKEN_TTL")); err == nil && ttl > 0 {
+		config.AccessTokenTTL = ttl
+	}
+
+	if ttl, err := time.ParseDuration(os.Getenv("REFRESH_TOKEN_TTL")); err == nil && ttl > 0 {
+		config.RefreshTokenTTL = ttl
+	}
+
 	return config
 }
 
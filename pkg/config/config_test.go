@@ -3,6 +3,7 @@ package config
 import (
 	"os"
 	"testing"
+	"time"
 )
 
 func TestLoadConfigDefault(t *testing.T) {
@@ -109,3 +110,23 @@ func TestConfigEnvironment(t *testing.T) {
 		t.Error("Expected production environment to not be development")
 	}
 }
+
+func TestLoadConfigTokenTTL(t *testing.T) {
+	os.Setenv("ACCESS_TOKEN_TTL", "5m")
+	os.Setenv("REFRESH_TOKEN_TTL", "invalid")
+	defer func() {
+		os.Unsetenv("ACCESS_TOKEN_TTL")
+		os.Unsetenv("REFRESH_TOKEN_TTL")
+	}()
+
+	config := LoadConfig()
+
+	if config.AccessTokenTTL != 5*time.Minute {
+		t.Errorf("Expected access token TTL 5m, got %s", config.AccessTokenTTL)
+	}
+
+	// 不正な値の場合は既定値（0）のまま
+	if config.RefreshTokenTTL != 0 {
+		t.Errorf("Expected refresh token TTL 0 for invalid value, got %s", config.RefreshTokenTTL)
+	}
+}
@@ -0,0 +1,24 @@
+package httpx
+
+import "strings"
+
+// ParseOrigins カンマ区切りの許可オリジン（ALLOWED_ORIGINS）を分割
+func ParseOrigins(s string) []string {
+	var origins []string
+	for _, part := range strings.Split(s, ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			origins = append(origins, part)
+		}
+	}
+	return origins
+}
+
+// OriginAllowed オリジンが許可リストに含まれるか判定
+func OriginAllowed(allowed []string, origin string) bool {
+	for _, a := range allowed {
+		if a == origin {
+			return true
+		}
+	}
+	return false
+}
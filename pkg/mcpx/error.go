@@ -6,22 +6,60 @@ import (
 	"github.com/AkitoSakurabaCreator/Rule-MCP-Server/pkg/apperr"
 )
 
+// JSON-RPC 2.0 の標準エラーコード
 const (
-	CodeValidation    = 4000
-	CodeUnauthorized  = 4001
-	CodeForbidden     = 4003
-	CodeNotFound      = 4040
-	CodeConflict      = 4090
-	CodeUnprocessable = 4220
-	CodeInternal      = 5000
+	CodeParseError     = -32700
+	CodeInvalidRequest = -32600
+	CodeMethodNotFound = -32601
+	CodeInvalidParams  = -32602
+	CodeInternalError  = -32603
 )
 
+// サーバー定義エラーコード（JSON-RPC 2.0 で予約された -32000〜-32099 の範囲）
+const (
+	CodeUnauthorized  = -32001
+	CodeForbidden     = -32003
+	CodeNotFound      = -32004
+	CodeConflict      = -32009
+	CodeUnprocessable = -32022
+)
+
+// Error MCPのエラー応答に変換されるエラー
+type Error struct {
+	Code    int
+	Message string
+	Data    interface{}
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// NewError エラーコードとメッセージからエラーを作成
+func NewError(code int, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// FromError アプリケーションエラーをMCPエラーに変換（prefixはメッセージの先頭に付与）
+// apperr.WithDetails の詳細は data に格納する
+func FromError(err error, prefix string) *Error {
+	var mcpErr *Error
+	if errors.As(err, &mcpErr) {
+		return mcpErr
+	}
+	code, msg := MapAppErrorToMCP(err)
+	e := &Error{Code: code, Message: prefix + msg}
+	var withDetails *apperr.WithDetails
+	if errors.As(err, &withDetails) && withDetails.Details != nil {
+		e.Data = withDetails.Details
+	}
+	return e
+}
+
 func MapAppErrorToMCP(err error) (int, string) {
 	if err == nil {
 		return 0, ""
 	}
 	if errors.Is(err, apperr.ErrValidation) {
-		return CodeValidation, "入力値が不正です"
+		return CodeInvalidParams, "入力値が不正です"
 	}
 	if errors.Is(err, apperr.ErrUnauthorized) {
 		return CodeUnauthorized, "認証が必要です"
@@ -38,5 +76,5 @@ func MapAppErrorToMCP(err error) (int, string) {
 	if errors.Is(err, apperr.ErrUnprocessable) {
 		return CodeUnprocessable, "処理できない内容です"
 	}
-	return CodeInternal, "サーバ内部でエラーが発生しました"
+	return CodeInternalError, "サーバ内部でエラーが発生しました"
 }
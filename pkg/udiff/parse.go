@@ -0,0 +1,145 @@
+package udiff
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// DevNull 追加・削除されたファイルの相手側を表すパス
+const DevNull = "/dev/null"
+
+// FileDiff 1ファイル分の差分
+type FileDiff struct {
+	OldPath string
+	NewPath string
+	Hunks   []Hunk
+}
+
+// Hunk 差分の1ハンク
+type Hunk struct {
+	OldStart, OldLines int
+	NewStart, NewLines int
+	Lines              []Line
+}
+
+// Line ハンク内の1行（Kindは ' ', '+', '-' のいずれか）
+type Line struct {
+	Kind    byte
+	Text    string // 先頭の記号と末尾の改行を除いた内容
+	OldLine int    // 変更前の行番号（追加行は0）
+	NewLine int    // 変更後の行番号（削除行は0）
+}
+
+var hunkHeader = regexp.MustCompile(`^@@ -(\d+)(?:,(\d+))? \+(\d+)(?:,(\d+))? @@`)
+
+// Parse unified diff（git diff の出力を含む）を解析
+func Parse(diff string) ([]FileDiff, error) {
+	var files []FileDiff
+	var current *FileDiff
+	lines := strings.Split(strings.ReplaceAll(diff, "\r\n", "\n"), "\n")
+
+	for i := 0; i < len(lines); i++ {
+		line := lines[i]
+		switch {
+		case strings.HasPrefix(line, "--- ") && i+1 < len(lines) && strings.HasPrefix(lines[i+1], "+++ "):
+			files = append(files, FileDiff{
+				OldPath: diffPath(line[4:]),
+				NewPath: diffPath(lines[i+1][4:]),
+			})
+			current = &files[len(files)-1]
+			i++
+		case strings.HasPrefix(line, "@@ "):
+			if current == nil {
+				return nil, fmt.Errorf("line %d: hunk without file header", i+1)
+			}
+			hunk, next, err := parseHunk(lines, i)
+			if err != nil {
+				return nil, err
+			}
+			current.Hunks = append(current.Hunks, hunk)
+			i = next - 1
+		}
+	}
+	return files, nil
+}
+
+// parseHunk lines[start] のハンクヘッダから行数分を読み取り、次に読む位置を返す
+func parseHunk(lines []string, start int) (Hunk, int, error) {
+	m := hunkHeader.FindStringSubmatch(lines[start])
+	if m == nil {
+		return Hunk{}, 0, fmt.Errorf("line %d: invalid hunk header %q", start+1, lines[start])
+	}
+	h := Hunk{
+		OldStart: atoi(m[1]), OldLines: countOrOne(m[2]),
+		NewStart: atoi(m[3]), NewLines: countOrOne(m[4]),
+	}
+
+	oldLine, newLine := h.OldStart, h.NewStart
+	oldLeft, newLeft := h.OldLines, h.NewLines
+	i := start + 1
+	for ; i < len(lines) && (oldLeft > 0 || newLeft > 0); i++ {
+		line := lines[i]
+		if strings.HasPrefix(line, `\`) {
+			continue
+		}
+		kind, text := byte(' '), ""
+		if line != "" {
+			kind, text = line[0], line[1:]
+		}
+		switch kind {
+		case ' ':
+			h.Lines = append(h.Lines, Line{Kind: ' ', Text: text, OldLine: oldLine, NewLine: newLine})
+			oldLine++
+			newLine++
+			oldLeft--
+			newLeft--
+		case '-':
+			h.Lines = append(h.Lines, Line{Kind: '-', Text: text, OldLine: oldLine})
+			oldLine++
+			oldLeft--
+		case '+':
+			h.Lines = append(h.Lines, Line{Kind: '+', Text: text, NewLine: newLine})
+			newLine++
+			newLeft--
+		default:
+			return Hunk{}, 0, fmt.Errorf("line %d: unexpected line in hunk %q", i+1, line)
+		}
+	}
+	if oldLeft > 0 || newLeft > 0 {
+		return Hunk{}, 0, fmt.Errorf("line %d: hunk is shorter than its header", i)
+	}
+	// 直後の "\ No newline at end of file" を読み飛ばす
+	for i < len(lines) && strings.HasPrefix(lines[i], `\`) {
+		i++
+	}
+	return h, i, nil
+}
+
+// diffPath ヘッダのパスから a/ b/ の接頭辞とタイムスタンプを取り除く
+func diffPath(p string) string {
+	if tab := strings.IndexByte(p, '\t'); tab >= 0 {
+		p = p[:tab]
+	}
+	p = strings.TrimSpace(p)
+	if p == DevNull {
+		return p
+	}
+	if strings.HasPrefix(p, "a/") || strings.HasPrefix(p, "b/") {
+		return p[2:]
+	}
+	return p
+}
+
+func atoi(s string) int {
+	n, _ := strconv.Atoi(s)
+	return n
+}
+
+func countOrOne(s string) int {
+	if s == "" {
+		return 1
+	}
+	return atoi(s)
+}
@@ -0,0 +1,205 @@
+// Package udiff はunified diff形式の生成と解析を提供する
+package udiff
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	// contextLines ハンクの前後に含める変更のない行数
+	contextLines = 3
+	// maxLCSCells 行単位LCSのテーブル上限（超えた場合は差分範囲全体を置換として扱う）
+	maxLCSCells = 4_000_000
+)
+
+type opKind int
+
+const (
+	opEqual opKind = iota
+	opDelete
+	opInsert
+)
+
+type op struct {
+	kind opKind
+	line string
+}
+
+// Unified 2つのテキストのunified diffを返す（差分が無ければ空文字列）
+func Unified(oldName, newName, a, b string) string {
+	if a == b {
+		return ""
+	}
+	ops := diffLines(splitLines(a), splitLines(b))
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", oldName, newName)
+
+	// 変更箇所を前後contextLines行の文脈とともにハンクへまとめる
+	oldLine, newLine := 1, 1
+	for i := 0; i < len(ops); {
+		if ops[i].kind == opEqual {
+			oldLine++
+			newLine++
+			i++
+			continue
+		}
+
+		start := i - contextLines
+		if start < 0 {
+			start = 0
+		}
+		end := i
+		for end < len(ops) {
+			if ops[end].kind != opEqual {
+				end++
+				continue
+			}
+			run := end
+			for run < len(ops) && ops[run].kind == opEqual {
+				run++
+			}
+			if run == len(ops) || run-end > 2*contextLines {
+				end += min(run-end, contextLines)
+				break
+			}
+			end = run
+		}
+
+		hunkOld, hunkNew := oldLine-(i-start), newLine-(i-start)
+		var body strings.Builder
+		oldCount, newCount := 0, 0
+		for _, o := range ops[start:end] {
+			switch o.kind {
+			case opEqual:
+				writeLine(&body, ' ', o.line)
+				oldCount++
+				newCount++
+			case opDelete:
+				writeLine(&body, '-', o.line)
+				oldCount++
+			case opInsert:
+				writeLine(&body, '+', o.line)
+				newCount++
+			}
+		}
+		fmt.Fprintf(&sb, "@@ -%s +%s @@\n", hunkRange(hunkOld, oldCount), hunkRange(hunkNew, newCount))
+		sb.WriteString(body.String())
+
+		for _, o := range ops[i:end] {
+			if o.kind != opInsert {
+				oldLine++
+			}
+			if o.kind != opDelete {
+				newLine++
+			}
+		}
+		i = end
+	}
+	return sb.String()
+}
+
+// hunkRange ハンクヘッダの範囲表記（行数0の場合は直前の行番号を示す）
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
+
+func writeLine(sb *strings.Builder, prefix byte, line string) {
+	sb.WriteByte(prefix)
+	sb.WriteString(line)
+	if !strings.HasSuffix(line, "\n") {
+		sb.WriteString("\n\\ No newline at end of file\n")
+	}
+}
+
+// splitLines 改行を含めたまま行に分割
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	lines := strings.SplitAfter(s, "\n")
+	if lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return lines
+}
+
+// diffLines 行単位の編集操作列を求める（共通の先頭・末尾を除いた範囲でLCS）
+func diffLines(a, b []string) []op {
+	prefix := 0
+	for prefix < len(a) && prefix < len(b) && a[prefix] == b[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(a)-prefix && suffix < len(b)-prefix && a[len(a)-1-suffix] == b[len(b)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]op, 0, len(a)+len(b))
+	for _, line := range a[:prefix] {
+		ops = append(ops, op{opEqual, line})
+	}
+	ops = append(ops, lcsOps(a[prefix:len(a)-suffix], b[prefix:len(b)-suffix])...)
+	for _, line := range a[len(a)-suffix:] {
+		ops = append(ops, op{opEqual, line})
+	}
+	return ops
+}
+
+func lcsOps(a, b []string) []op {
+	var ops []op
+	if len(a)*len(b) > maxLCSCells {
+		for _, line := range a {
+			ops = append(ops, op{opDelete, line})
+		}
+		for _, line := range b {
+			ops = append(ops, op{opInsert, line})
+		}
+		return ops
+	}
+
+	// table[i][j] = a[i:] と b[j:] のLCS長
+	table := make([][]int, len(a)+1)
+	for i := range table {
+		table[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				table[i][j] = table[i+1][j+1] + 1
+			} else {
+				table[i][j] = max(table[i+1][j], table[i][j+1])
+			}
+		}
+	}
+
+	i, j := 0, 0
+	for i < len(a) && j < len(b) {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{opEqual, a[i]})
+			i++
+			j++
+		case table[i+1][j] >= table[i][j+1]:
+			ops = append(ops, op{opDelete, a[i]})
+			i++
+		default:
+			ops = append(ops, op{opInsert, b[j]})
+			j++
+		}
+	}
+	for ; i < len(a); i++ {
+		ops = append(ops, op{opDelete, a[i]})
+	}
+	for ; j < len(b); j++ {
+		ops = append(ops, op{opInsert, b[j]})
+	}
+	return ops
+}
@@ -0,0 +1,93 @@
+package udiff
+
+import "testing"
+
+func TestUnified_SingleHunkWithContext(t *testing.T) {
+	a := "a\nb\nc\nd\ne\nf\ng\nh\n"
+	b := "a\nb\nc\nd\nE\nf\ng\nh\n"
+
+	want := "--- a/x.js\n+++ b/x.js\n" +
+		"@@ -2,7 +2,7 @@\n" +
+		" b\n c\n d\n-e\n+E\n f\n g\n h\n"
+	if got := Unified("a/x.js", "b/x.js", a, b); got != want {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_SeparateHunksAndMissingNewline(t *testing.T) {
+	a := "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11"
+	b := "one\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n"
+
+	want := "--- a\n+++ b\n" +
+		"@@ -1,4 +1,4 @@\n-1\n+one\n 2\n 3\n 4\n" +
+		"@@ -8,4 +8,4 @@\n 8\n 9\n 10\n-11\n\\ No newline at end of file\n+11\n"
+	if got := Unified("a", "b", a, b); got != want {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestUnified_InsertIntoEmpty(t *testing.T) {
+	want := "--- a\n+++ b\n@@ -0,0 +1 @@\n+x\n"
+	if got := Unified("a", "b", "", "x\n"); got != want {
+		t.Errorf("Unexpected diff:\n%s\nwant:\n%s", got, want)
+	}
+	if got := Unified("a", "b", "same", "same"); got != "" {
+		t.Errorf("Expected empty diff for identical input, got %q", got)
+	}
+}
+
+func TestParse_GitDiff(t *testing.T) {
+	diff := `diff --git a/src/app.js b/src/app.js
+index 1111111..2222222 100644
+--- a/src/app.js
++++ b/src/app.js
+@@ -1,3 +1,4 @@
+ const a = 1;
+-console.log(a);
++logger.debug(a);
++console.log('new');
+ export default a;
+@@ -10 +11,0 @@
+-removed();
+diff --git a/old.txt b/old.txt
+deleted file mode 100644
+--- a/old.txt
++++ /dev/null
+@@ -1 +0,0 @@
+-bye
+\ No newline at end of file
+`
+	files, err := Parse(diff)
+	if err != nil {
+		t.Fatalf("Parse returned error: %v", err)
+	}
+	if len(files) != 2 || files[0].NewPath != "src/app.js" || files[1].NewPath != DevNull {
+		t.Fatalf("Unexpected files: %+v", files)
+	}
+
+	hunks := files[0].Hunks
+	if len(hunks) != 2 || hunks[0].NewStart != 1 || hunks[0].NewLines != 4 || hunks[1].NewLines != 0 {
+		t.Fatalf("Unexpected hunks: %+v", hunks)
+	}
+	var added []Line
+	for _, l := range hunks[0].Lines {
+		if l.Kind == '+' {
+			added = append(added, l)
+		}
+	}
+	if len(added) != 2 || added[0].NewLine != 2 || added[1].NewLine != 3 || added[1].Text != "console.log('new');" {
+		t.Errorf("Unexpected added lines: %+v", added)
+	}
+	if last := hunks[0].Lines[len(hunks[0].Lines)-1]; last.OldLine != 3 || last.NewLine != 4 {
+		t.Errorf("Unexpected context line numbers: %+v", last)
+	}
+}
+
+func TestParse_RejectsTruncatedHunk(t *testing.T) {
+	if _, err := Parse("--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n"); err == nil {
+		t.Errorf("Expected error for truncated hunk")
+	}
+	if _, err := Parse("@@ -1 +1 @@\n-a\n+b\n"); err == nil {
+		t.Errorf("Expected error for hunk without file header")
+	}
+}